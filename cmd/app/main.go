@@ -1,7 +1,8 @@
 package main
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
 	"pr-reviewer-service/config"
 	"pr-reviewer-service/internal/app"
@@ -11,7 +12,17 @@ func main() {
 	// Configuration
 	cfg, err := config.NewConfig()
 	if err != nil {
-		log.Fatalf("Config error: %s", err)
+		slog.Error("Config error", "error", err)
+		os.Exit(1)
+	}
+
+	// Schema migrations: app migrate up | down [steps] | status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(cfg, os.Args[2:]); err != nil {
+			slog.Error("Migrate error", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Run application
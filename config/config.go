@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"gopkg.in/yaml.v3"
@@ -10,10 +11,19 @@ import (
 
 type (
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		Log  `yaml:"logger"`
-		PG   `yaml:"postgres"`
+		App        `yaml:"app"`
+		HTTP       `yaml:"http"`
+		Log        `yaml:"logger"`
+		PG         `yaml:"postgres"`
+		Stale      `yaml:"stale"`
+		Auth       `yaml:"auth"`
+		RateLimit  `yaml:"rate_limit"`
+		Metrics    `yaml:"metrics"`
+		Tracing    `yaml:"tracing"`
+		Migrations `yaml:"migrations"`
+		SCIM       `yaml:"scim"`
+		LDAP       `yaml:"ldap"`
+		Notify     `yaml:"notify"`
 	}
 
 	App struct {
@@ -23,21 +33,171 @@ type (
 
 	HTTP struct {
 		Port string `env:"HTTP_PORT" yaml:"port" env-default:"8080"`
+		// ShutdownDelay is how long /readyz fails before the server stops accepting requests
+		ShutdownDelay time.Duration `env:"HTTP_SHUTDOWN_DELAY" yaml:"shutdown_delay" env-default:"5s"`
 	}
 
 	Log struct {
+		// Level is debug, info, warn or error; debug also logs every SQL query
 		Level string `env:"LOG_LEVEL" yaml:"level" env-default:"info"`
+		// Format is json or text
+		Format string `env:"LOG_FORMAT" yaml:"format" env-default:"json"`
 	}
 
 	PG struct {
 		PoolMax int    `env:"PG_POOL_MAX" yaml:"pool_max" env-default:"2"`
 		URL     string `env:"PG_URL"      yaml:"url"      env-required:"true"`
 	}
+
+	// Migrations are embedded in the binary. AutoApply applies pending ones
+	// on startup; otherwise run "app migrate up".
+	Migrations struct {
+		AutoApply bool `env:"MIGRATIONS_AUTO_APPLY" yaml:"auto_apply" env-default:"false"`
+		// LockTimeout bounds the wait for another instance that is migrating
+		LockTimeout time.Duration `env:"MIGRATIONS_LOCK_TIMEOUT" yaml:"lock_timeout" env-default:"1m"`
+	}
+
+	Stale struct {
+		Threshold  time.Duration `env:"STALE_THRESHOLD"    yaml:"threshold"   env-default:"720h"`
+		Action     string        `env:"STALE_ACTION"       yaml:"action"      env-default:"mark"`
+		JobEnabled bool          `env:"STALE_JOB_ENABLED"  yaml:"job_enabled" env-default:"false"`
+		Interval   time.Duration `env:"STALE_JOB_INTERVAL" yaml:"interval"    env-default:"1h"`
+		DryRun     bool          `env:"STALE_DRY_RUN"      yaml:"dry_run"     env-default:"true"`
+	}
+
+	Auth struct {
+		Enabled bool `env:"AUTH_ENABLED" yaml:"enabled" env-default:"false"`
+		// BootstrapToken is registered as an admin token on startup
+		BootstrapToken string `env:"AUTH_BOOTSTRAP_TOKEN" yaml:"bootstrap_token"`
+		JWT            `yaml:"jwt"`
+	}
+
+	// JWT configures bearer JWTs from an identity provider, checked against a
+	// JWKS file or URL. Used only when auth is enabled.
+	JWT struct {
+		Enabled     bool          `env:"JWT_ENABLED"      yaml:"enabled"      env-default:"false"`
+		JWKSFile    string        `env:"JWT_JWKS_FILE"    yaml:"jwks_file"`
+		JWKSURL     string        `env:"JWT_JWKS_URL"     yaml:"jwks_url"`
+		JWKSRefresh time.Duration `env:"JWT_JWKS_REFRESH" yaml:"jwks_refresh" env-default:"1h"`
+		Issuer      string        `env:"JWT_ISSUER"       yaml:"issuer"`
+		Audience    string        `env:"JWT_AUDIENCE"     yaml:"audience"`
+		Leeway      time.Duration `env:"JWT_LEEWAY"       yaml:"leeway"       env-default:"30s"`
+		// UserClaim holds users.user_id
+		UserClaim    string `env:"JWT_USER_CLAIM"    yaml:"user_claim"    env-default:"sub"`
+		ScopeClaim   string `env:"JWT_SCOPE_CLAIM"   yaml:"scope_claim"   env-default:"scope"`
+		DefaultScope string `env:"JWT_DEFAULT_SCOPE" yaml:"default_scope" env-default:"write"`
+	}
+
+	RateLimit struct {
+		Enabled bool `env:"RATE_LIMIT_ENABLED" yaml:"enabled" env-default:"false"`
+		// Store is memory (per instance) or postgres (shared by all instances)
+		Store string `env:"RATE_LIMIT_STORE" yaml:"store" env-default:"memory"`
+		// TrustedProxies is the number of proxies in front of the service that
+		// append to X-Forwarded-For; 0 ignores the header
+		TrustedProxies int `env:"RATE_LIMIT_TRUSTED_PROXIES" yaml:"trusted_proxies" env-default:"0"`
+		// Groups are matched in order; the first group matching a request applies
+		Groups []RateLimitGroup `yaml:"groups"`
+	}
+
+	// RateLimitGroup limits a group of routes, e.g. "POST /pullRequest/create" or
+	// "POST /team/*". A group without routes matches every request.
+	RateLimitGroup struct {
+		Name   string   `yaml:"name"`
+		Routes []string `yaml:"routes"`
+		// Principal is the bucket of each API token or JWT user
+		Principal RateLimitBucket `yaml:"principal"`
+		// IP is the bucket of each client address, checked before authentication
+		IP RateLimitBucket `yaml:"ip"`
+	}
+
+	// RateLimitBucket refills at Rate requests per second up to Burst requests.
+	RateLimitBucket struct {
+		Rate  float64 `yaml:"rate"`
+		Burst int     `yaml:"burst"`
+	}
+
+	// Metrics serves GET /metrics in the Prometheus text format.
+	Metrics struct {
+		Enabled bool `env:"METRICS_ENABLED" yaml:"enabled" env-default:"true"`
+	}
+
+	// Tracing exports OpenTelemetry spans of HTTP requests, usecases and queries.
+	Tracing struct {
+		Enabled bool `env:"TRACING_ENABLED" yaml:"enabled" env-default:"false"`
+		// Exporter is otlp (OTLP over HTTP) or stdout
+		Exporter string `env:"TRACING_EXPORTER" yaml:"exporter" env-default:"otlp"`
+		// Endpoint is the host:port of the OTLP collector
+		Endpoint string `env:"TRACING_ENDPOINT" yaml:"endpoint" env-default:"localhost:4318"`
+		Insecure bool   `env:"TRACING_INSECURE" yaml:"insecure" env-default:"true"`
+		// SampleRatio is the share of new traces that are recorded
+		SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" yaml:"sample_ratio" env-default:"1"`
+	}
+
+	// SCIM serves the SCIM 2.0 provisioning endpoints under /scim/v2.
+	SCIM struct {
+		Enabled bool `env:"SCIM_ENABLED" yaml:"enabled" env-default:"false"`
+	}
+
+	// LDAP periodically syncs users and the teams of groups from a directory server.
+	LDAP struct {
+		Enabled  bool          `env:"LDAP_ENABLED"       yaml:"enabled"  env-default:"false"`
+		Interval time.Duration `env:"LDAP_SYNC_INTERVAL" yaml:"interval" env-default:"1h"`
+		// DryRun only logs the planned changes
+		DryRun bool `env:"LDAP_DRY_RUN" yaml:"dry_run" env-default:"true"`
+		// URL is ldap://host:389 or ldaps://host:636
+		URL      string        `env:"LDAP_URL"       yaml:"url"`
+		StartTLS bool          `env:"LDAP_START_TLS" yaml:"start_tls" env-default:"false"`
+		Timeout  time.Duration `env:"LDAP_TIMEOUT"   yaml:"timeout"   env-default:"30s"`
+		// BindDN is empty for an anonymous bind
+		BindDN       string `env:"LDAP_BIND_DN"       yaml:"bind_dn"`
+		BindPassword string `env:"LDAP_BIND_PASSWORD" yaml:"bind_password"`
+
+		UserBaseDN  string `env:"LDAP_USER_BASE_DN"  yaml:"user_base_dn"`
+		UserFilter  string `env:"LDAP_USER_FILTER"   yaml:"user_filter"  env-default:"(objectClass=person)"`
+		GroupBaseDN string `env:"LDAP_GROUP_BASE_DN" yaml:"group_base_dn"`
+		GroupFilter string `env:"LDAP_GROUP_FILTER"  yaml:"group_filter" env-default:"(objectClass=groupOfNames)"`
+
+		// UserIDAttribute holds users.user_id
+		UserIDAttribute    string `env:"LDAP_USER_ID_ATTRIBUTE"    yaml:"user_id_attribute"    env-default:"uid"`
+		UsernameAttribute  string `env:"LDAP_USERNAME_ATTRIBUTE"   yaml:"username_attribute"   env-default:"cn"`
+		GroupNameAttribute string `env:"LDAP_GROUP_NAME_ATTRIBUTE" yaml:"group_name_attribute" env-default:"cn"`
+		// MemberAttribute lists members by DN (member) or by user id (memberUid)
+		MemberAttribute string `env:"LDAP_MEMBER_ATTRIBUTE" yaml:"member_attribute" env-default:"member"`
+	}
+
+	// Notify posts PR events to Slack-compatible incoming webhooks.
+	Notify struct {
+		Enabled bool `env:"NOTIFY_ENABLED" yaml:"enabled" env-default:"false"`
+		// Window batches the messages to a webhook posted within it into one
+		Window   time.Duration `env:"NOTIFY_BATCH_WINDOW" yaml:"batch_window" env-default:"5s"`
+		MaxBatch int           `env:"NOTIFY_MAX_BATCH"    yaml:"max_batch"    env-default:"20"`
+		Timeout  time.Duration `env:"NOTIFY_TIMEOUT"      yaml:"timeout"      env-default:"10s"`
+		// ReviewSLA is how long a reviewer has for a verdict; 0 turns reminders off
+		ReviewSLA        time.Duration `env:"NOTIFY_REVIEW_SLA"         yaml:"review_sla"         env-default:"0"`
+		SLACheckInterval time.Duration `env:"NOTIFY_SLA_CHECK_INTERVAL" yaml:"sla_check_interval" env-default:"15m"`
+		// Teams and Users map team names and user ids to webhook URLs
+		Teams map[string]string `yaml:"teams"`
+		Users map[string]string `yaml:"users"`
+		// Templates override the message of an event (assigned, reassigned,
+		// sla_breach, merged); see internal/notify
+		Templates map[string]string `yaml:"templates"`
+	}
 )
 
 func NewConfig() (*Config, error) {
 	cfg := &Config{}
 
+	// Defaults first, so that config.yml and the environment override them.
+	// env.Parse would apply envDefault whenever a variable is unset, over the
+	// values of config.yml, so the defaults live in env-default and are parsed
+	// against an empty environment.
+	if err := env.ParseWithOptions(cfg, env.Options{
+		Environment:         map[string]string{},
+		DefaultValueTagName: "env-default",
+	}); err != nil {
+		return nil, fmt.Errorf("config - NewConfig - env.ParseWithOptions: %w", err)
+	}
+
 	// Read from config.yml if exists
 	if _, err := os.Stat("./config/config.yml"); err == nil {
 		file, err := os.ReadFile("./config/config.yml")
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/config"
+)
+
+// chdir runs the test in dir, where NewConfig looks for config/config.yml.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("os.Chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestNewConfigDefaultsWithoutFile(t *testing.T) {
+	chdir(t, t.TempDir())
+	t.Setenv("PG_URL", "postgres://localhost/db")
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+
+	if !cfg.LDAP.DryRun {
+		t.Error("LDAP.DryRun = false, want true")
+	}
+	if !cfg.Stale.DryRun {
+		t.Error("Stale.DryRun = false, want true")
+	}
+	if !cfg.Metrics.Enabled {
+		t.Error("Metrics.Enabled = false, want true")
+	}
+	if cfg.HTTP.Port != "8080" {
+		t.Errorf("HTTP.Port = %q, want 8080", cfg.HTTP.Port)
+	}
+	if cfg.Notify.SLACheckInterval != 15*time.Minute {
+		t.Errorf("Notify.SLACheckInterval = %v, want 15m", cfg.Notify.SLACheckInterval)
+	}
+}
+
+func TestNewConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatalf("os.Mkdir: %v", err)
+	}
+	file := "http:\n  port: '9090'\nlogger:\n  level: 'warn'\nstale:\n  dry_run: false\n"
+	if err := os.WriteFile(filepath.Join(dir, "config", "config.yml"), []byte(file), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	chdir(t, dir)
+	t.Setenv("HTTP_PORT", "9191")
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+
+	if cfg.HTTP.Port != "9191" {
+		t.Errorf("HTTP.Port = %q, want the environment's 9191", cfg.HTTP.Port)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("Log.Level = %q, want the file's warn", cfg.Log.Level)
+	}
+	if cfg.Stale.DryRun {
+		t.Error("Stale.DryRun = true, want the file's false")
+	}
+	if !cfg.LDAP.DryRun {
+		t.Error("LDAP.DryRun = false, want the default true")
+	}
+}
@@ -2,7 +2,7 @@ package app
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,21 +11,67 @@ import (
 
 	"pr-reviewer-service/config"
 	"pr-reviewer-service/internal/controller/http/middleware"
+	"pr-reviewer-service/internal/controller/http/scim"
 	v1 "pr-reviewer-service/internal/controller/http/v1"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/repo/directory"
 	"pr-reviewer-service/internal/repo/persistent"
 	"pr-reviewer-service/internal/usecase"
+	"pr-reviewer-service/pkg/job"
+	"pr-reviewer-service/pkg/jwt"
+	"pr-reviewer-service/pkg/metrics"
 	"pr-reviewer-service/pkg/postgres"
+	"pr-reviewer-service/pkg/ratelimit"
+	"pr-reviewer-service/pkg/slack"
+	"pr-reviewer-service/pkg/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// rateLimitCleanupInterval is how often idle shared rate limit buckets are deleted.
+const rateLimitCleanupInterval = 10 * time.Minute
+
 func Run(cfg *config.Config) {
 
-	log.SetOutput(os.Stdout)
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	//Logger
+	if err := setupLogger(cfg); err != nil {
+		fatal("app - Run - setupLogger", "error", err)
+	}
+	l := slog.Default()
+
+	//Tracing
+	var tp trace.TracerProvider = noop.NewTracerProvider()
+	if cfg.Tracing.Enabled {
+		sdkProvider, err := tracing.New(context.Background(), tracing.Config{
+			ServiceName:    cfg.App.Name,
+			ServiceVersion: cfg.App.Version,
+			Exporter:       cfg.Tracing.Exporter,
+			Endpoint:       cfg.Tracing.Endpoint,
+			Insecure:       cfg.Tracing.Insecure,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			fatal("app - Run - tracing.New", "error", err)
+		}
+		defer shutdownTracing(sdkProvider)
+		tp = sdkProvider
+	}
+
+	//Migrations
+	if cfg.Migrations.AutoApply {
+		if err := applyMigrations(cfg); err != nil {
+			fatal("app - Run - applyMigrations", "error", err)
+		}
+	}
 
 	//Postgres
-	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax), postgres.QueryLogger(l), postgres.TracerProvider(tp))
 	if err != nil {
-		log.Fatalf("app - Run - postgres.New: %v", err)
+		fatal("app - Run - postgres.New", "error", err)
 	}
 	defer pg.Close()
 
@@ -33,29 +79,184 @@ func Run(cfg *config.Config) {
 	userRepo := persistent.NewUserRepo(pg)
 	teamRepo := persistent.NewTeamRepo(pg, userRepo)
 	prRepo := persistent.NewPullRequestRepo(pg)
+	tokenRepo := persistent.NewTokenRepo(pg)
+	auditRepo := persistent.NewAuditRepo(pg)
+	schemaRepo := persistent.NewSchemaRepo(pg)
 
+	if err := checkSchemaVersion(context.Background(), schemaRepo); err != nil {
+		fatal("app - Run - checkSchemaVersion", "error", err)
+	}
+
+	//Metrics
+	var registry *metrics.Registry
+	var domainMetrics *usecase.DomainMetrics
+	if cfg.Metrics.Enabled {
+		registry = metrics.NewRegistry()
+		pg.RegisterMetrics(registry)
+		domainMetrics = usecase.NewDomainMetrics(registry, prRepo)
+	}
+
+	//Notifications
+	var notifier usecase.Notifier
+	var notifyBatcher *slack.Batcher
+	if cfg.Notify.Enabled {
+		notifyBatcher = slack.NewBatcher(slack.NewClient(&http.Client{Timeout: cfg.Notify.Timeout}), cfg.Notify.Window, cfg.Notify.MaxBatch)
+		webhook, err := notify.New(notify.Config{
+			Teams:     cfg.Notify.Teams,
+			Users:     cfg.Notify.Users,
+			Templates: cfg.Notify.Templates,
+		}, notifyBatcher)
+		if err != nil {
+			fatal("app - Run - notify.New", "error", err)
+		}
+		notifier = webhook
+	}
+
+	auditUC := usecase.NewAuditUseCase(auditRepo)
 	reviewerSelector := usecase.NewReviewerSelector()
-	teamUC := usecase.NewTeamUseCase(teamRepo, userRepo)
-	userUC := usecase.NewUserUseCase(userRepo, prRepo)
-	prUC := usecase.NewPullRequestUseCase(prRepo, userRepo, reviewerSelector)
+	prUC := usecase.NewPullRequestUseCase(pg, prRepo, userRepo, teamRepo, reviewerSelector, auditUC, domainMetrics, notifier)
+	teamUC := usecase.NewTeamUseCase(pg, teamRepo, userRepo, prUC, auditUC)
+	userUC := usecase.NewUserUseCase(pg, userRepo, prRepo, prUC, auditUC)
+	teamSyncUC := usecase.NewTeamSyncUseCase(pg, teamRepo, userRepo, teamUC, userUC)
 	statsUC := usecase.NewStatsUseCase(prRepo, userRepo)
+	var jwtAuth *usecase.JWTAuthenticator
+	if cfg.Auth.JWT.Enabled {
+		jwtAuth = newJWTAuthenticator(cfg.Auth.JWT, userRepo)
+	}
+	tokenUC := usecase.NewTokenUseCase(pg, tokenRepo, userRepo, jwtAuth, auditUC)
+
+	if cfg.Auth.BootstrapToken != "" {
+		if err := tokenUC.EnsureBootstrapToken(context.Background(), cfg.Auth.BootstrapToken); err != nil {
+			fatal("app - Run - tokenUC.EnsureBootstrapToken", "error", err)
+		}
+	}
+
+	staleAction := entity.StaleAction(cfg.Stale.Action)
+	if !staleAction.IsValid() {
+		fatal("app - Run - unknown stale action", "action", cfg.Stale.Action)
+	}
+	staleUC := usecase.NewStaleUseCase(pg, prRepo, cfg.Stale.Threshold, staleAction, auditUC)
+
+	//Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.Stale.JobEnabled {
+		go job.Every(jobsCtx, "stale-prs", cfg.Stale.Interval, func(ctx context.Context) error {
+			report, err := staleUC.ProcessStalePRs(ctx, cfg.Stale.DryRun)
+			if err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "stale PRs processed", "action", report.Action, "dry_run", report.DryRun, "affected", len(report.PullRequests))
+			return nil
+		})
+	}
+
+	if cfg.LDAP.Enabled {
+		if cfg.LDAP.URL == "" {
+			fatal("app - Run - ldap.url is required when LDAP sync is enabled")
+		}
+		directorySyncUC := usecase.NewDirectorySyncUseCase(pg, newLDAPDirectory(cfg.LDAP), teamRepo, userRepo, teamUC, userUC)
+		go job.Every(jobsCtx, "ldap-sync", cfg.LDAP.Interval, func(ctx context.Context) error {
+			result, err := directorySyncUC.Sync(ctx, cfg.LDAP.DryRun)
+			if err != nil {
+				return err
+			}
+			for _, change := range result.Changes {
+				slog.InfoContext(ctx, "directory sync change", "change", change.String(), "dry_run", result.DryRun)
+			}
+			slog.InfoContext(ctx, "directory synced", "dry_run", result.DryRun, "changes", len(result.Changes), "reassigned", len(result.ReassignedPRs))
+			return nil
+		})
+	}
+
+	if notifier != nil && cfg.Notify.ReviewSLA > 0 {
+		reviewSLAUC := usecase.NewReviewSLAUseCase(prRepo, notifier, cfg.Notify.ReviewSLA)
+		go job.Every(jobsCtx, "review-sla", cfg.Notify.SLACheckInterval, func(ctx context.Context) error {
+			breaches, err := reviewSLAUC.NotifyBreaches(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if len(breaches) > 0 {
+				slog.InfoContext(ctx, "review SLA breaches notified", "breaches", len(breaches))
+			}
+			return nil
+		})
+	}
+
+	//Rate limiting
+	rateLimitRules := newRateLimitRules(cfg.RateLimit.Groups)
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Store {
+		case "memory":
+			rateLimitStore = ratelimit.NewMemoryStore()
+		case "postgres":
+			rateLimitRepo := persistent.NewRateLimitRepo(pg)
+			rateLimitStore = rateLimitRepo
+
+			idle := maxRefillTime(rateLimitRules)
+			go job.Every(jobsCtx, "rate-limit-cleanup", rateLimitCleanupInterval, func(ctx context.Context) error {
+				_, err := rateLimitRepo.DeleteIdle(ctx, time.Now().Add(-idle))
+				return err
+			})
+		default:
+			fatal("app - Run - unknown rate limit store", "store", cfg.RateLimit.Store)
+		}
+	}
+
+	//Access policy
+	accessPolicy := policy.New(userRepo, prRepo)
+
+	//Readiness
+	readiness, err := newReadinessChecker(schemaRepo)
+	if err != nil {
+		fatal("app - Run - newReadinessChecker", "error", err)
+	}
 
 	//HTTP Server
 	mux := http.NewServeMux()
-	v1.NewRouter(mux, teamUC, userUC, prUC, statsUC)
+	v1.NewRouter(mux, teamUC, teamSyncUC, userUC, prUC, staleUC, statsUC, tokenUC, auditUC, accessPolicy, readiness)
+	if cfg.SCIM.Enabled {
+		scim.NewRouter(mux, userUC, teamUC, accessPolicy)
+	}
+	if registry != nil {
+		mux.Handle("GET /metrics", metrics.Handler(registry))
+	}
 
-	//Middleware: Recovery, Logger, CORS
-	handler := middleware.CORS(middleware.Recovery(middleware.Logger(mux)))
+	//Middleware, outermost first: CORS, RequestID, Tracing, Metrics, Recovery, Logger,
+	//RateLimitIP, Auth, RateLimitPrincipal, Actor
+	var handler http.Handler = middleware.Actor(!cfg.Auth.Enabled)(mux)
+	if rateLimitStore != nil {
+		handler = middleware.RateLimitPrincipal(rateLimitStore, rateLimitRules)(handler)
+	}
+	if cfg.Auth.Enabled {
+		handler = middleware.Auth(tokenUC, v1.RequiredScope)(handler)
+	} else {
+		slog.Warn("app - auth is disabled, all endpoints are open")
+	}
+	if rateLimitStore != nil {
+		handler = middleware.RateLimitIP(rateLimitStore, rateLimitRules, cfg.RateLimit.TrustedProxies)(handler)
+	}
+	handler = middleware.Recovery(middleware.Logger(handler))
+	if registry != nil {
+		handler = middleware.Metrics(registry, middleware.MuxRoute(mux))(handler)
+	}
+	if cfg.Tracing.Enabled {
+		handler = middleware.Tracing(tp, tracing.Propagator(), middleware.MuxRoute(mux))(handler)
+	}
+	handler = middleware.CORS(middleware.RequestID(handler))
 
 	server := &http.Server{
-		Addr:    ":" + cfg.HTTP.Port,
-		Handler: handler,
+		Addr:     ":" + cfg.HTTP.Port,
+		Handler:  handler,
+		ErrorLog: slog.NewLogLogger(l.Handler(), slog.LevelError),
 	}
 
 	go func() {
-		log.Printf("app starting: listening on port %s, PG=%s", cfg.HTTP.Port, cfg.PG.URL)
+		slog.Info("app starting", "port", cfg.HTTP.Port, "version", cfg.App.Version)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("app - ListenAndServe error: %v", err)
+			fatal("app - ListenAndServe", "error", err)
 		}
 	}()
 
@@ -64,14 +265,117 @@ func Run(cfg *config.Config) {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	sig := <-quit
-	log.Printf("app - shutting down, signal: %s", sig.String())
+	slog.Info("app - shutting down", "signal", sig.String(), "drain_delay", cfg.HTTP.ShutdownDelay)
+
+	// Report not-ready and keep serving until load balancers stop sending traffic
+	readiness.SetShuttingDown()
+	time.Sleep(cfg.HTTP.ShutdownDelay)
+
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("app - server shutdown error: %v", err)
+		slog.Error("app - server shutdown", "error", err)
 	} else {
-		log.Println("app - server gracefully stopped")
+		slog.Info("app - server gracefully stopped")
+	}
+
+	// Send the notifications of the last requests
+	if notifyBatcher != nil {
+		notifyBatcher.Close()
 	}
 }
+
+// shutdownTracing flushes the spans still buffered by the tracer provider.
+func shutdownTracing(tp *sdktrace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tp.Shutdown(ctx); err != nil {
+		slog.Error("app - tracer provider shutdown", "error", err)
+	}
+}
+
+func newJWTAuthenticator(cfg config.JWT, userRepo *persistent.UserRepo) *usecase.JWTAuthenticator {
+	var source jwt.KeySource
+	switch {
+	case cfg.JWKSFile != "":
+		fileSource, err := jwt.NewFileSource(cfg.JWKSFile)
+		if err != nil {
+			fatal("app - Run - jwt.NewFileSource", "error", err)
+		}
+		source = fileSource
+	case cfg.JWKSURL != "":
+		source = jwt.NewURLSource(cfg.JWKSURL, cfg.JWKSRefresh, nil)
+	default:
+		fatal("app - Run - jwt is enabled but neither jwks_file nor jwks_url is set")
+	}
+
+	// Without both, tokens the IdP issued for other applications would be accepted
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		fatal("app - Run - jwt is enabled but issuer or audience is not set")
+	}
+
+	defaultScope := entity.Scope(cfg.DefaultScope)
+	if defaultScope != "" && !defaultScope.IsValid() {
+		fatal("app - Run - unknown jwt default scope", "scope", cfg.DefaultScope)
+	}
+
+	verifier := jwt.NewVerifier(source,
+		jwt.Issuer(cfg.Issuer),
+		jwt.Audience(cfg.Audience),
+		jwt.Leeway(cfg.Leeway),
+	)
+
+	return usecase.NewJWTAuthenticator(verifier, userRepo, cfg.UserClaim, cfg.ScopeClaim, defaultScope)
+}
+
+func newRateLimitRules(groups []config.RateLimitGroup) []middleware.RateLimitRule {
+	rules := make([]middleware.RateLimitRule, 0, len(groups))
+	for _, g := range groups {
+		rules = append(rules, middleware.RateLimitRule{
+			Group:     g.Name,
+			Routes:    g.Routes,
+			Principal: ratelimit.Limit{Rate: g.Principal.Rate, Burst: g.Principal.Burst},
+			IP:        ratelimit.Limit{Rate: g.IP.Rate, Burst: g.IP.Burst},
+		})
+	}
+	return rules
+}
+
+// maxRefillTime is the longest time a bucket of the rules takes to refill from
+// empty. A bucket idle for longer is full and can be dropped.
+func maxRefillTime(rules []middleware.RateLimitRule) time.Duration {
+	longest := time.Minute
+	for _, rule := range rules {
+		for _, limit := range []ratelimit.Limit{rule.Principal, rule.IP} {
+			if limit.IsZero() {
+				continue
+			}
+			if d := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second)); d > longest {
+				longest = d
+			}
+		}
+	}
+	return longest
+}
+
+func newLDAPDirectory(cfg config.LDAP) *directory.LDAP {
+	return directory.NewLDAP(directory.LDAPConfig{
+		URL:                cfg.URL,
+		StartTLS:           cfg.StartTLS,
+		BindDN:             cfg.BindDN,
+		BindPassword:       cfg.BindPassword,
+		Timeout:            cfg.Timeout,
+		UserBaseDN:         cfg.UserBaseDN,
+		UserFilter:         cfg.UserFilter,
+		GroupBaseDN:        cfg.GroupBaseDN,
+		GroupFilter:        cfg.GroupFilter,
+		UserIDAttribute:    cfg.UserIDAttribute,
+		UsernameAttribute:  cfg.UsernameAttribute,
+		GroupNameAttribute: cfg.GroupNameAttribute,
+		MemberAttribute:    cfg.MemberAttribute,
+	})
+}
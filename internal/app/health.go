@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/repo/persistent"
+	"pr-reviewer-service/migrations"
+	"pr-reviewer-service/pkg/health"
+)
+
+// readinessTimeout bounds each readiness check.
+const readinessTimeout = 2 * time.Second
+
+// newReadinessChecker checks that Postgres answers and that its schema is at
+// least at the version of the newest migration built into the binary.
+func newReadinessChecker(schemaRepo *persistent.SchemaRepo) (*health.Checker, error) {
+	want, err := migrations.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	checker := health.New(readinessTimeout)
+	checker.Add("postgres", schemaRepo.Ping)
+	checker.Add("migrations", func(ctx context.Context) error {
+		version, dirty, err := schemaRepo.Version(ctx)
+		switch {
+		case err != nil:
+			return err
+		case dirty:
+			return fmt.Errorf("schema version %d is dirty, a migration failed", version)
+		case version < want:
+			return fmt.Errorf("schema is at version %d, want %d", version, want)
+		}
+		return nil
+	})
+
+	return checker, nil
+}
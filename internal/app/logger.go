@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"pr-reviewer-service/config"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestAttrs adds the request id, the acting user and the trace to records
+// logged while serving a request.
+func requestAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if id := entity.RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if actor := entity.ActorFromContext(ctx); actor != "" {
+		attrs = append(attrs, slog.String("actor_id", actor))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return attrs
+}
+
+// setupLogger installs the logger configured by cfg as the slog default.
+func setupLogger(cfg *config.Config) error {
+	l, err := logger.New(os.Stdout, cfg.Log.Level, cfg.Log.Format, requestAttrs)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(l)
+	return nil
+}
+
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
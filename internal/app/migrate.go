@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"pr-reviewer-service/config"
+	"pr-reviewer-service/internal/repo/persistent"
+	"pr-reviewer-service/migrations"
+	"pr-reviewer-service/pkg/migrator"
+)
+
+// Migrate runs the migrate subcommand: up, down [steps] or status.
+func Migrate(cfg *config.Config, args []string) error {
+	if err := setupLogger(cfg); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [steps] | status")
+	}
+
+	m, err := migrator.New(migrations.FS, cfg.PG.URL, cfg.Migrations.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		return m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("app - Migrate - invalid steps %q", args[1])
+			}
+		}
+		return m.Down(steps)
+	case "status":
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d\ndirty: %t\nlatest: %d\npending: %d\n", status.Version, status.Dirty, status.Latest, status.Pending)
+		return nil
+	default:
+		return fmt.Errorf("app - Migrate - unknown command %q", args[0])
+	}
+}
+
+// applyMigrations applies the pending embedded migrations on startup.
+func applyMigrations(cfg *config.Config) error {
+	m, err := migrator.New(migrations.FS, cfg.PG.URL, cfg.Migrations.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Up()
+}
+
+// checkSchemaVersion refuses a schema migrated by a newer binary, whose
+// tables this binary may no longer read or write correctly.
+func checkSchemaVersion(ctx context.Context, schemaRepo *persistent.SchemaRepo) error {
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	version, _, err := schemaRepo.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version > latest {
+		return fmt.Errorf("version %d, latest %d: %w", version, latest, migrator.ErrSchemaNewer)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+)
+
+// ActorHeader identifies the user on whose behalf a request is made.
+const ActorHeader = "X-User-ID"
+
+// Actor stores the user from ActorHeader in the request context. The header is
+// trusted only when trustHeader is set (authentication is disabled) or the
+// request's API token has the impersonate scope. Other tokens act as the user
+// they are bound to; naming a different user is rejected. Requests without a
+// user are treated as made by a trusted client.
+func Actor(trustHeader bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := r.Header.Get(ActorHeader)
+			if userID == "" || userID == entity.ActorFromContext(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := entity.APITokenFromContext(r.Context())
+			switch {
+			case ok && token.HasScope(entity.ScopeImpersonate), !ok && trustHeader:
+				r = r.WithContext(entity.WithActor(r.Context(), userID))
+			case ok:
+				writeError(w, http.StatusBadRequest, "INVALID_REQUEST", ActorHeader+" requires a token with the impersonate scope")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
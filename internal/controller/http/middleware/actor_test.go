@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-reviewer-service/internal/controller/http/middleware"
+	"pr-reviewer-service/internal/entity"
+)
+
+func TestActor(t *testing.T) {
+	writeToken := entity.APIToken{Scopes: []entity.Scope{entity.ScopeWrite}}
+	boundToken := entity.APIToken{Scopes: []entity.Scope{entity.ScopeWrite}, UserID: "u1"}
+	impersonator := entity.APIToken{Scopes: []entity.Scope{entity.ScopeWrite, entity.ScopeImpersonate}}
+
+	tests := []struct {
+		name        string
+		trustHeader bool
+		token       *entity.APIToken
+		header      string
+		wantStatus  int
+		wantActor   string
+	}{
+		{name: "auth disabled honours the header", trustHeader: true, header: "u2", wantStatus: http.StatusOK, wantActor: "u2"},
+		{name: "auth disabled without header", trustHeader: true, wantStatus: http.StatusOK},
+		{name: "public route ignores the header", header: "u2", wantStatus: http.StatusOK},
+		{name: "token without user rejects the header", token: &writeToken, header: "u2", wantStatus: http.StatusBadRequest},
+		{name: "bound token rejects another user", token: &boundToken, header: "u2", wantStatus: http.StatusBadRequest},
+		{name: "bound token accepts its own user", token: &boundToken, header: "u1", wantStatus: http.StatusOK, wantActor: "u1"},
+		{name: "bound token without header", token: &boundToken, wantStatus: http.StatusOK, wantActor: "u1"},
+		{name: "impersonate scope honours the header", token: &impersonator, header: "u2", wantStatus: http.StatusOK, wantActor: "u2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			handler := middleware.Actor(tt.trustHeader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = entity.ActorFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("POST", "/users/setIsActive", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.ActorHeader, tt.header)
+			}
+			if tt.token != nil {
+				// as Auth leaves the context
+				ctx := entity.WithAPIToken(req.Context(), *tt.token)
+				if tt.token.UserID != "" {
+					ctx = entity.WithActor(ctx, tt.token.UserID)
+				}
+				req = req.WithContext(ctx)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if actor != tt.wantActor {
+				t.Errorf("actor = %q, want %q", actor, tt.wantActor)
+			}
+		})
+	}
+}
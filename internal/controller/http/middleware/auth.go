@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"strings"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, raw string) (entity.APIToken, error)
+}
+
+// ScopeFunc returns the scope a request needs. An empty scope makes the route public.
+type ScopeFunc func(r *http.Request) entity.Scope
+
+// Auth requires a bearer API token with the scope the route needs. A token bound
+// to a user makes the request act as that user.
+func Auth(auth Authenticator, scopeFor ScopeFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := scopeFor(r)
+			if scope == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || raw == "" {
+				writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing bearer token")
+				return
+			}
+
+			token, err := auth.Authenticate(r.Context(), raw)
+			if err != nil {
+				if errors.Is(err, entity.ErrUnauthorized) {
+					writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid, expired or revoked token")
+					return
+				}
+				slog.ErrorContext(r.Context(), "middleware - Auth - Authenticate", "error", err)
+				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "authentication failed")
+				return
+			}
+
+			if !token.HasScope(scope) {
+				writeError(w, http.StatusForbidden, "FORBIDDEN", "token lacks the "+string(scope)+" scope")
+				return
+			}
+
+			ctx := entity.WithAPIToken(r.Context(), token)
+			if token.UserID != "" {
+				ctx = entity.WithActor(ctx, token.UserID)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeError writes an error in the same shape as the v1 API.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/controller/http/middleware"
+	v1 "pr-reviewer-service/internal/controller/http/v1"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// tokenRepo finds tokens by the raw value they were issued as.
+type tokenRepo struct {
+	repo.TokenRepo
+	tokens map[string]entity.APIToken
+}
+
+func (r tokenRepo) GetByHash(_ context.Context, hash string) (entity.APIToken, error) {
+	for raw, token := range r.tokens {
+		if entity.HashToken(raw) == hash {
+			if raw == "broken" {
+				return entity.APIToken{}, errors.New("connection refused")
+			}
+			return token, nil
+		}
+	}
+	return entity.APIToken{}, entity.ErrNotFound
+}
+
+func (r tokenRepo) TouchLastUsed(context.Context, int64, time.Time) error {
+	return nil
+}
+
+func TestAuth(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tokens := tokenRepo{tokens: map[string]entity.APIToken{
+		"read":    {TokenID: 1, Scopes: []entity.Scope{entity.ScopeRead}},
+		"write":   {TokenID: 2, Scopes: []entity.Scope{entity.ScopeWrite}, ExpiresAt: &future},
+		"admin":   {TokenID: 3, Scopes: []entity.Scope{entity.ScopeAdmin}, UserID: "u1"},
+		"expired": {TokenID: 4, Scopes: []entity.Scope{entity.ScopeAdmin}, ExpiresAt: &past},
+		"revoked": {TokenID: 5, Scopes: []entity.Scope{entity.ScopeAdmin}, RevokedAt: &past},
+		"broken":  {},
+	}}
+	auth := usecase.NewTokenUseCase(nil, tokens, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		wantStatus    int
+		wantCode      string
+		wantActor     string
+	}{
+		{name: "public route without token", method: "GET", path: "/health", wantStatus: http.StatusOK},
+		{name: "missing token", method: "GET", path: "/team/get", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "not a bearer token", method: "GET", path: "/team/get", authorization: "Basic cmVhZA==", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "unknown token", method: "GET", path: "/team/get", authorization: "Bearer guessed", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "expired token", method: "GET", path: "/team/get", authorization: "Bearer expired", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "revoked token", method: "GET", path: "/team/get", authorization: "Bearer revoked", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "token store failure", method: "GET", path: "/team/get", authorization: "Bearer broken", wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+		{name: "read scope reads", method: "GET", path: "/team/get", authorization: "Bearer read", wantStatus: http.StatusOK},
+		{name: "read scope cannot write", method: "POST", path: "/pullRequest/create", authorization: "Bearer read", wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "write scope includes read", method: "GET", path: "/team/get", authorization: "Bearer write", wantStatus: http.StatusOK},
+		{name: "write scope writes", method: "POST", path: "/pullRequest/create", authorization: "Bearer write", wantStatus: http.StatusOK},
+		{name: "write scope cannot process stale PRs", method: "POST", path: "/pullRequest/stale/process", authorization: "Bearer write", wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "write scope cannot manage tokens", method: "GET", path: "/auth/tokens", authorization: "Bearer write", wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "admin scope includes write", method: "POST", path: "/pullRequest/create", authorization: "Bearer admin", wantStatus: http.StatusOK, wantActor: "u1"},
+		{name: "admin scope processes stale PRs", method: "POST", path: "/pullRequest/stale/process", authorization: "Bearer admin", wantStatus: http.StatusOK, wantActor: "u1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			handler := middleware.Auth(auth, v1.RequiredScope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = entity.ActorFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode != "" {
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				if body.Error.Code != tt.wantCode {
+					t.Errorf("error code = %q, want %q", body.Error.Code, tt.wantCode)
+				}
+			}
+			if actor != tt.wantActor {
+				t.Errorf("actor = %q, want %q", actor, tt.wantActor)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"pr-reviewer-service/pkg/metrics"
+)
+
+// RouteFunc returns the route pattern a request matches, or "" if none does.
+type RouteFunc func(r *http.Request) string
+
+// unmatchedRoute labels requests that match no route, so that probing random
+// paths does not create new series.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts requests and observes their latency by method, route
+// pattern and status.
+func Metrics(reg *metrics.Registry, routeFor RouteFunc) func(http.Handler) http.Handler {
+	requests := reg.NewCounter("http_requests_total",
+		"HTTP requests by method, route and status.",
+		"method", "route", "status")
+	duration := reg.NewHistogram("http_request_duration_seconds",
+		"HTTP request latency by method, route and status.",
+		metrics.DefaultBuckets, "method", "route", "status")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := routeFor(r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r)
+
+			status := strconv.Itoa(wrapped.status)
+			requests.Inc(r.Method, route, status)
+			duration.Observe(time.Since(start).Seconds(), r.Method, route, status)
+		})
+	}
+}
+
+// MuxRoute returns the pattern of the mux route a request is dispatched to.
+func MuxRoute(mux *http.ServeMux) RouteFunc {
+	return func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+}
@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/controller/http/middleware"
+	"pr-reviewer-service/pkg/metrics"
+)
+
+func TestMetricsLabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /team/get", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /team/add", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	reg := metrics.NewRegistry()
+	handler := middleware.Metrics(reg, middleware.MuxRoute(mux))(mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/team/get?team_name=a", nil),
+		httptest.NewRequest("GET", "/team/get?team_name=b", nil),
+		httptest.NewRequest("POST", "/team/add", nil),
+		httptest.NewRequest("GET", "/no/such/path", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var out strings.Builder
+	if err := reg.WriteTo(context.Background(), &out); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",route="GET /team/get",status="200"} 2`,
+		`http_requests_total{method="POST",route="POST /team/add",status="400"} 1`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="GET /team/get",status="200"} 2`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing %s in:\n%s", line, out.String())
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/pkg/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitRule limits one group of routes.
+type RateLimitRule struct {
+	Group string
+	// Routes are "METHOD /path" or "/path" patterns; a trailing "*" in the path
+	// matches any suffix. A rule without routes matches every request.
+	Routes []string
+	// Principal limits each API token or JWT user; IP limits each client
+	// address. A zero limit disables the bucket.
+	Principal ratelimit.Limit
+	IP        ratelimit.Limit
+}
+
+// RateLimitIP limits every request by its client address. It runs before
+// Auth, so that requests with an invalid token are limited too. trustedProxies
+// is the number of proxies in front of the service that append to
+// X-Forwarded-For, see clientIP; with zero the header is ignored.
+func RateLimitIP(store ratelimit.Store, rules []RateLimitRule, trustedProxies int) func(http.Handler) http.Handler {
+	return rateLimit(store, rules, func(rule RateLimitRule, r *http.Request) (string, ratelimit.Limit) {
+		return rule.Group + ":ip:" + clientIP(r, trustedProxies), rule.IP
+	})
+}
+
+// RateLimitPrincipal limits authenticated requests by their API token or JWT
+// user. It runs after Auth; requests without a principal are left to
+// RateLimitIP. The X-User-ID header is not used, since any client can set it.
+func RateLimitPrincipal(store ratelimit.Store, rules []RateLimitRule) func(http.Handler) http.Handler {
+	return rateLimit(store, rules, func(rule RateLimitRule, r *http.Request) (string, ratelimit.Limit) {
+		token, ok := entity.APITokenFromContext(r.Context())
+		if !ok {
+			return "", ratelimit.Limit{}
+		}
+		if token.TokenID != 0 {
+			return rule.Group + ":token:" + strconv.FormatInt(token.TokenID, 10), rule.Principal
+		}
+		return rule.Group + ":user:" + token.UserID, rule.Principal
+	})
+}
+
+// rateLimit applies the first rule matching a request to the bucket returned
+// by bucketFor and answers 429 once it is empty. Requests matching no rule or
+// with a zero limit are not limited. When the store fails the request is let
+// through.
+func rateLimit(store ratelimit.Store, rules []RateLimitRule, bucketFor func(rule RateLimitRule, r *http.Request) (string, ratelimit.Limit)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, ok := matchRule(rules, r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, limit := bucketFor(rule, r)
+			if limit.IsZero() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := store.Take(r.Context(), key, limit, time.Now())
+			if err != nil {
+				slog.ErrorContext(r.Context(), "middleware - RateLimit - store.Take", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+			if !result.Allowed {
+				h.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests, retry later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matchRule(rules []RateLimitRule, r *http.Request) (RateLimitRule, bool) {
+	for _, rule := range rules {
+		if len(rule.Routes) == 0 {
+			return rule, true
+		}
+		for _, pattern := range rule.Routes {
+			if routeMatches(pattern, r) {
+				return rule, true
+			}
+		}
+	}
+	return RateLimitRule{}, false
+}
+
+func routeMatches(pattern string, r *http.Request) bool {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	if method != "" && method != r.Method {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(path, "*"); ok {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+	return r.URL.Path == path
+}
+
+// clientIP returns the address the outermost of trustedProxies proxies saw the
+// request come from. Each proxy appends the address of its peer to
+// X-Forwarded-For, so only that many entries from the right are trusted: the
+// entries to their left are set by the client. When the header is shorter the
+// request did not pass every proxy and the peer address is used.
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var entries []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			entries = append(entries, strings.Split(header, ",")...)
+		}
+		if len(entries) >= trustedProxies {
+			if ip := strings.TrimSpace(entries[len(entries)-trustedProxies]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-reviewer-service/internal/controller/http/middleware"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/pkg/ratelimit"
+)
+
+var rateLimitRules = []middleware.RateLimitRule{{
+	Group:     "all",
+	Principal: ratelimit.Limit{Rate: 0.001, Burst: 1},
+	IP:        ratelimit.Limit{Rate: 0.001, Burst: 2},
+}}
+
+func TestRateLimitIPLimitsRejectedTokens(t *testing.T) {
+	unauthorized := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	handler := middleware.RateLimitIP(ratelimit.NewMemoryStore(), rateLimitRules, 0)(unauthorized)
+
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}
+	for i, status := range want {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		req.Header.Set("Authorization", "Bearer guessed")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != status {
+			t.Fatalf("request %d: status = %d, want %d", i+1, rec.Code, status)
+		}
+	}
+}
+
+func TestRateLimitIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// One proxy in front: it appends the address it saw the request from
+	handler := middleware.RateLimitIP(ratelimit.NewMemoryStore(), rateLimitRules, 1)(ok)
+
+	serve := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		req.RemoteAddr = "10.0.0.2:41000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name         string
+		forwardedFor string
+		want         int
+	}{
+		{name: "first request", forwardedFor: "203.0.113.7", want: http.StatusOK},
+		{name: "spoofed entry", forwardedFor: "198.51.100.1, 203.0.113.7", want: http.StatusOK},
+		{name: "another spoofed entry", forwardedFor: "198.51.100.2, 203.0.113.7", want: http.StatusTooManyRequests},
+		{name: "another client", forwardedFor: "198.51.100.2, 203.0.113.8", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if got := serve(tt.forwardedFor); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitPrincipal(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.RateLimitPrincipal(ratelimit.NewMemoryStore(), rateLimitRules)(ok)
+
+	serve := func(token *entity.APIToken) int {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		if token != nil {
+			req = req.WithContext(entity.WithAPIToken(req.Context(), *token))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	first := &entity.APIToken{TokenID: 1}
+	second := &entity.APIToken{TokenID: 2}
+	tests := []struct {
+		name  string
+		token *entity.APIToken
+		want  int
+	}{
+		{name: "first request of a token", token: first, want: http.StatusOK},
+		{name: "token over its limit", token: first, want: http.StatusTooManyRequests},
+		{name: "another token has its own bucket", token: second, want: http.StatusOK},
+		{name: "request without a principal", want: http.StatusOK},
+		{name: "another request without a principal", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if got := serve(tt.token); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-reviewer-service/internal/controller/http/middleware"
+	"pr-reviewer-service/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingContinuesCallerTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(tracing.Config{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+
+	var inner trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /pullRequest/create", func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	handler := middleware.Tracing(tp, tracing.Propagator(), middleware.MuxRoute(mux))(mux)
+
+	req := httptest.NewRequest("POST", "/pullRequest/create", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name != "POST /pullRequest/create" {
+		t.Errorf("name = %q", span.Name)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the caller's", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want the caller's", got)
+	}
+	if inner.SpanID() != span.SpanContext.SpanID() {
+		t.Error("handler context does not carry the request span")
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %v, want error", span.Status.Code)
+	}
+
+	var status attribute.Value
+	for _, attr := range span.Attributes {
+		if attr.Key == "http.response.status_code" {
+			status = attr.Value
+		}
+	}
+	if status.AsInt64() != http.StatusInternalServerError {
+		t.Errorf("http.response.status_code = %v, want 500", status.Emit())
+	}
+}
@@ -0,0 +1,61 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"strconv"
+)
+
+// scimType values of RFC 7644 section 3.12.
+const (
+	scimInvalidFilter = "invalidFilter"
+	scimInvalidSyntax = "invalidSyntax"
+	scimInvalidValue  = "invalidValue"
+	scimInvalidPath   = "invalidPath"
+	scimMutability    = "mutability"
+	scimUniqueness    = "uniqueness"
+)
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func respond(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func respondError(w http.ResponseWriter, status int, scimType, detail string) {
+	respond(w, status, errorResponse{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// respondUseCaseError reports an error of a use case or a permission check.
+func respondUseCaseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrForbidden):
+		respondError(w, http.StatusForbidden, "", "action is not allowed for the user")
+	case errors.Is(err, entity.ErrNotFound):
+		respondError(w, http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, entity.ErrUserAlreadyExists):
+		respondError(w, http.StatusConflict, scimUniqueness, "userName is already taken")
+	case errors.Is(err, entity.ErrTeamAlreadyExists):
+		respondError(w, http.StatusConflict, scimUniqueness, "displayName is already taken")
+	case errors.Is(err, entity.ErrUserArchived):
+		respondError(w, http.StatusBadRequest, scimInvalidValue, "user is deleted")
+	case errors.Is(err, entity.ErrTeamArchived):
+		respondError(w, http.StatusNotFound, "", "group is deleted")
+	default:
+		respondError(w, http.StatusInternalServerError, "", err.Error())
+	}
+}
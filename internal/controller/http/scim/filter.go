@@ -0,0 +1,140 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 200
+)
+
+// equalityFilter matches `attribute eq "value"`, the only filter identity
+// providers send when looking up a resource before creating it.
+var equalityFilter = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9._]*)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// filter is a parsed equality filter. A zero filter matches everything.
+type filter struct {
+	attribute string
+	value     string
+}
+
+func parseFilter(s string) (filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return filter{}, nil
+	}
+
+	m := equalityFilter.FindStringSubmatch(s)
+	if m == nil {
+		return filter{}, fmt.Errorf("unsupported filter %q: only `attribute eq \"value\"` is supported", s)
+	}
+
+	value, err := strconv.Unquote(m[2])
+	if err != nil {
+		return filter{}, fmt.Errorf("invalid filter value %s", m[2])
+	}
+
+	return filter{attribute: strings.ToLower(m[1]), value: value}, nil
+}
+
+// memberPath parses a patch path that selects one group member, such as
+// `members[value eq "u1"]`.
+func memberPath(path string) (string, bool) {
+	inner, ok := strings.CutPrefix(path, "members[")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, "]")
+	if !ok {
+		return "", false
+	}
+
+	f, err := parseFilter(inner)
+	if err != nil || f.attribute != "value" {
+		return "", false
+	}
+	return f.value, true
+}
+
+// page is the 1-based window of a list request.
+type page struct {
+	startIndex int
+	count      int
+}
+
+func parsePage(query url.Values) (page, error) {
+	p := page{startIndex: 1, count: defaultCount}
+
+	if v := query.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return page{}, errors.New("startIndex must be an integer")
+		}
+		p.startIndex = max(n, 1)
+	}
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return page{}, errors.New("count must be an integer")
+		}
+		p.count = min(max(n, 0), maxCount)
+	}
+
+	return p, nil
+}
+
+// slice returns the bounds of the page in a list of n items.
+func (p page) slice(n int) (int, int) {
+	from := min(p.startIndex-1, n)
+	return from, min(from+p.count, n)
+}
+
+func newListResponse(p page, total int, resources []any) listResponse {
+	return listResponse{
+		Schemas:      []string{listSchema},
+		TotalResults: total,
+		StartIndex:   p.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+type patchRequest struct {
+	Schemas    []string  `json:"schemas"`
+	Operations []patchOp `json:"Operations"`
+}
+
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// op returns the lower-cased operation: some providers send "Replace".
+func (o patchOp) op() string {
+	return strings.ToLower(o.Op)
+}
+
+// boolValue decodes a boolean that some providers send as a string.
+func boolValue(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, errors.New("expected a boolean")
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New("expected a boolean")
+	}
+	return b, nil
+}
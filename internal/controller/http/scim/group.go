@@ -0,0 +1,318 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase"
+	"strings"
+)
+
+type groupRoutes struct {
+	t *usecase.TeamUseCase
+	p *policy.Policy
+}
+
+func newGroupRoutes(mux *http.ServeMux, t *usecase.TeamUseCase, p *policy.Policy) {
+	r := &groupRoutes{t, p}
+
+	mux.HandleFunc("GET "+basePath+"/Groups", r.list)
+	mux.HandleFunc("POST "+basePath+"/Groups", r.create)
+	mux.HandleFunc("GET "+basePath+"/Groups/{id}", r.get)
+	mux.HandleFunc("PUT "+basePath+"/Groups/{id}", r.replace)
+	mux.HandleFunc("PATCH "+basePath+"/Groups/{id}", r.patch)
+	mux.HandleFunc("DELETE "+basePath+"/Groups/{id}", r.delete)
+}
+
+func (r *groupRoutes) list(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	f, err := parseFilter(req.URL.Query().Get("filter"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+		return
+	}
+	if f.attribute != "" && f.attribute != "displayname" && f.attribute != "id" {
+		respondError(w, http.StatusBadRequest, scimInvalidFilter, "groups can be filtered by displayName or id only")
+		return
+	}
+	p, err := parsePage(req.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidValue, err.Error())
+		return
+	}
+
+	var names []string
+	if f.attribute != "" {
+		// displayName and id are both the team name
+		names = []string{f.value}
+	} else {
+		tree, err := r.t.GetTree(req.Context(), "")
+		if err != nil {
+			respondUseCaseError(w, err)
+			return
+		}
+		names = flatten(tree, nil)
+	}
+
+	resources := make([]any, 0, len(names))
+	for _, name := range names {
+		resource, err := r.load(req.Context(), name)
+		if errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			respondUseCaseError(w, err)
+			return
+		}
+		resources = append(resources, resource)
+	}
+
+	from, to := p.slice(len(resources))
+	respond(w, http.StatusOK, newListResponse(p, len(resources), resources[from:to]))
+}
+
+func flatten(nodes []entity.TeamNode, names []string) []string {
+	for _, node := range nodes {
+		names = append(names, node.TeamName)
+		names = flatten(node.Children, names)
+	}
+	return names
+}
+
+func (r *groupRoutes) get(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	resource, err := r.load(req.Context(), req.PathValue("id"))
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, resource)
+}
+
+// create makes a team of existing users: members must be provisioned first.
+func (r *groupRoutes) create(w http.ResponseWriter, req *http.Request) {
+	var input group
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+	if input.DisplayName == "" {
+		respondError(w, http.StatusBadRequest, scimInvalidValue, "displayName is required")
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	team, err := r.t.CreateTeamWithUsers(req.Context(), input.DisplayName, input.memberIDs())
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", groupLocation(team.TeamName))
+	respond(w, http.StatusCreated, toGroup(team))
+}
+
+// replace handles PUT: the team gets exactly the listed members.
+func (r *groupRoutes) replace(w http.ResponseWriter, req *http.Request) {
+	teamName := req.PathValue("id")
+
+	var input group
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+	if input.DisplayName != "" && input.DisplayName != teamName {
+		respondError(w, http.StatusBadRequest, scimMutability, "displayName cannot be changed")
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, err := r.load(req.Context(), teamName); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	team, _, err := r.t.SetMembers(req.Context(), teamName, input.memberIDs())
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, toGroup(team))
+}
+
+// patch handles PATCH. Operations are applied in order and in one
+// transaction: either all of them or none. Members removed from the team have
+// their OPEN reviews drawn from it reassigned.
+func (r *groupRoutes) patch(w http.ResponseWriter, req *http.Request) {
+	teamName := req.PathValue("id")
+
+	var input patchRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, err := r.load(req.Context(), teamName); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	var changes []entity.MemberChange
+	for _, op := range input.Operations {
+		opChanges, status, scimType, detail := parsePatch(teamName, op)
+		if status != 0 {
+			respondError(w, status, scimType, detail)
+			return
+		}
+		changes = append(changes, opChanges...)
+	}
+
+	if _, _, err := r.t.ChangeMembers(req.Context(), teamName, changes); err != nil {
+		status, scimType, detail := statusOf(err)
+		respondError(w, status, scimType, detail)
+		return
+	}
+
+	resource, err := r.load(req.Context(), teamName)
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, resource)
+}
+
+// parsePatch returns the member changes of one patch operation, or the
+// status, scimType and detail of the error response when it is invalid.
+func parsePatch(teamName string, op patchOp) ([]entity.MemberChange, int, string, string) {
+	path := strings.ToLower(op.Path)
+	if userID, ok := memberPath(op.Path); ok {
+		if op.op() != "remove" {
+			return nil, http.StatusBadRequest, scimInvalidPath, "a member filter can only be removed"
+		}
+		return []entity.MemberChange{{Op: entity.MemberRemove, UserIDs: []string{userID}}}, 0, "", ""
+	}
+
+	switch {
+	case path == "displayname" || path == "" && op.op() != "remove":
+		var attrs struct {
+			DisplayName *string `json:"displayName"`
+			Members     *[]ref  `json:"members"`
+		}
+		if path == "displayname" {
+			attrs.DisplayName = new(string)
+			if err := json.Unmarshal(op.Value, attrs.DisplayName); err != nil {
+				return nil, http.StatusBadRequest, scimInvalidValue, "displayName must be a string"
+			}
+		} else if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return nil, http.StatusBadRequest, scimInvalidValue, "value must be an object when path is omitted"
+		}
+
+		if attrs.DisplayName != nil && *attrs.DisplayName != teamName {
+			return nil, http.StatusBadRequest, scimMutability, "displayName cannot be changed"
+		}
+		if attrs.Members == nil {
+			return nil, 0, "", ""
+		}
+		return memberChange(op.op(), group{Members: *attrs.Members})
+	case path == "members":
+		var members []ref
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return nil, http.StatusBadRequest, scimInvalidValue, "members must be a list of {\"value\": user id}"
+			}
+		}
+		return memberChange(op.op(), group{Members: members})
+	default:
+		return nil, http.StatusBadRequest, scimInvalidPath, "unsupported path " + op.Path
+	}
+}
+
+func memberChange(op string, members group) ([]entity.MemberChange, int, string, string) {
+	switch op {
+	case "add":
+		return []entity.MemberChange{{Op: entity.MemberAdd, UserIDs: members.memberIDs()}}, 0, "", ""
+	case "replace":
+		return []entity.MemberChange{{Op: entity.MemberReplace, UserIDs: members.memberIDs()}}, 0, "", ""
+	case "remove":
+		if len(members.Members) == 0 {
+			// Removing the attribute removes every member
+			return []entity.MemberChange{{Op: entity.MemberReplace}}, 0, "", ""
+		}
+		return []entity.MemberChange{{Op: entity.MemberRemove, UserIDs: members.memberIDs()}}, 0, "", ""
+	default:
+		return nil, http.StatusBadRequest, scimInvalidSyntax, "unsupported patch operation " + op
+	}
+}
+
+func statusOf(err error) (int, string, string) {
+	switch {
+	case err == nil:
+		return 0, "", ""
+	case errors.Is(err, entity.ErrNotFound):
+		return http.StatusBadRequest, scimInvalidValue, "member not found"
+	case errors.Is(err, entity.ErrUserArchived):
+		return http.StatusBadRequest, scimInvalidValue, "member is deleted"
+	default:
+		return http.StatusInternalServerError, "", err.Error()
+	}
+}
+
+// delete archives the team: its PR history is kept.
+func (r *groupRoutes) delete(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, err := r.load(req.Context(), req.PathValue("id")); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, _, err := r.t.ArchiveTeam(req.Context(), req.PathValue("id")); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load returns the team as a SCIM resource. Archived teams are reported as
+// not found.
+func (r *groupRoutes) load(ctx context.Context, teamName string) (group, error) {
+	team, err := r.t.GetTeam(ctx, teamName)
+	if err != nil {
+		return group{}, err
+	}
+	if team.IsArchived() {
+		return group{}, entity.ErrNotFound
+	}
+	return toGroup(team), nil
+}
@@ -0,0 +1,136 @@
+// Package scim implements the SCIM 2.0 (RFC 7643, RFC 7644) provisioning
+// endpoints identity providers use to push users and groups. SCIM users are
+// service users: userName is the user_id and displayName the username. SCIM
+// groups are teams named by their displayName.
+package scim
+
+import (
+	"net/url"
+	"pr-reviewer-service/internal/entity"
+	"strings"
+	"time"
+)
+
+const (
+	userSchema   = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema  = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	listSchema   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	errorSchema  = "urn:ietf:params:scim:api:messages:2.0:Error"
+	configSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	// ContentType is the media type of SCIM requests and responses.
+	ContentType = "application/scim+json"
+
+	basePath = "/scim/v2"
+)
+
+type meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	Location     string     `json:"location"`
+}
+
+type name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// ref points at another resource: a group of a user or a member of a group.
+type ref struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type user struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Groups      []ref    `json:"groups,omitempty"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+// username picks the display name of an incoming user, falling back to the
+// formatted or composed name and finally to userName.
+func (u user) username() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if full := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); full != "" {
+			return full
+		}
+	}
+	return u.UserName
+}
+
+type group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []ref    `json:"members"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+func (g group) memberIDs() []string {
+	ids := make([]string, len(g.Members))
+	for i, m := range g.Members {
+		ids[i] = m.Value
+	}
+	return ids
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+func userLocation(userID string) string {
+	return basePath + "/Users/" + url.PathEscape(userID)
+}
+
+func groupLocation(teamName string) string {
+	return basePath + "/Groups/" + url.PathEscape(teamName)
+}
+
+func toUser(u entity.User, memberships []entity.Membership) user {
+	active := u.IsActive
+	created := u.CreatedAt
+	resource := user{
+		Schemas:     []string{userSchema},
+		ID:          u.UserID,
+		UserName:    u.UserID,
+		DisplayName: u.Username,
+		Active:      &active,
+		Meta:        &meta{ResourceType: "User", Created: &created, Location: userLocation(u.UserID)},
+	}
+	for _, m := range memberships {
+		resource.Groups = append(resource.Groups, ref{Value: m.TeamName, Display: m.TeamName, Ref: groupLocation(m.TeamName)})
+	}
+	return resource
+}
+
+func toGroup(t entity.Team) group {
+	created := t.CreatedAt
+	resource := group{
+		Schemas:     []string{groupSchema},
+		ID:          t.TeamName,
+		DisplayName: t.TeamName,
+		Members:     make([]ref, 0, len(t.Members)),
+		Meta:        &meta{ResourceType: "Group", Created: &created, Location: groupLocation(t.TeamName)},
+	}
+	for _, m := range t.Members {
+		resource.Members = append(resource.Members, ref{Value: m.UserID, Display: m.Username, Ref: userLocation(m.UserID)})
+	}
+	return resource
+}
@@ -0,0 +1,35 @@
+package scim
+
+import (
+	"net/http"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase"
+)
+
+// serviceProviderConfig advertises the supported subset of the protocol.
+var serviceProviderConfig = map[string]any{
+	"schemas":        []string{configSchema},
+	"patch":          map[string]bool{"supported": true},
+	"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+	"filter":         map[string]any{"supported": true, "maxResults": maxCount},
+	"changePassword": map[string]bool{"supported": false},
+	"sort":           map[string]bool{"supported": false},
+	"etag":           map[string]bool{"supported": false},
+	"authenticationSchemes": []map[string]any{{
+		"type":        "oauthbearertoken",
+		"name":        "API token",
+		"description": "An API token with the admin scope",
+		"primary":     true,
+	}},
+}
+
+// NewRouter registers the SCIM endpoints under /scim/v2. They need an API
+// token with the admin scope, see v1.RequiredScope.
+func NewRouter(mux *http.ServeMux, u *usecase.UserUseCase, t *usecase.TeamUseCase, p *policy.Policy) {
+	mux.HandleFunc("GET "+basePath+"/ServiceProviderConfig", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, serviceProviderConfig)
+	})
+
+	newUserRoutes(mux, u, p)
+	newGroupRoutes(mux, t, p)
+}
@@ -0,0 +1,122 @@
+package scim_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/controller/http/scim"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+)
+
+// The requests below are rejected before reaching the use cases, so the
+// router is built without them.
+func newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	scim.NewRouter(mux, nil, nil, policy.New(nil, nil))
+	return mux
+}
+
+func TestRejectedRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		actor    string
+		status   int
+		scimType string
+	}{
+		{
+			name:   "user without admin rights",
+			method: http.MethodGet, target: "/scim/v2/Users/u1",
+			actor:  "u1",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unsupported filter",
+			method: http.MethodGet, target: `/scim/v2/Users?filter=userName+sw+"a"`,
+			status: http.StatusBadRequest, scimType: "invalidFilter",
+		},
+		{
+			name:   "unsupported filter attribute",
+			method: http.MethodGet, target: `/scim/v2/Groups?filter=members+eq+"u1"`,
+			status: http.StatusBadRequest, scimType: "invalidFilter",
+		},
+		{
+			name:   "user without userName",
+			method: http.MethodPost, target: "/scim/v2/Users",
+			body:   `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"displayName":"Alice"}`,
+			status: http.StatusBadRequest, scimType: "invalidValue",
+		},
+		{
+			name:   "userName change",
+			method: http.MethodPut, target: "/scim/v2/Users/u1",
+			body:   `{"userName":"u2"}`,
+			status: http.StatusBadRequest, scimType: "mutability",
+		},
+		{
+			name:   "unknown patch operation",
+			method: http.MethodPatch, target: "/scim/v2/Users/u1",
+			body:   `{"Operations":[{"op":"Move","path":"active","value":false}]}`,
+			status: http.StatusBadRequest, scimType: "invalidSyntax",
+		},
+		{
+			name:   "displayName change",
+			method: http.MethodPut, target: "/scim/v2/Groups/backend",
+			body:   `{"displayName":"frontend"}`,
+			status: http.StatusBadRequest, scimType: "mutability",
+		},
+	}
+
+	mux := newMux()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.actor != "" {
+				req = req.WithContext(entity.WithActor(req.Context(), tt.actor))
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != scim.ContentType {
+				t.Errorf("Content-Type = %q", ct)
+			}
+
+			var body struct {
+				Schemas  []string `json:"schemas"`
+				Status   string   `json:"status"`
+				ScimType string   `json:"scimType"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body.Schemas) != 1 || body.Schemas[0] != "urn:ietf:params:scim:api:messages:2.0:Error" {
+				t.Errorf("schemas = %v", body.Schemas)
+			}
+			if body.ScimType != tt.scimType {
+				t.Errorf("scimType = %q, want %q", body.ScimType, tt.scimType)
+			}
+		})
+	}
+}
+
+func TestServiceProviderConfig(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scim/v2/ServiceProviderConfig", nil))
+
+	var body struct {
+		Patch struct {
+			Supported bool `json:"supported"`
+		} `json:"patch"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusOK || !body.Patch.Supported {
+		t.Errorf("status = %d, patch supported = %v (%v)", rec.Code, body.Patch.Supported, err)
+	}
+}
@@ -0,0 +1,267 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase"
+	"strings"
+)
+
+type userRoutes struct {
+	u *usecase.UserUseCase
+	p *policy.Policy
+}
+
+func newUserRoutes(mux *http.ServeMux, u *usecase.UserUseCase, p *policy.Policy) {
+	r := &userRoutes{u, p}
+
+	mux.HandleFunc("GET "+basePath+"/Users", r.list)
+	mux.HandleFunc("POST "+basePath+"/Users", r.create)
+	mux.HandleFunc("GET "+basePath+"/Users/{id}", r.get)
+	mux.HandleFunc("PUT "+basePath+"/Users/{id}", r.replace)
+	mux.HandleFunc("PATCH "+basePath+"/Users/{id}", r.patch)
+	mux.HandleFunc("DELETE "+basePath+"/Users/{id}", r.delete)
+}
+
+func (r *userRoutes) list(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	f, err := parseFilter(req.URL.Query().Get("filter"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+		return
+	}
+	p, err := parsePage(req.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidValue, err.Error())
+		return
+	}
+
+	switch f.attribute {
+	case "":
+		page, err := r.u.ListUsers(req.Context(), entity.UserFilter{Limit: p.count, Offset: p.startIndex - 1})
+		if err != nil {
+			respondUseCaseError(w, err)
+			return
+		}
+
+		resources := make([]any, 0, len(page.Users))
+		for _, u := range page.Users {
+			resources = append(resources, toUser(u.User, nil))
+		}
+		respond(w, http.StatusOK, newListResponse(p, page.Total, resources))
+	case "username", "id":
+		// userName and id are both the user_id
+		resources := []any{}
+		if profile, err := r.u.GetUser(req.Context(), f.value); err == nil && !profile.IsArchived() {
+			resources = append(resources, toUser(profile.User, profile.Memberships))
+		} else if err != nil && !errors.Is(err, entity.ErrNotFound) {
+			respondUseCaseError(w, err)
+			return
+		}
+
+		total := len(resources)
+		from, to := p.slice(total)
+		respond(w, http.StatusOK, newListResponse(p, total, resources[from:to]))
+	default:
+		respondError(w, http.StatusBadRequest, scimInvalidFilter, "users can be filtered by userName or id only")
+	}
+}
+
+func (r *userRoutes) get(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	resource, err := r.load(req.Context(), req.PathValue("id"))
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, resource)
+}
+
+func (r *userRoutes) create(w http.ResponseWriter, req *http.Request) {
+	var input user
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+	if input.UserName == "" {
+		respondError(w, http.StatusBadRequest, scimInvalidValue, "userName is required")
+		return
+	}
+
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	active := input.Active == nil || *input.Active
+	created, err := r.u.CreateUser(req.Context(), entity.User{UserID: input.UserName, Username: input.username(), IsActive: active})
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", userLocation(created.UserID))
+	respond(w, http.StatusCreated, toUser(created, nil))
+}
+
+// replace handles PUT: the user takes the name and activity of the request.
+func (r *userRoutes) replace(w http.ResponseWriter, req *http.Request) {
+	userID := req.PathValue("id")
+
+	var input user
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+	if input.UserName != "" && input.UserName != userID {
+		respondError(w, http.StatusBadRequest, scimMutability, "userName cannot be changed")
+		return
+	}
+
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	input.UserName = userID
+	username := input.username()
+	r.update(req.Context(), w, userID, &username, input.Active)
+}
+
+// patch handles PATCH. Attributes the service does not store, such as emails,
+// are ignored so that providers can keep sending their full mapping.
+func (r *userRoutes) patch(w http.ResponseWriter, req *http.Request) {
+	var input patchRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, scimInvalidSyntax, "invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	var username *string
+	var active *bool
+	for _, op := range input.Operations {
+		switch op.op() {
+		case "add", "replace":
+		case "remove":
+			continue
+		default:
+			respondError(w, http.StatusBadRequest, scimInvalidSyntax, "unsupported patch operation "+op.Op)
+			return
+		}
+
+		values := map[string]json.RawMessage{strings.ToLower(op.Path): op.Value}
+		if op.Path == "" {
+			// No path: the value is an object of attributes
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				respondError(w, http.StatusBadRequest, scimInvalidValue, "value must be an object when path is omitted")
+				return
+			}
+			values = make(map[string]json.RawMessage, len(attrs))
+			for k, v := range attrs {
+				values[strings.ToLower(k)] = v
+			}
+		}
+
+		for path, value := range values {
+			switch path {
+			case "active":
+				b, err := boolValue(value)
+				if err != nil {
+					respondError(w, http.StatusBadRequest, scimInvalidValue, "active: "+err.Error())
+					return
+				}
+				active = &b
+			case "displayname", "name.formatted":
+				var s string
+				if err := json.Unmarshal(value, &s); err != nil || s == "" {
+					respondError(w, http.StatusBadRequest, scimInvalidValue, path+" must be a non-empty string")
+					return
+				}
+				username = &s
+			case "username":
+				var s string
+				if err := json.Unmarshal(value, &s); err != nil || s != req.PathValue("id") {
+					respondError(w, http.StatusBadRequest, scimMutability, "userName cannot be changed")
+					return
+				}
+			}
+		}
+	}
+
+	r.update(req.Context(), w, req.PathValue("id"), username, active)
+}
+
+// update applies a new username and activity flag, each when set, and responds
+// with the resulting user. Both are changed in one transaction. Deactivation
+// reassigns the user's OPEN reviews.
+func (r *userRoutes) update(ctx context.Context, w http.ResponseWriter, userID string, username *string, active *bool) {
+	if _, err := r.load(ctx, userID); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, _, err := r.u.UpdateUser(ctx, userID, username, active); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	resource, err := r.load(ctx, userID)
+	if err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, resource)
+}
+
+// delete archives the user: SCIM deletion must not lose the user's PR history.
+func (r *userRoutes) delete(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, err := r.load(req.Context(), req.PathValue("id")); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	if _, _, err := r.u.ArchiveUser(req.Context(), req.PathValue("id")); err != nil {
+		respondUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load returns the user as a SCIM resource. Archived users were deleted
+// through SCIM or the API and are reported as not found.
+func (r *userRoutes) load(ctx context.Context, userID string) (user, error) {
+	profile, err := r.u.GetUser(ctx, userID)
+	if err != nil {
+		return user{}, err
+	}
+	if profile.IsArchived() {
+		return user{}, entity.ErrNotFound
+	}
+	return toUser(profile.User, profile.Memberships), nil
+}
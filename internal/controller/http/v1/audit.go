@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase"
+	"strconv"
+	"time"
+)
+
+type auditRoutes struct {
+	audit *usecase.AuditUseCase
+	p     *policy.Policy
+}
+
+func newAuditRoutes(mux *http.ServeMux, audit *usecase.AuditUseCase, p *policy.Policy) {
+	r := &auditRoutes{audit, p}
+
+	mux.HandleFunc("GET /audit", r.list)
+}
+
+func (r *auditRoutes) list(w http.ResponseWriter, req *http.Request) {
+	if err := r.p.AuthorizeReadAudit(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	filter, err := parseAuditFilter(req.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	page, err := r.audit.ListEntries(req.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+func parseAuditFilter(query url.Values) (entity.AuditFilter, error) {
+	filter := entity.AuditFilter{
+		ActorID:    query.Get("actor_id"),
+		Action:     entity.AuditAction(query.Get("action")),
+		EntityType: entity.AuditEntityType(query.Get("entity_type")),
+		EntityID:   query.Get("entity_id"),
+	}
+
+	times := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	}
+	for _, p := range times {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return entity.AuditFilter{}, fmt.Errorf("%s must be an RFC 3339 timestamp", p.name)
+		}
+		*p.dst = &t
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	}
+	for _, p := range ints {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return entity.AuditFilter{}, fmt.Errorf("%s must be a non-negative integer", p.name)
+		}
+		*p.dst = n
+	}
+
+	return filter, nil
+}
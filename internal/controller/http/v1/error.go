@@ -2,7 +2,9 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"pr-reviewer-service/internal/entity"
 )
 
 type errorResponse struct {
@@ -28,3 +30,15 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 func respondError(w http.ResponseWriter, status int, code, message string) {
 	respondJSON(w, status, newErrorResponse(code, message))
 }
+
+// respondAuthorizeError reports a failed permission check.
+func respondAuthorizeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrForbidden):
+		respondError(w, http.StatusForbidden, "FORBIDDEN", "action is not allowed for the user")
+	case errors.Is(err, entity.ErrNotFound):
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+	default:
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"net/http"
+	"pr-reviewer-service/pkg/health"
+)
+
+type healthRoutes struct {
+	checker *health.Checker
+}
+
+func newHealthRoutes(mux *http.ServeMux, checker *health.Checker) {
+	r := &healthRoutes{checker}
+
+	mux.HandleFunc("GET /livez", r.live)
+	mux.HandleFunc("GET /readyz", r.ready)
+}
+
+// live reports that the process is serving requests; dependencies are not checked.
+func (r *healthRoutes) live(w http.ResponseWriter, req *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]health.Status{"status": health.StatusOK})
+}
+
+// ready runs the dependency checks and fails while the server shuts down.
+func (r *healthRoutes) ready(w http.ResponseWriter, req *http.Request) {
+	report := r.checker.Run(req.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	respondJSON(w, status, report)
+}
@@ -3,21 +3,32 @@ package v1
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
 	"pr-reviewer-service/internal/usecase"
+	"time"
 )
 
 type pullRequestRoutes struct {
-	pr *usecase.PullRequestUseCase
+	pr    *usecase.PullRequestUseCase
+	stale *usecase.StaleUseCase
+	p     *policy.Policy
 }
 
-func newPullRequestRoutes(mux *http.ServeMux, pr *usecase.PullRequestUseCase) {
-	r := &pullRequestRoutes{pr}
+func newPullRequestRoutes(mux *http.ServeMux, pr *usecase.PullRequestUseCase, stale *usecase.StaleUseCase, p *policy.Policy) {
+	r := &pullRequestRoutes{pr, stale, p}
 
 	mux.HandleFunc("POST /pullRequest/create", r.create)
 	mux.HandleFunc("POST /pullRequest/merge", r.merge)
 	mux.HandleFunc("POST /pullRequest/reassign", r.reassign)
+	mux.HandleFunc("POST /pullRequest/addReviewer", r.addReviewer)
+	mux.HandleFunc("POST /pullRequest/removeReviewer", r.removeReviewer)
+	mux.HandleFunc("POST /pullRequest/review", r.review)
+	mux.HandleFunc("GET /pullRequest/timeline", r.getTimeline)
+	mux.HandleFunc("GET /pullRequest/stale", r.getStale)
+	mux.HandleFunc("POST /pullRequest/stale/process", r.processStale)
 }
 
 type createPRRequest struct {
@@ -63,6 +74,10 @@ func (r *pullRequestRoutes) merge(w http.ResponseWriter, req *http.Request) {
 
 	pr, err := r.pr.MergePR(req.Context(), input.PullRequestID)
 	if err != nil {
+		if errors.Is(err, entity.ErrPRClosed) {
+			respondError(w, http.StatusConflict, "PR_CLOSED", "cannot merge closed PR")
+			return
+		}
 		if errors.Is(err, entity.ErrNotFound) {
 			respondError(w, http.StatusNotFound, "NOT_FOUND", "pull request not found")
 			return
@@ -77,6 +92,8 @@ func (r *pullRequestRoutes) merge(w http.ResponseWriter, req *http.Request) {
 type reassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	// NewUserID picks the replacement explicitly instead of a random teammate
+	NewUserID string `json:"new_user_id,omitempty"`
 }
 
 func (r *pullRequestRoutes) reassign(w http.ResponseWriter, req *http.Request) {
@@ -86,12 +103,35 @@ func (r *pullRequestRoutes) reassign(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := r.p.AuthorizeReassign(req.Context(), input.PullRequestID); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	if input.NewUserID != "" {
+		pr, err := r.pr.ReassignReviewerTo(req.Context(), input.PullRequestID, input.OldUserID, input.NewUserID)
+		if err != nil {
+			respondReviewerError(w, err)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"pr":          pr,
+			"replaced_by": input.NewUserID,
+		})
+		return
+	}
+
 	pr, newReviewerID, err := r.pr.ReassignReviewer(req.Context(), input.PullRequestID, input.OldUserID)
 	if err != nil {
 		if errors.Is(err, entity.ErrPRAlreadyMerged) {
 			respondError(w, http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR")
 			return
 		}
+		if errors.Is(err, entity.ErrPRClosed) {
+			respondError(w, http.StatusConflict, "PR_CLOSED", "cannot reassign on closed PR")
+			return
+		}
 		if errors.Is(err, entity.ErrReviewerNotAssigned) {
 			respondError(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 			return
@@ -113,3 +153,180 @@ func (r *pullRequestRoutes) reassign(w http.ResponseWriter, req *http.Request) {
 		"replaced_by": newReviewerID,
 	})
 }
+
+type reviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+func (r *pullRequestRoutes) addReviewer(w http.ResponseWriter, req *http.Request) {
+	var input reviewerRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeReassign(req.Context(), input.PullRequestID); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	pr, err := r.pr.AddReviewer(req.Context(), input.PullRequestID, input.UserID)
+	if err != nil {
+		respondReviewerError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (r *pullRequestRoutes) removeReviewer(w http.ResponseWriter, req *http.Request) {
+	var input reviewerRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeReassign(req.Context(), input.PullRequestID); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	pr, err := r.pr.RemoveReviewer(req.Context(), input.PullRequestID, input.UserID)
+	if err != nil {
+		respondReviewerError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+type reviewRequest struct {
+	PullRequestID string         `json:"pull_request_id"`
+	UserID        string         `json:"user_id"`
+	Verdict       entity.Verdict `json:"verdict"`
+}
+
+func (r *pullRequestRoutes) review(w http.ResponseWriter, req *http.Request) {
+	var input reviewRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if !input.Verdict.IsValid() {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", entity.ErrInvalidVerdict.Error())
+		return
+	}
+
+	if err := r.p.AuthorizeVerdict(req.Context(), input.UserID); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	pr, err := r.pr.SubmitVerdict(req.Context(), input.PullRequestID, input.UserID, input.Verdict)
+	if err != nil {
+		respondReviewerError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// respondReviewerError maps errors of the manual reviewer operations.
+func respondReviewerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrPRAlreadyMerged):
+		respondError(w, http.StatusConflict, "PR_MERGED", "cannot change reviewers on merged PR")
+	case errors.Is(err, entity.ErrPRClosed):
+		respondError(w, http.StatusConflict, "PR_CLOSED", "cannot change reviewers on closed PR")
+	case errors.Is(err, entity.ErrReviewerNotAssigned):
+		respondError(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+	case errors.Is(err, entity.ErrAlreadyAssigned):
+		respondError(w, http.StatusConflict, "ALREADY_ASSIGNED", "reviewer is already assigned to this PR")
+	case errors.Is(err, entity.ErrReviewerIsAuthor):
+		respondError(w, http.StatusConflict, "REVIEWER_IS_AUTHOR", "author cannot review own PR")
+	case errors.Is(err, entity.ErrUserInactive):
+		respondError(w, http.StatusConflict, "USER_INACTIVE", "reviewer is not active")
+	case errors.Is(err, entity.ErrCrossTeamNotAllowed):
+		respondError(w, http.StatusConflict, "CROSS_TEAM_NOT_ALLOWED", "reviewer must be from the author's team")
+	case errors.Is(err, entity.ErrMaxReviewers):
+		respondError(w, http.StatusConflict, "MAX_REVIEWERS", "team reviewer limit reached")
+	case errors.Is(err, entity.ErrNotFound):
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "pull request or user not found")
+	default:
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
+
+func (r *pullRequestRoutes) getTimeline(w http.ResponseWriter, req *http.Request) {
+	prID := req.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	events, err := r.pr.GetTimeline(req.Context(), prID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+func (r *pullRequestRoutes) getStale(w http.ResponseWriter, req *http.Request) {
+	var threshold time.Duration
+	if olderThan := req.URL.Query().Get("older_than"); olderThan != "" {
+		d, err := time.ParseDuration(olderThan)
+		if err != nil || d <= 0 {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "older_than must be a positive duration, e.g. 720h")
+			return
+		}
+		threshold = d
+	}
+
+	report, err := r.stale.GetStalePRs(req.Context(), threshold)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+type processStaleRequest struct {
+	DryRun *bool `json:"dry_run"`
+}
+
+func (r *pullRequestRoutes) processStale(w http.ResponseWriter, req *http.Request) {
+	var input processStaleRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// Stale PRs of every team are closed or unassigned
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	// Dry run unless explicitly disabled
+	dryRun := input.DryRun == nil || *input.DryRun
+
+	report, err := r.stale.ProcessStalePRs(req.Context(), dryRun)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
@@ -2,25 +2,73 @@ package v1
 
 import (
 	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
 	"pr-reviewer-service/internal/usecase"
+	"pr-reviewer-service/pkg/health"
+	"strings"
 )
 
+// publicRoutes need no API token.
+var publicRoutes = map[string]bool{
+	"/health": true,
+	"/livez":  true,
+	"/readyz": true,
+}
+
+// adminRoutes need the admin scope: token management and irreversible or
+// organisation-wide changes.
+var adminRoutes = map[string]bool{
+	"/team/deactivate":           true,
+	"/team/archive":              true,
+	"/team/sync":                 true,
+	"/users/archive":             true,
+	"/users/erase":               true,
+	"/audit":                     true,
+	"/pullRequest/stale/process": true,
+}
+
+// RequiredScope returns the API token scope a request needs. Reads need read,
+// other methods need write, unless the route is public or admin-only.
+func RequiredScope(r *http.Request) entity.Scope {
+	switch {
+	case publicRoutes[r.URL.Path]:
+		return ""
+	case adminRoutes[r.URL.Path], strings.HasPrefix(r.URL.Path, "/auth/"), strings.HasPrefix(r.URL.Path, "/scim/"):
+		return entity.ScopeAdmin
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return entity.ScopeRead
+	default:
+		return entity.ScopeWrite
+	}
+}
+
 func NewRouter(
 	mux *http.ServeMux,
 	t *usecase.TeamUseCase,
+	sync *usecase.TeamSyncUseCase,
 	u *usecase.UserUseCase,
 	pr *usecase.PullRequestUseCase,
+	stale *usecase.StaleUseCase,
 	stats *usecase.StatsUseCase,
+	tokens *usecase.TokenUseCase,
+	audit *usecase.AuditUseCase,
+	p *policy.Policy,
+	checker *health.Checker,
 ) {
-	// Health check
+	// Health check, kept for compatibility: /livez and /readyz are the probes
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
 	// Register routes
-	newTeamRoutes(mux, t)
-	newUserRoutes(mux, u)
-	newPullRequestRoutes(mux, pr)
+	newTeamRoutes(mux, t, p)
+	newTeamSyncRoutes(mux, sync, p)
+	newUserRoutes(mux, u, p)
+	newPullRequestRoutes(mux, pr, stale, p)
 	newStatsRoutes(mux, stats)
+	newTokenRoutes(mux, tokens)
+	newAuditRoutes(mux, audit, p)
+	newHealthRoutes(mux, checker)
 }
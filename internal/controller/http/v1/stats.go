@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/internal/usecase"
 )
 
@@ -14,6 +16,7 @@ func newStatsRoutes(mux *http.ServeMux, stats *usecase.StatsUseCase) {
 
 	mux.HandleFunc("GET /stats/users", r.getUserStats)
 	mux.HandleFunc("GET /stats/prs", r.getPRStats)
+	mux.HandleFunc("GET /stats/teams", r.getTeamStats)
 }
 
 func (r *statsRoutes) getUserStats(w http.ResponseWriter, req *http.Request) {
@@ -37,3 +40,19 @@ func (r *statsRoutes) getPRStats(w http.ResponseWriter, req *http.Request) {
 
 	respondJSON(w, http.StatusOK, stats)
 }
+
+func (r *statsRoutes) getTeamStats(w http.ResponseWriter, req *http.Request) {
+	stats, err := r.stats.GetTeamStats(req.Context(), req.URL.Query().Get("root"))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"teams": stats,
+	})
+}
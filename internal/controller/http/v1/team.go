@@ -5,34 +5,51 @@ import (
 	"errors"
 	"net/http"
 	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
 	"pr-reviewer-service/internal/usecase"
 )
 
 type teamRoutes struct {
 	t *usecase.TeamUseCase
+	p *policy.Policy
 }
 
-func newTeamRoutes(mux *http.ServeMux, t *usecase.TeamUseCase) {
-	r := &teamRoutes{t}
+func newTeamRoutes(mux *http.ServeMux, t *usecase.TeamUseCase, p *policy.Policy) {
+	r := &teamRoutes{t, p}
 
 	mux.HandleFunc("POST /team/add", r.create)
 	mux.HandleFunc("GET /team/get", r.get)
 	mux.HandleFunc("POST /team/deactivate", r.deactivate)
+	mux.HandleFunc("POST /team/archive", r.archive)
+	mux.HandleFunc("POST /team/updateSettings", r.updateSettings)
+	mux.HandleFunc("POST /team/addMembers", r.addMembers)
+	mux.HandleFunc("POST /team/removeMember", r.removeMember)
+	mux.HandleFunc("POST /team/moveMember", r.moveMember)
+	mux.HandleFunc("POST /team/updateMember", r.updateMember)
+	mux.HandleFunc("POST /team/setParent", r.setParent)
+	mux.HandleFunc("GET /team/tree", r.tree)
+}
+
+type teamMemberRequest struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
 }
 
 type createTeamRequest struct {
-	TeamName string `json:"team_name"`
-	Members  []struct {
-		UserID   string `json:"user_id"`
-		Username string `json:"username"`
-		IsActive bool   `json:"is_active"`
-	} `json:"members"`
+	TeamName string              `json:"team_name"`
+	Members  []teamMemberRequest `json:"members"`
 }
 
 type deactivateTeamRequest struct {
 	TeamName string `json:"team_name"`
 }
 
+type setParentRequest struct {
+	TeamName   string `json:"team_name"`
+	ParentTeam string `json:"parent_team"`
+}
+
 func (r *teamRoutes) create(w http.ResponseWriter, req *http.Request) {
 	var input createTeamRequest
 	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
@@ -40,16 +57,12 @@ func (r *teamRoutes) create(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	members := make([]entity.User, len(input.Members))
-	for i, m := range input.Members {
-		members[i] = entity.User{
-			UserID:   m.UserID,
-			Username: m.Username,
-			IsActive: m.IsActive,
-		}
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
 	}
 
-	team, err := r.t.CreateTeam(req.Context(), input.TeamName, members)
+	team, err := r.t.CreateTeam(req.Context(), input.TeamName, toMembers(input.Members))
 	if err != nil {
 		if errors.Is(err, entity.ErrTeamAlreadyExists) {
 			respondError(w, http.StatusBadRequest, "TEAM_EXISTS", "team_name already exists")
@@ -89,8 +102,314 @@ func (r *teamRoutes) deactivate(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	reassigned, err := r.t.DeactivateTeamAndReassign(req.Context(), input.TeamName)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Team deactivated",
+		"team_name":      input.TeamName,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (r *teamRoutes) archive(w http.ResponseWriter, req *http.Request) {
+	var input deactivateTeamRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	team, reassigned, err := r.t.ArchiveTeam(req.Context(), input.TeamName)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"team":           team,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (r *teamRoutes) setParent(w http.ResponseWriter, req *http.Request) {
+	var input setParentRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	team, err := r.t.SetParent(req.Context(), input.TeamName, input.ParentTeam)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrNotFound):
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+		case errors.Is(err, entity.ErrTeamCycle):
+			respondError(w, http.StatusConflict, "TEAM_CYCLE", "team cannot be nested under itself or its sub-team")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (r *teamRoutes) tree(w http.ResponseWriter, req *http.Request) {
+	tree, err := r.t.GetTree(req.Context(), req.URL.Query().Get("root"))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"teams": tree})
+}
+
+func toMembers(input []teamMemberRequest) []entity.User {
+	members := make([]entity.User, len(input))
+	for i, m := range input {
+		members[i] = entity.User{
+			UserID:   m.UserID,
+			Username: m.Username,
+			IsActive: m.IsActive,
+		}
+	}
+	return members
+}
+
+type updateTeamSettingsRequest struct {
+	TeamName       string    `json:"team_name"`
+	MaxReviewers   *int      `json:"max_reviewers"`
+	AllowCrossTeam *bool     `json:"allow_cross_team"`
+	FallbackTeams  *[]string `json:"fallback_teams"`
+}
+
+func (r *teamRoutes) updateSettings(w http.ResponseWriter, req *http.Request) {
+	var input updateTeamSettingsRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeTeam(req.Context(), input.TeamName, entity.PermSettings); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	team, err := r.t.UpdateSettings(req.Context(), input.TeamName, entity.TeamSettingsPatch{
+		MaxReviewers:   input.MaxReviewers,
+		AllowCrossTeam: input.AllowCrossTeam,
+		FallbackTeams:  input.FallbackTeams,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidSettings) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST",
+				"max_reviewers must not be negative, fallback_teams must be unique and not include the team itself")
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team or fallback team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (r *teamRoutes) addMembers(w http.ResponseWriter, req *http.Request) {
+	var input createTeamRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeTeam(req.Context(), input.TeamName, entity.PermMembers); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	team, err := r.t.AddMembers(req.Context(), input.TeamName, toMembers(input.Members))
+	if err != nil {
+		if errors.Is(err, entity.ErrTeamArchived) {
+			respondError(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+			return
+		}
+		if errors.Is(err, entity.ErrUserArchived) {
+			respondError(w, http.StatusConflict, "USER_ARCHIVED", "user is archived")
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+type removeMemberRequest struct {
+	TeamName        string `json:"team_name"`
+	UserID          string `json:"user_id"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+func (r *teamRoutes) removeMember(w http.ResponseWriter, req *http.Request) {
+	var input removeMemberRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeTeam(req.Context(), input.TeamName, entity.PermMembers); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	reassigned, err := r.t.RemoveMember(req.Context(), input.TeamName, input.UserID, input.ReassignReviews)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotTeamMember) {
+			respondError(w, http.StatusConflict, "NOT_TEAM_MEMBER", "user is not a member of the team")
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"team_name":      input.TeamName,
+		"user_id":        input.UserID,
+		"reassigned_prs": reassigned,
+	})
+}
+
+type moveMemberRequest struct {
+	UserID string `json:"user_id"`
+	// FromTeam defaults to the user's primary team
+	FromTeam        string `json:"from_team"`
+	ToTeam          string `json:"to_team"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+func (r *teamRoutes) moveMember(w http.ResponseWriter, req *http.Request) {
+	var input moveMemberRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// The source team is resolved here so that the team authorized is the team moved from
+	fromTeam, err := r.p.AuthorizeMoveMember(req.Context(), input.UserID, input.FromTeam, input.ToTeam)
+	if err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	user, reassigned, err := r.t.MoveMember(req.Context(), input.UserID, fromTeam, input.ToTeam, input.ReassignReviews)
+	if err != nil {
+		if errors.Is(err, entity.ErrTeamArchived) {
+			respondError(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+			return
+		}
+		if errors.Is(err, entity.ErrNotTeamMember) {
+			respondError(w, http.StatusConflict, "NOT_TEAM_MEMBER", "user is not a member of from_team")
+			return
+		}
+		if errors.Is(err, entity.ErrAlreadyTeamMember) {
+			respondError(w, http.StatusConflict, "ALREADY_TEAM_MEMBER", "user is already a member of to_team")
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user or team not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":           user,
+		"reassigned_prs": reassigned,
+	})
+}
+
+type updateMemberRequest struct {
+	TeamName     string           `json:"team_name"`
+	UserID       string           `json:"user_id"`
+	IsActive     *bool            `json:"is_active"`
+	ReviewWeight *int             `json:"review_weight"`
+	Role         *entity.TeamRole `json:"role"`
+}
+
+func (r *teamRoutes) updateMember(w http.ResponseWriter, req *http.Request) {
+	var input updateMemberRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeTeam(req.Context(), input.TeamName, entity.PermMembers); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	membership, err := r.t.UpdateMember(req.Context(), input.TeamName, input.UserID, entity.MembershipPatch{
+		IsActive:     input.IsActive,
+		ReviewWeight: input.ReviewWeight,
+		Role:         input.Role,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidSettings) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST",
+				"review_weight must not be negative, role must be member, lead or maintainer")
+			return
+		}
+		if errors.Is(err, entity.ErrNotTeamMember) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user is not a member of the team")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"message":   "Team deactivated",
-		"team_name": input.TeamName,
+		"user_id":    input.UserID,
+		"membership": membership,
 	})
 }
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type teamSyncRoutes struct {
+	s *usecase.TeamSyncUseCase
+	p *policy.Policy
+}
+
+func newTeamSyncRoutes(mux *http.ServeMux, s *usecase.TeamSyncUseCase, p *policy.Policy) {
+	r := &teamSyncRoutes{s, p}
+
+	mux.HandleFunc("POST /team/sync", r.sync)
+}
+
+// sync takes a YAML or JSON TeamConfig. dry_run=true only returns the plan,
+// prune=true archives teams missing from the config.
+func (r *teamSyncRoutes) sync(w http.ResponseWriter, req *http.Request) {
+	dryRun, err := boolParam(req, "dry_run")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	prune, err := boolParam(req, "prune")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	var cfg entity.TeamConfig
+	if err := yaml.NewDecoder(req.Body).Decode(&cfg); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid team config: "+err.Error())
+		return
+	}
+
+	if err := r.p.AuthorizeManageTeams(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	result, err := r.s.Sync(req.Context(), cfg, dryRun, prune)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidTeamConfig), errors.Is(err, entity.ErrInvalidSettings):
+			respondError(w, http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+		case errors.Is(err, entity.ErrNotFound):
+			respondError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+		case errors.Is(err, entity.ErrTeamArchived), errors.Is(err, entity.ErrUserArchived):
+			respondError(w, http.StatusConflict, "ARCHIVED", err.Error())
+		case errors.Is(err, entity.ErrTeamCycle):
+			respondError(w, http.StatusConflict, "TEAM_CYCLE", err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// boolParam reads an optional boolean query parameter.
+func boolParam(req *http.Request, name string) (bool, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New(name + " must be a boolean")
+	}
+	return b, nil
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+	"time"
+)
+
+type tokenRoutes struct {
+	t *usecase.TokenUseCase
+}
+
+func newTokenRoutes(mux *http.ServeMux, t *usecase.TokenUseCase) {
+	r := &tokenRoutes{t}
+
+	mux.HandleFunc("POST /auth/tokens/create", r.create)
+	mux.HandleFunc("GET /auth/tokens/list", r.list)
+	mux.HandleFunc("POST /auth/tokens/revoke", r.revoke)
+}
+
+type createTokenRequest struct {
+	Name   string         `json:"name"`
+	Scopes []entity.Scope `json:"scopes"`
+	UserID string         `json:"user_id"`
+	// ExpiresIn is a Go duration such as "720h"; empty means the token never expires
+	ExpiresIn string `json:"expires_in"`
+}
+
+type revokeTokenRequest struct {
+	TokenID int64 `json:"token_id"`
+}
+
+func (r *tokenRoutes) create(w http.ResponseWriter, req *http.Request) {
+	var input createTokenRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	var ttl time.Duration
+	if input.ExpiresIn != "" {
+		d, err := time.ParseDuration(input.ExpiresIn)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "expires_in must be a duration like 720h")
+			return
+		}
+		ttl = d
+	}
+
+	token, raw, err := r.t.CreateToken(req.Context(), input.Name, input.Scopes, input.UserID, ttl)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidToken) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST",
+				"name is required, scopes must be read, write or admin and expires_in must not be negative")
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"token":     raw,
+		"api_token": token,
+	})
+}
+
+func (r *tokenRoutes) list(w http.ResponseWriter, req *http.Request) {
+	tokens, err := r.t.ListTokens(req.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"tokens": tokens})
+}
+
+func (r *tokenRoutes) revoke(w http.ResponseWriter, req *http.Request) {
+	var input revokeTokenRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.t.RevokeToken(req.Context(), input.TokenID); err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "token not found or already revoked")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"token_id": input.TokenID,
+		"revoked":  true,
+	})
+}
@@ -3,20 +3,30 @@ package v1
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
 	"pr-reviewer-service/internal/usecase"
+	"strconv"
 )
 
 type userRoutes struct {
 	u *usecase.UserUseCase
+	p *policy.Policy
 }
 
-func newUserRoutes(mux *http.ServeMux, u *usecase.UserUseCase) {
-	r := &userRoutes{u}
+func newUserRoutes(mux *http.ServeMux, u *usecase.UserUseCase, p *policy.Policy) {
+	r := &userRoutes{u, p}
 
 	mux.HandleFunc("POST /users/setIsActive", r.setIsActive)
 	mux.HandleFunc("GET /users/getReview", r.getReviews)
+	mux.HandleFunc("POST /users/archive", r.archive)
+	mux.HandleFunc("POST /users/erase", r.erase)
+	mux.HandleFunc("GET /users/get", r.get)
+	mux.HandleFunc("GET /users/list", r.list)
+	mux.HandleFunc("GET /users/search", r.search)
 }
 
 type setIsActiveRequest struct {
@@ -31,8 +41,17 @@ func (r *userRoutes) setIsActive(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := r.p.AuthorizeSetIsActive(req.Context(), input.UserID); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
 	user, err := r.u.SetIsActive(req.Context(), input.UserID, input.IsActive)
 	if err != nil {
+		if errors.Is(err, entity.ErrUserArchived) {
+			respondError(w, http.StatusConflict, "USER_ARCHIVED", "user is archived")
+			return
+		}
 		if errors.Is(err, entity.ErrNotFound) {
 			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
 			return
@@ -62,3 +81,164 @@ func (r *userRoutes) getReviews(w http.ResponseWriter, req *http.Request) {
 		"pull_requests": prs,
 	})
 }
+
+type userIDRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (r *userRoutes) archive(w http.ResponseWriter, req *http.Request) {
+	var input userIDRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	user, reassigned, err := r.u.ArchiveUser(req.Context(), input.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":           user,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (r *userRoutes) erase(w http.ResponseWriter, req *http.Request) {
+	var input userIDRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := r.p.AuthorizeManageUsers(req.Context()); err != nil {
+		respondAuthorizeError(w, err)
+		return
+	}
+
+	anonymousID, reassigned, err := r.u.EraseUser(req.Context(), input.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"erased_user_id": anonymousID,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (r *userRoutes) get(w http.ResponseWriter, req *http.Request) {
+	userID := req.URL.Query().Get("user_id")
+	if userID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	profile, err := r.u.GetUser(req.Context(), userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"user": profile})
+}
+
+func (r *userRoutes) list(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseUserFilter(req.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	r.respondUserPage(w, req, filter)
+}
+
+func (r *userRoutes) search(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseUserFilter(req.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if filter.Query == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "q is required")
+		return
+	}
+
+	r.respondUserPage(w, req, filter)
+}
+
+func (r *userRoutes) respondUserPage(w http.ResponseWriter, req *http.Request, filter entity.UserFilter) {
+	page, err := r.u.ListUsers(req.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+// parseUserFilter reads the user directory filters from the query string:
+// team_name, is_active, max_open_reviews, q, include_archived, limit and offset.
+func parseUserFilter(query url.Values) (entity.UserFilter, error) {
+	filter := entity.UserFilter{
+		TeamName: query.Get("team_name"),
+		Query:    query.Get("q"),
+	}
+
+	if v := query.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			return entity.UserFilter{}, errors.New("is_active must be a boolean")
+		}
+		filter.IsActive = &isActive
+	}
+
+	if v := query.Get("include_archived"); v != "" {
+		includeArchived, err := strconv.ParseBool(v)
+		if err != nil {
+			return entity.UserFilter{}, errors.New("include_archived must be a boolean")
+		}
+		filter.IncludeArchived = includeArchived
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"max_open_reviews", &filter.MaxOpenReviews},
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	}
+	for _, p := range ints {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return entity.UserFilter{}, fmt.Errorf("%s must be a non-negative integer", p.name)
+		}
+		*p.dst = n
+	}
+
+	return filter, nil
+}
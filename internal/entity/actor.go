@@ -0,0 +1,43 @@
+package entity
+
+import "context"
+
+type (
+	actorKey     struct{}
+	tokenKey     struct{}
+	requestIDKey struct{}
+)
+
+// WithActor returns a copy of ctx carrying the id of the user performing the request.
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the id of the user performing the request, or an
+// empty string for requests made by the service itself or a trusted client.
+func ActorFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(actorKey{}).(string)
+	return userID
+}
+
+// WithAPIToken returns a copy of ctx carrying the API token the request was authenticated with.
+func WithAPIToken(ctx context.Context, token APIToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// APITokenFromContext returns the API token the request was authenticated with.
+func APITokenFromContext(ctx context.Context) (APIToken, bool) {
+	token, ok := ctx.Value(tokenKey{}).(APIToken)
+	return token, ok
+}
+
+// WithRequestID returns a copy of ctx carrying the id that correlates the request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id, or an empty string outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
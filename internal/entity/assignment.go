@@ -0,0 +1,101 @@
+package entity
+
+import "time"
+
+type AssignmentEventType string
+
+const (
+	EventAssigned   AssignmentEventType = "assigned"
+	EventUnassigned AssignmentEventType = "unassigned"
+	EventReassigned AssignmentEventType = "reassigned"
+)
+
+type AssignmentReason string
+
+const (
+	ReasonAuto         AssignmentReason = "auto"
+	ReasonManual       AssignmentReason = "manual"
+	ReasonDeactivation AssignmentReason = "deactivation"
+	ReasonDecline      AssignmentReason = "decline"
+	ReasonStale        AssignmentReason = "stale"
+	ReasonMembership   AssignmentReason = "membership"
+	ReasonArchival     AssignmentReason = "archival"
+	ReasonErasure      AssignmentReason = "erasure"
+)
+
+// AssignmentMeta describes who changed a PR's reviewers and why.
+// An empty ActorID means the change was made by the service itself.
+type AssignmentMeta struct {
+	ActorID string
+	Reason  AssignmentReason
+}
+
+type AssignmentEvent struct {
+	EventID            int64               `json:"event_id"`
+	PullRequestID      string              `json:"pull_request_id"`
+	EventType          AssignmentEventType `json:"event_type"`
+	ReviewerID         string              `json:"reviewer_id"`
+	PreviousReviewerID string              `json:"previous_reviewer_id,omitempty"`
+	SourceTeam         string              `json:"source_team,omitempty"`
+	ActorID            string              `json:"actor_id,omitempty"`
+	Reason             AssignmentReason    `json:"reason"`
+	CreatedAt          time.Time           `json:"created_at"`
+}
+
+// DiffReviewers compares the previously assigned reviewers with the ones now on pr
+// and returns the reviewers to add, the reviewers to remove and the timeline events
+// describing the change. Removed and added reviewers are paired in order into
+// "reassigned" events, the rest become plain "assigned"/"unassigned" events.
+func DiffReviewers(before []string, pr PullRequest, meta AssignmentMeta) (added, removed []string, events []AssignmentEvent) {
+	after := pr.AssignedReviewers
+
+	beforeSet := make(map[string]bool, len(before))
+	for _, id := range before {
+		beforeSet[id] = true
+	}
+
+	afterSet := make(map[string]bool, len(after))
+	for _, id := range after {
+		afterSet[id] = true
+	}
+
+	for _, id := range before {
+		if !afterSet[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	for _, id := range after {
+		if !beforeSet[id] {
+			added = append(added, id)
+		}
+	}
+
+	newEvent := func(eventType AssignmentEventType, reviewerID, previousID string) AssignmentEvent {
+		e := AssignmentEvent{
+			PullRequestID:      pr.PullRequestID,
+			EventType:          eventType,
+			ReviewerID:         reviewerID,
+			PreviousReviewerID: previousID,
+			ActorID:            meta.ActorID,
+			Reason:             meta.Reason,
+		}
+		if eventType != EventUnassigned {
+			e.SourceTeam = pr.ReviewerTeams[reviewerID]
+		}
+		return e
+	}
+
+	paired := min(len(added), len(removed))
+	for i := 0; i < paired; i++ {
+		events = append(events, newEvent(EventReassigned, added[i], removed[i]))
+	}
+	for _, id := range removed[paired:] {
+		events = append(events, newEvent(EventUnassigned, id, ""))
+	}
+	for _, id := range added[paired:] {
+		events = append(events, newEvent(EventAssigned, id, ""))
+	}
+
+	return added, removed, events
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction names a mutation recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditTeamCreate       AuditAction = "team.create"
+	AuditTeamSettings     AuditAction = "team.update_settings"
+	AuditTeamSetParent    AuditAction = "team.set_parent"
+	AuditTeamAddMembers   AuditAction = "team.add_members"
+	AuditTeamUpdateMember AuditAction = "team.update_member"
+	AuditTeamRemoveMember AuditAction = "team.remove_member"
+	AuditTeamMoveMember   AuditAction = "team.move_member"
+	AuditTeamDeactivate   AuditAction = "team.deactivate"
+	AuditTeamArchive      AuditAction = "team.archive"
+
+	AuditUserCreate      AuditAction = "user.create"
+	AuditUserSetIsActive AuditAction = "user.set_is_active"
+	AuditUserArchive     AuditAction = "user.archive"
+	AuditUserErase       AuditAction = "user.erase"
+	AuditUserRename      AuditAction = "user.rename"
+
+	AuditPRCreate         AuditAction = "pull_request.create"
+	AuditPRMerge          AuditAction = "pull_request.merge"
+	AuditPRReassign       AuditAction = "pull_request.reassign"
+	AuditPRAddReviewer    AuditAction = "pull_request.add_reviewer"
+	AuditPRRemoveReviewer AuditAction = "pull_request.remove_reviewer"
+	AuditPRApprove        AuditAction = "pull_request.approve"
+	AuditPRDecline        AuditAction = "pull_request.decline"
+	AuditPRStale          AuditAction = "pull_request.stale"
+
+	AuditTokenCreate AuditAction = "token.create"
+	AuditTokenRevoke AuditAction = "token.revoke"
+)
+
+// AuditEntityType is the kind of object an audit entry is about.
+type AuditEntityType string
+
+const (
+	AuditEntityTeam        AuditEntityType = "team"
+	AuditEntityUser        AuditEntityType = "user"
+	AuditEntityPullRequest AuditEntityType = "pull_request"
+	AuditEntityToken       AuditEntityType = "token"
+)
+
+// AuditEntry is one record of the append-only audit log. Before and After are
+// JSON snapshots of the entity; either is empty when it does not apply.
+type AuditEntry struct {
+	AuditID int64  `json:"audit_id"`
+	ActorID string `json:"actor_id,omitempty"`
+	// ActorTokenID is the API token the request was made with, so requests of
+	// tokens bound to no user can be told apart
+	ActorTokenID int64           `json:"actor_token_id,omitempty"`
+	Action       AuditAction     `json:"action"`
+	EntityType   AuditEntityType `json:"entity_type"`
+	EntityID     string          `json:"entity_id"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	RequestID    string          `json:"request_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditFilter selects audit entries. Zero values disable a filter.
+type AuditFilter struct {
+	ActorID    string
+	Action     AuditAction
+	EntityType AuditEntityType
+	EntityID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
@@ -0,0 +1,116 @@
+package entity
+
+import "sort"
+
+// ManagedByDirectory marks users and teams created by a directory sync. Only
+// these are changed by later syncs.
+const ManagedByDirectory = "directory"
+
+// Directory is a snapshot of an external user directory such as LDAP. Each
+// group is synced into the team of the same name; such teams are owned by the
+// directory.
+type Directory struct {
+	Users  []DirectoryUser
+	Groups []DirectoryGroup
+}
+
+type DirectoryUser struct {
+	UserID   string
+	Username string
+}
+
+// DirectoryGroup lists the members of a group that are directory users.
+type DirectoryGroup struct {
+	TeamName string
+	UserIDs  []string
+}
+
+// PlanDirectorySync returns the changes that make users and the teams of the
+// directory groups match dir. users holds the stored directory users, members
+// of those teams and users managed by the directory, teams the stored teams of
+// the groups.
+//
+// Only users and teams managed by the directory are reconciled: a group whose
+// team was created in the service is skipped, and users created in the service
+// are added to directory teams but never renamed or deactivated. Users are
+// created and renamed but never reactivated: is_active may be toggled by users
+// themselves. Members of directory teams that are not in the group are removed;
+// managed users that are no longer directory users are deactivated. Archived
+// users and teams are left alone.
+func PlanDirectorySync(dir Directory, users map[string]User, teams map[string]Team) []TeamSyncChange {
+	var userChanges, teamChanges, memberChanges, removals, deactivations []TeamSyncChange
+
+	listed := make(map[string]bool, len(dir.Users))
+	for _, u := range dir.Users {
+		if listed[u.UserID] {
+			continue
+		}
+		listed[u.UserID] = true
+
+		have, exists := users[u.UserID]
+		switch {
+		case !exists:
+			userChanges = append(userChanges, TeamSyncChange{Action: SyncCreateUser, UserID: u.UserID})
+		case have.IsArchived() || have.ManagedBy != ManagedByDirectory:
+		case have.Username != u.Username:
+			userChanges = append(userChanges, TeamSyncChange{
+				Action:  SyncRenameUser,
+				UserID:  u.UserID,
+				Changes: []string{change("username", have.Username, u.Username)},
+			})
+		}
+	}
+
+	for _, g := range dir.Groups {
+		team, exists := teams[g.TeamName]
+		if !exists {
+			teamChanges = append(teamChanges, TeamSyncChange{Action: SyncCreateTeam, TeamName: g.TeamName})
+		} else if team.IsArchived() || team.ManagedBy != ManagedByDirectory {
+			continue
+		}
+
+		wanted := make(map[string]bool, len(g.UserIDs))
+		for _, userID := range g.UserIDs {
+			if !listed[userID] || wanted[userID] {
+				continue
+			}
+			wanted[userID] = true
+			if team.HasMember(userID) {
+				continue
+			}
+			if u, ok := users[userID]; ok && u.IsArchived() {
+				continue
+			}
+			memberChanges = append(memberChanges, TeamSyncChange{Action: SyncAddMember, TeamName: g.TeamName, UserID: userID})
+		}
+
+		var removed []string
+		for _, m := range team.Members {
+			if !wanted[m.UserID] {
+				removed = append(removed, m.UserID)
+			}
+		}
+		sort.Strings(removed)
+		for _, userID := range removed {
+			removals = append(removals, TeamSyncChange{Action: SyncRemoveMember, TeamName: g.TeamName, UserID: userID})
+		}
+	}
+
+	var left []string
+	for userID, u := range users {
+		if !listed[userID] && u.ManagedBy == ManagedByDirectory && u.IsActive && !u.IsArchived() {
+			left = append(left, userID)
+		}
+	}
+	sort.Strings(left)
+	for _, userID := range left {
+		deactivations = append(deactivations, TeamSyncChange{Action: SyncDeactivateUser, UserID: userID})
+	}
+
+	plan := make([]TeamSyncChange, 0, len(userChanges)+len(teamChanges)+len(memberChanges)+len(removals)+len(deactivations))
+	plan = append(plan, userChanges...)
+	plan = append(plan, teamChanges...)
+	plan = append(plan, memberChanges...)
+	plan = append(plan, removals...)
+	return append(plan, deactivations...)
+}
@@ -0,0 +1,69 @@
+package entity_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/entity"
+)
+
+func TestPlanDirectorySync(t *testing.T) {
+	archived := time.Now()
+	const managed = entity.ManagedByDirectory
+	users := map[string]entity.User{
+		"u1": {UserID: "u1", Username: "Alice", IsActive: true, ManagedBy: managed},
+		"u2": {UserID: "u2", Username: "Bob", IsActive: false, ManagedBy: managed},
+		"u3": {UserID: "u3", Username: "Carol", IsActive: true, ManagedBy: managed},
+		"u4": {UserID: "u4", Username: "Dave", IsActive: true, ManagedBy: managed},
+		"u5": {UserID: "u5", Username: "Eve", ArchivedAt: &archived, ManagedBy: managed},
+		// Created in the service
+		"u7": {UserID: "u7", Username: "Grace", IsActive: true},
+		"u8": {UserID: "u8", Username: "Heidi", IsActive: true},
+		// Managed, left the directory and every team
+		"u9": {UserID: "u9", Username: "Ivan", IsActive: true, ManagedBy: managed},
+	}
+	teams := map[string]entity.Team{
+		"backend": {TeamName: "backend", ManagedBy: managed, Members: []entity.User{{UserID: "u1"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u8"}}},
+		"retired": {TeamName: "retired", ManagedBy: managed, ArchivedAt: &archived, Members: []entity.User{{UserID: "u4"}}},
+		// Created in the service under the name of a group
+		"design": {TeamName: "design", Members: []entity.User{{UserID: "u7"}, {UserID: "u8"}}},
+	}
+
+	dir := entity.Directory{
+		Users: []entity.DirectoryUser{
+			{UserID: "u1", Username: "Alice A."},
+			{UserID: "u2", Username: "Bob"},
+			{UserID: "u3", Username: "Carol"},
+			{UserID: "u5", Username: "Eve"},
+			{UserID: "u6", Username: "Frank"},
+			{UserID: "u7", Username: "Grace G."},
+		},
+		Groups: []entity.DirectoryGroup{
+			// u3 left backend, u4 left the directory, u8 was never in it
+			{TeamName: "backend", UserIDs: []string{"u1", "u2", "u5", "u7"}},
+			{TeamName: "platform", UserIDs: []string{"u6", "u6"}},
+			{TeamName: "retired", UserIDs: []string{"u1"}},
+			{TeamName: "design", UserIDs: []string{"u1"}},
+		},
+	}
+
+	got := entity.PlanDirectorySync(dir, users, teams)
+	want := []entity.TeamSyncChange{
+		{Action: entity.SyncRenameUser, UserID: "u1", Changes: []string{"username: Alice -> Alice A."}},
+		{Action: entity.SyncCreateUser, UserID: "u6"},
+		{Action: entity.SyncCreateTeam, TeamName: "platform"},
+		{Action: entity.SyncAddMember, TeamName: "backend", UserID: "u2"},
+		{Action: entity.SyncAddMember, TeamName: "backend", UserID: "u7"},
+		{Action: entity.SyncAddMember, TeamName: "platform", UserID: "u6"},
+		{Action: entity.SyncRemoveMember, TeamName: "backend", UserID: "u3"},
+		{Action: entity.SyncRemoveMember, TeamName: "backend", UserID: "u4"},
+		{Action: entity.SyncRemoveMember, TeamName: "backend", UserID: "u8"},
+		{Action: entity.SyncDeactivateUser, UserID: "u4"},
+		{Action: entity.SyncDeactivateUser, UserID: "u9"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plan =\n%v\nwant\n%v", got, want)
+	}
+}
@@ -4,10 +4,28 @@ import "errors"
 
 var (
 	ErrTeamAlreadyExists   = errors.New("team already exists")
+	ErrUserAlreadyExists   = errors.New("user already exists")
 	ErrPRAlreadyExists     = errors.New("pull request already exists")
 	ErrPRAlreadyMerged     = errors.New("PR is already merged")
+	ErrPRClosed            = errors.New("PR is closed")
 	ErrReviewerNotAssigned = errors.New("reviewer is not assigned to this PR")
 	ErrNoCandidates        = errors.New("no candidate reviewers available")
 	ErrNotFound            = errors.New("not found")
-	ErrMaxReviewers        = errors.New("maximum 2 reviewers allowed")
+	ErrMaxReviewers        = errors.New("maximum reviewers reached for team")
+	ErrAlreadyAssigned     = errors.New("reviewer is already assigned to this PR")
+	ErrReviewerIsAuthor    = errors.New("author cannot review own PR")
+	ErrUserInactive        = errors.New("user is not active")
+	ErrCrossTeamNotAllowed = errors.New("reviewer from another team is not allowed")
+	ErrInvalidSettings     = errors.New("invalid team settings")
+	ErrNotTeamMember       = errors.New("user is not a member of the team")
+	ErrAlreadyTeamMember   = errors.New("user is already a member of the team")
+	ErrTeamCycle           = errors.New("team cannot be nested under itself or its sub-team")
+	ErrTeamArchived        = errors.New("team is archived")
+	ErrUserArchived        = errors.New("user is archived")
+	ErrForbidden           = errors.New("action is not allowed for the user")
+	ErrUnauthorized        = errors.New("missing or invalid API token")
+	ErrInvalidToken        = errors.New("invalid token parameters")
+	ErrInvalidVerdict      = errors.New("verdict must be approve or decline")
+	ErrInvalidTeamConfig   = errors.New("invalid team config")
+	ErrEmptyDirectory      = errors.New("directory returned no users")
 )
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+type NotificationEvent string
+
+const (
+	NotifyAssigned   NotificationEvent = "assigned"
+	NotifyReassigned NotificationEvent = "reassigned"
+	NotifySLABreach  NotificationEvent = "sla_breach"
+	NotifyMerged     NotificationEvent = "merged"
+)
+
+// Notification tells a reviewer or a team about a PR event.
+type Notification struct {
+	Event           NotificationEvent
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	// ReviewerID is the assigned reviewer, unset for merges
+	ReviewerID string
+	// PreviousReviewerID is the reviewer replaced by a reassignment
+	PreviousReviewerID string
+	// Reviewers are the reviewers of a merged PR
+	Reviewers []string
+	// TeamName is the team the reviewer was drawn from or, for merges, the
+	// author's team
+	TeamName string
+	Reason   AssignmentReason
+	// AssignedAt is when the reviewer of an SLA breach was assigned
+	AssignedAt time.Time
+}
+
+// PendingReview is an assigned reviewer of an OPEN PR without a verdict.
+type PendingReview struct {
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	ReviewerID      string
+	SourceTeam      string
+	AssignedAt      time.Time
+}
@@ -7,22 +7,51 @@ type PRStatus string
 const (
 	StatusOpen   PRStatus = "OPEN"
 	StatusMerged PRStatus = "MERGED"
+	StatusClosed PRStatus = "CLOSED"
 )
 
+// Verdict is a reviewer's decision on a PR.
+type Verdict string
+
+const (
+	VerdictApprove Verdict = "approve"
+	// VerdictDecline hands the review back: the reviewer is replaced.
+	VerdictDecline Verdict = "decline"
+)
+
+func (v Verdict) IsValid() bool {
+	return v == VerdictApprove || v == VerdictDecline
+}
+
 type PullRequest struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            PRStatus   `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"created_at"`
-	MergedAt          *time.Time `json:"merged_at,omitempty"`
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            PRStatus `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	// ReviewerTeams maps each assigned reviewer to the team it was drawn from.
+	ReviewerTeams map[string]string `json:"reviewer_teams,omitempty"`
+	// ApprovedBy lists the assigned reviewers that approved the PR.
+	ApprovedBy []string   `json:"approved_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	MergedAt   *time.Time `json:"merged_at,omitempty"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	StaleAt    *time.Time `json:"stale_at,omitempty"`
+}
+
+func (pr *PullRequest) IsOpen() bool {
+	return pr.Status == StatusOpen
 }
 
 func (pr *PullRequest) IsMerged() bool {
 	return pr.Status == StatusMerged
 }
 
+func (pr *PullRequest) IsClosed() bool {
+	return pr.Status == StatusClosed
+}
+
 func (pr *PullRequest) HasReviewer(userID string) bool {
 	for _, reviewerID := range pr.AssignedReviewers {
 		if reviewerID == userID {
@@ -32,10 +61,95 @@ func (pr *PullRequest) HasReviewer(userID string) bool {
 	return false
 }
 
+// ReplaceReviewer swaps oldID for newID in place, keeping the reviewer order.
+func (pr *PullRequest) ReplaceReviewer(oldID, newID, sourceTeam string) {
+	for i, reviewerID := range pr.AssignedReviewers {
+		if reviewerID == oldID {
+			pr.AssignedReviewers[i] = newID
+			break
+		}
+	}
+
+	if pr.ReviewerTeams == nil {
+		pr.ReviewerTeams = make(map[string]string)
+	}
+	delete(pr.ReviewerTeams, oldID)
+	pr.ReviewerTeams[newID] = sourceTeam
+	pr.dropApproval(oldID)
+}
+
+func (pr *PullRequest) RemoveReviewer(userID string) {
+	reviewers := make([]string, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		if reviewerID != userID {
+			reviewers = append(reviewers, reviewerID)
+		}
+	}
+
+	pr.AssignedReviewers = reviewers
+	delete(pr.ReviewerTeams, userID)
+	pr.dropApproval(userID)
+}
+
+func (pr *PullRequest) HasApproved(userID string) bool {
+	for _, reviewerID := range pr.ApprovedBy {
+		if reviewerID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Approve records the reviewer's approval. The reviewer must be assigned.
+func (pr *PullRequest) Approve(userID string) {
+	if !pr.HasApproved(userID) {
+		pr.ApprovedBy = append(pr.ApprovedBy, userID)
+	}
+}
+
+func (pr *PullRequest) dropApproval(userID string) {
+	approved := pr.ApprovedBy[:0]
+	for _, reviewerID := range pr.ApprovedBy {
+		if reviewerID != userID {
+			approved = append(approved, reviewerID)
+		}
+	}
+	pr.ApprovedBy = approved
+}
+
 func (pr *PullRequest) Merge() {
 	if pr.Status != StatusMerged {
 		pr.Status = StatusMerged
 		now := time.Now()
 		pr.MergedAt = &now
+		pr.UpdatedAt = now
+	}
+}
+
+// Touch records activity on the PR and clears a previous stale mark.
+func (pr *PullRequest) Touch() {
+	pr.UpdatedAt = time.Now()
+	pr.StaleAt = nil
+}
+
+// Close closes the PR without merging and releases its reviewers.
+// UpdatedAt is left untouched: closing is not activity on the PR.
+func (pr *PullRequest) Close() {
+	if pr.Status == StatusOpen {
+		pr.Status = StatusClosed
+		now := time.Now()
+		pr.ClosedAt = &now
+		pr.AssignedReviewers = []string{}
+		pr.ApprovedBy = nil
+	}
+}
+
+// MarkStale flags an open PR as stale and releases its reviewers.
+func (pr *PullRequest) MarkStale() {
+	if pr.Status == StatusOpen && pr.StaleAt == nil {
+		now := time.Now()
+		pr.StaleAt = &now
+		pr.AssignedReviewers = []string{}
+		pr.ApprovedBy = nil
 	}
 }
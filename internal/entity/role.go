@@ -0,0 +1,40 @@
+package entity
+
+// TeamRole is a user's role within one team.
+type TeamRole string
+
+const (
+	RoleMember TeamRole = "member"
+	// RoleLead reviews as a last resort and manages the team's PR reviewers and settings.
+	RoleLead TeamRole = "lead"
+	// RoleMaintainer administers the team: everything a lead can do plus its members.
+	RoleMaintainer TeamRole = "maintainer"
+)
+
+func (r TeamRole) IsValid() bool {
+	switch r {
+	case RoleMember, RoleLead, RoleMaintainer:
+		return true
+	}
+	return false
+}
+
+// TeamPermission is an action restricted to some team roles.
+type TeamPermission string
+
+const (
+	// PermReassign covers manual reviewer changes on PRs authored in the team.
+	PermReassign TeamPermission = "reassign"
+	PermSettings TeamPermission = "settings"
+	PermMembers  TeamPermission = "members"
+)
+
+func (r TeamRole) Allows(p TeamPermission) bool {
+	switch r {
+	case RoleMaintainer:
+		return true
+	case RoleLead:
+		return p == PermReassign || p == PermSettings
+	}
+	return false
+}
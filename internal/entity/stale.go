@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+type StaleAction string
+
+const (
+	// StaleActionClose closes stale PRs.
+	StaleActionClose StaleAction = "close"
+	// StaleActionMark keeps stale PRs open but marks them and unassigns reviewers.
+	StaleActionMark StaleAction = "mark"
+)
+
+func (a StaleAction) IsValid() bool {
+	return a == StaleActionClose || a == StaleActionMark
+}
+
+type StaleReport struct {
+	Threshold    string        `json:"threshold"`
+	Cutoff       time.Time     `json:"cutoff"`
+	Action       StaleAction   `json:"action,omitempty"`
+	DryRun       bool          `json:"dry_run"`
+	PullRequests []PullRequest `json:"pull_requests"`
+}
@@ -14,3 +14,15 @@ type PRStats struct {
 	MergedPRs      int `json:"merged_prs"`
 	TotalReviewers int `json:"total_reviewers"`
 }
+
+// TeamStats are rolled up over the team and all of its sub-teams.
+type TeamStats struct {
+	TeamName    string `json:"team_name"`
+	ParentTeam  string `json:"parent_team,omitempty"`
+	SubTeams    int    `json:"sub_teams"`
+	Memberships int    `json:"memberships"`
+	TotalPRs    int    `json:"total_prs"`
+	OpenPRs     int    `json:"open_prs"`
+	MergedPRs   int    `json:"merged_prs"`
+	OpenReviews int    `json:"open_reviews"`
+}
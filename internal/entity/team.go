@@ -2,8 +2,135 @@ package entity
 
 import "time"
 
+const (
+	// AutoReviewers is how many reviewers are picked automatically for a new PR.
+	AutoReviewers = 2
+	// DefaultMaxReviewers leaves room for a reviewer added manually on top of
+	// the automatic ones.
+	DefaultMaxReviewers = AutoReviewers + 1
+)
+
 type Team struct {
-	TeamName  string    `json:"team_name"`
-	Members   []User    `json:"members"`
-	CreatedAt time.Time `json:"created_at"`
+	TeamName   string       `json:"team_name"`
+	ParentTeam string       `json:"parent_team,omitempty"`
+	Members    []User       `json:"members"`
+	Settings   TeamSettings `json:"settings"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ArchivedAt *time.Time   `json:"archived_at,omitempty"`
+	// ManagedBy names the external system that owns the team, see User.ManagedBy.
+	ManagedBy string `json:"managed_by,omitempty"`
+}
+
+func (t *Team) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+func (t *Team) HasMember(userID string) bool {
+	for _, m := range t.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type TeamSettings struct {
+	// MaxReviewers caps how many reviewers a PR authored in the team can have.
+	MaxReviewers int `json:"max_reviewers"`
+	// AllowCrossTeam lets reviewers from any other team be requested manually.
+	AllowCrossTeam bool `json:"allow_cross_team"`
+	// FallbackTeams are partner teams, in priority order, that reviewers are drawn
+	// from when the team itself has no candidates left.
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+// TeamSettingsPatch is a partial settings update: nil fields keep their current value.
+type TeamSettingsPatch struct {
+	MaxReviewers   *int
+	AllowCrossTeam *bool
+	FallbackTeams  *[]string
+}
+
+// MemberChangeOp is how a MemberChange edits the member list.
+type MemberChangeOp string
+
+const (
+	// MemberAdd adds the users to the team.
+	MemberAdd MemberChangeOp = "add"
+	// MemberRemove removes the users that are members of the team.
+	MemberRemove MemberChangeOp = "remove"
+	// MemberReplace makes the users the only members of the team.
+	MemberReplace MemberChangeOp = "replace"
+)
+
+// MemberChange is one edit of a team's member list, e.g. an operation of a
+// SCIM PATCH request.
+type MemberChange struct {
+	Op      MemberChangeOp
+	UserIDs []string
+}
+
+func DefaultTeamSettings() TeamSettings {
+	return TeamSettings{
+		MaxReviewers:  DefaultMaxReviewers,
+		FallbackTeams: []string{},
+	}
+}
+
+// AutoReviewerCount is how many reviewers the selector should pick for the team.
+func (s TeamSettings) AutoReviewerCount() int {
+	return min(AutoReviewers, s.MaxReviewers)
+}
+
+// IsPartner reports whether reviewers from teamName may review the team's PRs
+// without cross-team reviews being allowed in general.
+func (s TeamSettings) IsPartner(teamName string) bool {
+	for _, name := range s.FallbackTeams {
+		if name == teamName {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamNode is a team in the team hierarchy together with its sub-teams.
+type TeamNode struct {
+	TeamName   string     `json:"team_name"`
+	ParentTeam string     `json:"parent_team,omitempty"`
+	Children   []TeamNode `json:"children"`
+}
+
+// BuildTeamTree arranges a flat list of teams into trees. Teams whose parent is
+// not in the list become roots.
+func BuildTeamTree(teams []Team) []TeamNode {
+	present := make(map[string]bool, len(teams))
+	children := make(map[string][]Team, len(teams))
+	for _, t := range teams {
+		present[t.TeamName] = true
+	}
+
+	var roots []Team
+	for _, t := range teams {
+		if t.ParentTeam != "" && present[t.ParentTeam] {
+			children[t.ParentTeam] = append(children[t.ParentTeam], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var build func(t Team) TeamNode
+	build = func(t Team) TeamNode {
+		node := TeamNode{TeamName: t.TeamName, ParentTeam: t.ParentTeam, Children: []TeamNode{}}
+		for _, child := range children[t.TeamName] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	nodes := make([]TeamNode, 0, len(roots))
+	for _, root := range roots {
+		nodes = append(nodes, build(root))
+	}
+
+	return nodes
 }
@@ -0,0 +1,297 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TeamConfig declares teams, their members and settings, e.g. kept in a git
+// repository and synced into the service.
+type TeamConfig struct {
+	Teams []TeamSpec `json:"teams" yaml:"teams"`
+}
+
+// TeamSpec declares one team. Omitted settings and member fields take their
+// defaults rather than keeping the current value.
+type TeamSpec struct {
+	TeamName   string           `json:"team_name"             yaml:"team_name"`
+	ParentTeam string           `json:"parent_team,omitempty" yaml:"parent_team"`
+	Settings   TeamSettingsSpec `json:"settings"              yaml:"settings"`
+	Members    []MemberSpec     `json:"members"               yaml:"members"`
+}
+
+type TeamSettingsSpec struct {
+	MaxReviewers   *int     `json:"max_reviewers,omitempty"    yaml:"max_reviewers"`
+	AllowCrossTeam bool     `json:"allow_cross_team,omitempty" yaml:"allow_cross_team"`
+	FallbackTeams  []string `json:"fallback_teams,omitempty"   yaml:"fallback_teams"`
+}
+
+type MemberSpec struct {
+	UserID   string `json:"user_id"  yaml:"user_id"`
+	Username string `json:"username" yaml:"username"`
+	// IsActive is the membership flag and defaults to true.
+	IsActive     *bool    `json:"is_active,omitempty"     yaml:"is_active"`
+	ReviewWeight *int     `json:"review_weight,omitempty" yaml:"review_weight"`
+	Role         TeamRole `json:"role,omitempty"          yaml:"role"`
+}
+
+// TeamState is a team as compared by the sync: the declared one with defaults
+// applied, or the one stored in the database.
+type TeamState struct {
+	TeamName   string
+	ParentTeam string
+	Settings   TeamSettings
+	Archived   bool
+	Members    []MemberState
+}
+
+// MemberState is a team member with the flags of its membership.
+type MemberState struct {
+	UserID       string
+	Username     string
+	IsActive     bool
+	ReviewWeight int
+	Role         TeamRole
+}
+
+// Validate checks the config and returns the declared teams with defaults applied.
+func (c TeamConfig) Validate() ([]TeamState, error) {
+	teams := make([]TeamState, 0, len(c.Teams))
+	seen := make(map[string]bool, len(c.Teams))
+	for _, spec := range c.Teams {
+		if spec.TeamName == "" {
+			return nil, fmt.Errorf("%w: team without team_name", ErrInvalidTeamConfig)
+		}
+		if seen[spec.TeamName] {
+			return nil, fmt.Errorf("%w: team %s is declared twice", ErrInvalidTeamConfig, spec.TeamName)
+		}
+		seen[spec.TeamName] = true
+
+		team, err := spec.state()
+		if err != nil {
+			return nil, fmt.Errorf("%w: team %s: %w", ErrInvalidTeamConfig, spec.TeamName, err)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
+func (s TeamSpec) state() (TeamState, error) {
+	if s.ParentTeam == s.TeamName {
+		return TeamState{}, ErrTeamCycle
+	}
+
+	settings := DefaultTeamSettings()
+	if s.Settings.MaxReviewers != nil {
+		if *s.Settings.MaxReviewers < 0 {
+			return TeamState{}, ErrInvalidSettings
+		}
+		settings.MaxReviewers = *s.Settings.MaxReviewers
+	}
+	settings.AllowCrossTeam = s.Settings.AllowCrossTeam
+	if s.Settings.FallbackTeams != nil {
+		settings.FallbackTeams = s.Settings.FallbackTeams
+	}
+
+	team := TeamState{TeamName: s.TeamName, ParentTeam: s.ParentTeam, Settings: settings}
+	seen := make(map[string]bool, len(s.Members))
+	for _, m := range s.Members {
+		if m.UserID == "" {
+			return TeamState{}, errors.New("member without user_id")
+		}
+		if seen[m.UserID] {
+			return TeamState{}, fmt.Errorf("member %s is declared twice", m.UserID)
+		}
+		seen[m.UserID] = true
+
+		member := MemberState{
+			UserID:       m.UserID,
+			Username:     m.Username,
+			IsActive:     m.IsActive == nil || *m.IsActive,
+			ReviewWeight: DefaultReviewWeight,
+			Role:         RoleMember,
+		}
+		if m.ReviewWeight != nil {
+			if *m.ReviewWeight < 0 {
+				return TeamState{}, fmt.Errorf("member %s: %w", m.UserID, ErrInvalidSettings)
+			}
+			member.ReviewWeight = *m.ReviewWeight
+		}
+		if m.Role != "" {
+			if !m.Role.IsValid() {
+				return TeamState{}, fmt.Errorf("member %s: unknown role %q", m.UserID, m.Role)
+			}
+			member.Role = m.Role
+		}
+		team.Members = append(team.Members, member)
+	}
+
+	return team, nil
+}
+
+type TeamSyncAction string
+
+const (
+	SyncCreateTeam     TeamSyncAction = "create_team"
+	SyncUpdateSettings TeamSyncAction = "update_settings"
+	SyncSetParent      TeamSyncAction = "set_parent"
+	SyncArchiveTeam    TeamSyncAction = "archive_team"
+	SyncAddMember      TeamSyncAction = "add_member"
+	SyncUpdateMember   TeamSyncAction = "update_member"
+	SyncRenameUser     TeamSyncAction = "rename_user"
+	SyncRemoveMember   TeamSyncAction = "remove_member"
+	SyncCreateUser     TeamSyncAction = "create_user"
+	SyncDeactivateUser TeamSyncAction = "deactivate_user"
+)
+
+// TeamSyncChange is one step of a sync plan.
+type TeamSyncChange struct {
+	Action   TeamSyncAction `json:"action"`
+	TeamName string         `json:"team_name,omitempty"`
+	UserID   string         `json:"user_id,omitempty"`
+	// Changes lists the changed fields as "field: old -> new".
+	Changes []string `json:"changes,omitempty"`
+}
+
+func (c TeamSyncChange) String() string {
+	s := string(c.Action)
+	if c.TeamName != "" {
+		s += " " + c.TeamName
+	}
+	if c.UserID != "" {
+		s += " " + c.UserID
+	}
+	if len(c.Changes) > 0 {
+		s += " (" + strings.Join(c.Changes, ", ") + ")"
+	}
+	return s
+}
+
+// TeamSyncResult is the plan of a sync and, once applied, the reviews moved
+// away from removed or deactivated members.
+type TeamSyncResult struct {
+	DryRun        bool             `json:"dry_run"`
+	Changes       []TeamSyncChange `json:"changes"`
+	ReassignedPRs []PullRequest    `json:"reassigned_prs"`
+}
+
+// PlanTeamSync returns the changes that turn the current teams into the
+// desired ones, in the order they must be applied: teams are created before
+// anything refers to them and members are removed last, so their reviews can
+// move to the members added by the sync. Teams missing from desired are
+// archived only when prune is set.
+func PlanTeamSync(current map[string]TeamState, desired []TeamState, prune bool) []TeamSyncChange {
+	var creates, teamUpdates, memberUpdates, removals []TeamSyncChange
+
+	declared := make(map[string]bool, len(desired))
+	renamed := make(map[string]bool)
+	for _, want := range desired {
+		declared[want.TeamName] = true
+
+		have, exists := current[want.TeamName]
+		if !exists {
+			creates = append(creates, TeamSyncChange{Action: SyncCreateTeam, TeamName: want.TeamName})
+			have = TeamState{TeamName: want.TeamName, Settings: DefaultTeamSettings()}
+		}
+
+		if changes := diffSettings(have.Settings, want.Settings); len(changes) > 0 {
+			teamUpdates = append(teamUpdates, TeamSyncChange{Action: SyncUpdateSettings, TeamName: want.TeamName, Changes: changes})
+		}
+		if have.ParentTeam != want.ParentTeam {
+			teamUpdates = append(teamUpdates, TeamSyncChange{
+				Action:   SyncSetParent,
+				TeamName: want.TeamName,
+				Changes:  []string{change("parent_team", have.ParentTeam, want.ParentTeam)},
+			})
+		}
+
+		members := make(map[string]MemberState, len(have.Members))
+		for _, m := range have.Members {
+			members[m.UserID] = m
+		}
+		for _, m := range want.Members {
+			old, isMember := members[m.UserID]
+			delete(members, m.UserID)
+			if !isMember {
+				memberUpdates = append(memberUpdates, TeamSyncChange{Action: SyncAddMember, TeamName: want.TeamName, UserID: m.UserID})
+				old = MemberState{UserID: m.UserID, Username: m.Username, IsActive: true, ReviewWeight: DefaultReviewWeight, Role: RoleMember}
+			}
+			if isMember && m.Username != "" && old.Username != m.Username && !renamed[m.UserID] {
+				renamed[m.UserID] = true
+				memberUpdates = append(memberUpdates, TeamSyncChange{
+					Action:   SyncRenameUser,
+					TeamName: want.TeamName,
+					UserID:   m.UserID,
+					Changes:  []string{change("username", old.Username, m.Username)},
+				})
+			}
+			if changes := diffMember(old, m); len(changes) > 0 {
+				memberUpdates = append(memberUpdates, TeamSyncChange{Action: SyncUpdateMember, TeamName: want.TeamName, UserID: m.UserID, Changes: changes})
+			}
+		}
+
+		removed := make([]string, 0, len(members))
+		for userID := range members {
+			removed = append(removed, userID)
+		}
+		sort.Strings(removed)
+		for _, userID := range removed {
+			removals = append(removals, TeamSyncChange{Action: SyncRemoveMember, TeamName: want.TeamName, UserID: userID})
+		}
+	}
+
+	if prune {
+		names := make([]string, 0, len(current))
+		for name, team := range current {
+			if !declared[name] && !team.Archived {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			removals = append(removals, TeamSyncChange{Action: SyncArchiveTeam, TeamName: name})
+		}
+	}
+
+	plan := make([]TeamSyncChange, 0, len(creates)+len(teamUpdates)+len(memberUpdates)+len(removals))
+	plan = append(plan, creates...)
+	plan = append(plan, teamUpdates...)
+	plan = append(plan, memberUpdates...)
+	return append(plan, removals...)
+}
+
+func diffSettings(have, want TeamSettings) []string {
+	var changes []string
+	if have.MaxReviewers != want.MaxReviewers {
+		changes = append(changes, change("max_reviewers", have.MaxReviewers, want.MaxReviewers))
+	}
+	if have.AllowCrossTeam != want.AllowCrossTeam {
+		changes = append(changes, change("allow_cross_team", have.AllowCrossTeam, want.AllowCrossTeam))
+	}
+	if !slices.Equal(have.FallbackTeams, want.FallbackTeams) {
+		changes = append(changes, change("fallback_teams", strings.Join(have.FallbackTeams, ","), strings.Join(want.FallbackTeams, ",")))
+	}
+	return changes
+}
+
+func diffMember(have, want MemberState) []string {
+	var changes []string
+	if have.IsActive != want.IsActive {
+		changes = append(changes, change("is_active", have.IsActive, want.IsActive))
+	}
+	if have.ReviewWeight != want.ReviewWeight {
+		changes = append(changes, change("review_weight", have.ReviewWeight, want.ReviewWeight))
+	}
+	if have.Role != want.Role {
+		changes = append(changes, change("role", have.Role, want.Role))
+	}
+	return changes
+}
+
+func change(field string, from, to any) string {
+	return fmt.Sprintf("%s: %v -> %v", field, from, to)
+}
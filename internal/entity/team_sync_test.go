@@ -0,0 +1,98 @@
+package entity_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPlanTeamSync(t *testing.T) {
+	current := map[string]entity.TeamState{
+		"backend": {
+			TeamName: "backend",
+			Settings: entity.DefaultTeamSettings(),
+			Members: []entity.MemberState{
+				{UserID: "u1", Username: "Alice", IsActive: true, ReviewWeight: 1, Role: entity.RoleMember},
+				{UserID: "u2", Username: "Bob", IsActive: true, ReviewWeight: 1, Role: entity.RoleMember},
+				{UserID: "u3", Username: "Carol", IsActive: true, ReviewWeight: 1, Role: entity.RoleMember},
+			},
+		},
+		"legacy":  {TeamName: "legacy", Settings: entity.DefaultTeamSettings()},
+		"retired": {TeamName: "retired", Settings: entity.DefaultTeamSettings(), Archived: true},
+	}
+
+	cfg := entity.TeamConfig{Teams: []entity.TeamSpec{
+		{
+			TeamName: "backend",
+			Settings: entity.TeamSettingsSpec{MaxReviewers: ptr(1)},
+			Members: []entity.MemberSpec{
+				{UserID: "u1", Username: "Alice A."},
+				{UserID: "u2", Username: "Bob", IsActive: ptr(false), Role: entity.RoleLead},
+				{UserID: "u4", Username: "Dave", ReviewWeight: ptr(2)},
+			},
+		},
+		{TeamName: "platform", ParentTeam: "backend"},
+	}}
+
+	desired, err := cfg.Validate()
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	got := entity.PlanTeamSync(current, desired, true)
+	want := []entity.TeamSyncChange{
+		{Action: entity.SyncCreateTeam, TeamName: "platform"},
+		{Action: entity.SyncUpdateSettings, TeamName: "backend", Changes: []string{"max_reviewers: 3 -> 1"}},
+		{Action: entity.SyncSetParent, TeamName: "platform", Changes: []string{"parent_team:  -> backend"}},
+		{Action: entity.SyncRenameUser, TeamName: "backend", UserID: "u1", Changes: []string{"username: Alice -> Alice A."}},
+		{Action: entity.SyncUpdateMember, TeamName: "backend", UserID: "u2", Changes: []string{"is_active: true -> false", "role: member -> lead"}},
+		{Action: entity.SyncAddMember, TeamName: "backend", UserID: "u4"},
+		{Action: entity.SyncUpdateMember, TeamName: "backend", UserID: "u4", Changes: []string{"review_weight: 1 -> 2"}},
+		{Action: entity.SyncRemoveMember, TeamName: "backend", UserID: "u3"},
+		{Action: entity.SyncArchiveTeam, TeamName: "legacy"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plan =\n%v\nwant\n%v", got, want)
+	}
+
+	if plan := entity.PlanTeamSync(current, desired, false); plan[len(plan)-1].Action == entity.SyncArchiveTeam {
+		t.Error("teams archived without prune")
+	}
+}
+
+func TestPlanTeamSyncNoChanges(t *testing.T) {
+	cfg := entity.TeamConfig{Teams: []entity.TeamSpec{
+		{TeamName: "backend", Members: []entity.MemberSpec{{UserID: "u1", Username: "Alice"}}},
+	}}
+	desired, err := cfg.Validate()
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	current := map[string]entity.TeamState{"backend": desired[0]}
+	if plan := entity.PlanTeamSync(current, desired, true); len(plan) != 0 {
+		t.Errorf("plan = %v, want no changes", plan)
+	}
+}
+
+func TestTeamConfigValidate(t *testing.T) {
+	for name, cfg := range map[string]entity.TeamConfig{
+		"no team name":     {Teams: []entity.TeamSpec{{}}},
+		"duplicate team":   {Teams: []entity.TeamSpec{{TeamName: "a"}, {TeamName: "a"}}},
+		"own parent":       {Teams: []entity.TeamSpec{{TeamName: "a", ParentTeam: "a"}}},
+		"duplicate member": {Teams: []entity.TeamSpec{{TeamName: "a", Members: []entity.MemberSpec{{UserID: "u1"}, {UserID: "u1"}}}}},
+		"unknown role":     {Teams: []entity.TeamSpec{{TeamName: "a", Members: []entity.MemberSpec{{UserID: "u1", Role: "owner"}}}}},
+		"negative weight":  {Teams: []entity.TeamSpec{{TeamName: "a", Members: []entity.MemberSpec{{UserID: "u1", ReviewWeight: ptr(-1)}}}}},
+	} {
+		if _, err := cfg.Validate(); !errors.Is(err, entity.ErrInvalidTeamConfig) {
+			t.Errorf("%s: err = %v, want ErrInvalidTeamConfig", name, err)
+		}
+	}
+}
@@ -1,13 +1,32 @@
 package entity
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// DefaultReviewWeight is the selection weight of a new team membership.
+const DefaultReviewWeight = 1
+
+// ErasedUsername replaces the username of an erased user.
+const ErasedUsername = "deleted user"
 
 type User struct {
-	UserID    string    `json:"user_id"`
-	Username  string    `json:"username"`
-	TeamName  string    `json:"team_name"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	// TeamName is the user's primary team. In a team context (team members,
+	// reviewer selection) it is the team the user was loaded through.
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+	// ReviewWeight and Role come from the membership and are set only in a team context.
+	ReviewWeight int        `json:"review_weight,omitempty"`
+	Role         TeamRole   `json:"role,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	// ManagedBy names the external system that owns the user, e.g.
+	// ManagedByDirectory; it is empty for users created in the service.
+	ManagedBy string `json:"managed_by,omitempty"`
 }
 
 func (u *User) Activate() {
@@ -17,3 +36,70 @@ func (u *User) Activate() {
 func (u *User) Deactivate() {
 	u.IsActive = false
 }
+
+func (u *User) IsArchived() bool {
+	return u.ArchivedAt != nil
+}
+
+// Archive deactivates the user and takes them out of reviewer selection for good.
+func (u *User) Archive() {
+	now := time.Now()
+	u.IsActive = false
+	u.ArchivedAt = &now
+}
+
+// NewErasedUserID returns a random anonymous id for an erased user. It is not
+// derived from the original id, so it cannot be traced back to the user.
+func NewErasedUserID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "deleted-" + hex.EncodeToString(b)
+}
+
+// Membership is a user's participation in one team.
+type Membership struct {
+	TeamName     string    `json:"team_name"`
+	IsActive     bool      `json:"is_active"`
+	ReviewWeight int       `json:"review_weight"`
+	Role         TeamRole  `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MembershipPatch is a partial membership update: nil fields keep their current value.
+type MembershipPatch struct {
+	IsActive     *bool
+	ReviewWeight *int
+	Role         *TeamRole
+}
+
+// UserFilter selects users in the user directory. Zero values disable a filter.
+type UserFilter struct {
+	TeamName string
+	IsActive *bool
+	// MaxOpenReviews keeps users with fewer OPEN reviews than this.
+	MaxOpenReviews int
+	// Query matches the username case-insensitively; prefix matches rank first.
+	Query           string
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+// UserSummary is a user directory entry.
+type UserSummary struct {
+	User
+	OpenReviews int `json:"open_reviews"`
+}
+
+// UserProfile is a directory entry together with the user's team memberships.
+type UserProfile struct {
+	UserSummary
+	Memberships []Membership `json:"memberships"`
+}
+
+type UserPage struct {
+	Users  []UserSummary `json:"users"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
+
+	"pr-reviewer-service/internal/entity"
+)
+
+// Sender queues a message for a webhook without blocking, e.g. *slack.Batcher.
+type Sender interface {
+	Add(webhookURL, text string)
+}
+
+// Config routes notifications to incoming webhooks. Templates override the
+// message of an event and are executed with the entity.Notification.
+type Config struct {
+	Teams     map[string]string
+	Users     map[string]string
+	Templates map[string]string
+}
+
+var defaultTemplates = map[entity.NotificationEvent]string{
+	entity.NotifyAssigned: `:eyes: {{.ReviewerID}} was picked to review *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.AuthorID}}` +
+		`{{with .TeamName}} for team {{.}}{{end}}`,
+	entity.NotifyReassigned: `:arrows_counterclockwise: *{{.PullRequestName}}* ({{.PullRequestID}}) moved from {{.PreviousReviewerID}} to {{.ReviewerID}}` +
+		`{{with .Reason}} ({{.}}){{end}}`,
+	entity.NotifySLABreach: `:hourglass: {{.ReviewerID}} has not reviewed *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.AuthorID}}` +
+		` since {{.AssignedAt.UTC.Format "2006-01-02 15:04 MST"}}`,
+	entity.NotifyMerged: `:white_check_mark: *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.AuthorID}} was merged` +
+		`{{with .Reviewers}}, reviewed by {{join . ", "}}{{end}}`,
+}
+
+// Webhook posts notifications to the webhooks of the users and the team
+// involved.
+type Webhook struct {
+	sender    Sender
+	teams     map[string]string
+	users     map[string]string
+	templates map[entity.NotificationEvent]*template.Template
+}
+
+func New(cfg Config, sender Sender) (*Webhook, error) {
+	w := &Webhook{
+		sender:    sender,
+		teams:     cfg.Teams,
+		users:     cfg.Users,
+		templates: make(map[entity.NotificationEvent]*template.Template, len(defaultTemplates)),
+	}
+
+	for event := range cfg.Templates {
+		if _, ok := defaultTemplates[entity.NotificationEvent(event)]; !ok {
+			return nil, fmt.Errorf("notify - New - unknown event %q", event)
+		}
+	}
+
+	for event, text := range defaultTemplates {
+		if custom, ok := cfg.Templates[string(event)]; ok {
+			text = custom
+		}
+
+		tmpl, err := template.New(string(event)).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("notify - New - template %q: %w", event, err)
+		}
+		w.templates[event] = tmpl
+	}
+
+	return w, nil
+}
+
+// Notify renders n and queues it for every webhook it is routed to.
+func (w *Webhook) Notify(ctx context.Context, n entity.Notification) {
+	urls := w.route(n)
+	if len(urls) == 0 {
+		return
+	}
+
+	tmpl, ok := w.templates[n.Event]
+	if !ok {
+		slog.WarnContext(ctx, "notify - unknown event", "event", n.Event)
+		return
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, n); err != nil {
+		slog.ErrorContext(ctx, "notify - template failed", "event", n.Event, "pull_request_id", n.PullRequestID, "error", err)
+		return
+	}
+
+	for _, webhookURL := range urls {
+		w.sender.Add(webhookURL, text.String())
+	}
+}
+
+// route returns the webhooks of n without duplicates: the reviewers and the
+// replaced reviewer get a direct message and the team a channel message.
+func (w *Webhook) route(n entity.Notification) []string {
+	var urls []string
+	add := func(webhookURL string) {
+		if webhookURL != "" && !slices.Contains(urls, webhookURL) {
+			urls = append(urls, webhookURL)
+		}
+	}
+
+	switch n.Event {
+	case entity.NotifyAssigned, entity.NotifySLABreach:
+		add(w.users[n.ReviewerID])
+	case entity.NotifyReassigned:
+		add(w.users[n.ReviewerID])
+		add(w.users[n.PreviousReviewerID])
+	case entity.NotifyMerged:
+		for _, reviewerID := range n.Reviewers {
+			add(w.users[reviewerID])
+		}
+	}
+	add(w.teams[n.TeamName])
+
+	return urls
+}
@@ -0,0 +1,123 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/pkg/slack"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		posts = make(map[string][]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		posts[r.URL.Path] = append(posts[r.URL.Path], msg.Text)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	batcher := slack.NewBatcher(slack.NewClient(srv.Client()), time.Hour, 10)
+	webhook, err := notify.New(notify.Config{
+		Teams: map[string]string{"backend": srv.URL + "/backend"},
+		Users: map[string]string{
+			"u2": srv.URL + "/u2",
+			"u3": srv.URL + "/u3",
+			// u4 shares the team channel
+			"u4": srv.URL + "/backend",
+		},
+		Templates: map[string]string{
+			"merged": `{{.PullRequestID}} merged after {{join .Reviewers "+"}}`,
+		},
+	}, batcher)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx := context.Background()
+	webhook.Notify(ctx, entity.Notification{
+		Event: entity.NotifyAssigned, PullRequestID: "pr-1", PullRequestName: "Add search",
+		AuthorID: "u1", ReviewerID: "u2", TeamName: "backend", Reason: entity.ReasonAuto,
+	})
+	webhook.Notify(ctx, entity.Notification{
+		Event: entity.NotifyReassigned, PullRequestID: "pr-1", PullRequestName: "Add search",
+		AuthorID: "u1", ReviewerID: "u3", PreviousReviewerID: "u2", TeamName: "backend", Reason: entity.ReasonDecline,
+	})
+	webhook.Notify(ctx, entity.Notification{
+		Event: entity.NotifySLABreach, PullRequestID: "pr-2", PullRequestName: "Fix login",
+		AuthorID: "u1", ReviewerID: "u4", TeamName: "backend",
+		AssignedAt: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+	})
+	webhook.Notify(ctx, entity.Notification{
+		Event: entity.NotifyMerged, PullRequestID: "pr-1", PullRequestName: "Add search",
+		AuthorID: "u1", Reviewers: []string{"u3", "u9"}, TeamName: "frontend",
+	})
+	// nobody to tell
+	webhook.Notify(ctx, entity.Notification{Event: entity.NotifyAssigned, PullRequestID: "pr-3", ReviewerID: "u9"})
+	batcher.Close()
+
+	want := map[string][]string{
+		"/u2": {
+			"u2 was picked to review *Add search* (pr-1) by u1 for team backend",
+			"*Add search* (pr-1) moved from u2 to u3 (decline)",
+		},
+		"/u3": {
+			"*Add search* (pr-1) moved from u2 to u3 (decline)",
+			"pr-1 merged after u3+u9",
+		},
+		"/backend": {
+			"u2 was picked to review *Add search* (pr-1) by u1 for team backend",
+			"*Add search* (pr-1) moved from u2 to u3 (decline)",
+			"u4 has not reviewed *Fix login* (pr-2) by u1 since 2024-03-01 09:30 UTC",
+		},
+	}
+
+	if len(posts) != len(want) {
+		t.Errorf("posted to %d webhooks, want %d: %q", len(posts), len(want), posts)
+	}
+	for path, texts := range want {
+		got := posts[path]
+		if len(got) != 1 {
+			t.Errorf("%s: got %d posts, want one batch", path, len(got))
+			continue
+		}
+		// emoji prefixes aside, every message arrives once and in order
+		lines := strings.Split(got[0], "\n\n")
+		if len(lines) != len(texts) {
+			t.Errorf("%s: got %q, want %q", path, lines, texts)
+			continue
+		}
+		for i, text := range texts {
+			if !strings.HasSuffix(lines[i], text) {
+				t.Errorf("%s: message %d = %q, want %q", path, i, lines[i], text)
+			}
+		}
+	}
+}
+
+func TestNewRejectsTemplates(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unknown event":  {"closed": "{{.PullRequestID}}"},
+		"invalid syntax": {"assigned": "{{.PullRequestID"},
+	}
+	for name, templates := range tests {
+		if _, err := notify.New(notify.Config{Templates: templates}, nil); err == nil {
+			t.Errorf("%s: New succeeded, want an error", name)
+		}
+	}
+}
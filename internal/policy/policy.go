@@ -0,0 +1,202 @@
+// Package policy decides which principal may perform which mutating operation.
+// The rules are pure functions over a Principal; Policy loads the facts they
+// need (memberships, PR authors) and is called by the HTTP handlers before the
+// usecases.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// Principal is who performs a request.
+type Principal struct {
+	// UserID is the acting user. It is empty for a service principal: the
+	// service itself or a client authenticated with a token bound to no user.
+	// A service principal passes only the rules that admit any principal of
+	// its token scope; rules about a user or a team role need a user or an admin.
+	UserID string
+	// Admin principals may perform every operation.
+	Admin bool
+}
+
+// PrincipalFromContext builds the principal of a request. With authentication
+// enabled an admin-scoped token makes the principal an admin. Without it, a
+// request naming no user comes from a trusted client and is an admin as well.
+func PrincipalFromContext(ctx context.Context) Principal {
+	p := Principal{UserID: entity.ActorFromContext(ctx)}
+	if token, ok := entity.APITokenFromContext(ctx); ok {
+		p.Admin = token.HasScope(entity.ScopeAdmin)
+	} else {
+		p.Admin = p.UserID == ""
+	}
+	return p
+}
+
+// Is reports whether the principal acts as the user userID.
+func (p Principal) Is(userID string) bool {
+	return p.UserID != "" && p.UserID == userID
+}
+
+// CanManageTeams covers creating, deactivating and archiving teams.
+func CanManageTeams(p Principal) bool {
+	return p.Admin
+}
+
+// CanManageUsers covers archiving and erasing users.
+func CanManageUsers(p Principal) bool {
+	return p.Admin
+}
+
+// CanReadAudit covers reading the audit log.
+func CanReadAudit(p Principal) bool {
+	return p.Admin
+}
+
+// CanTeam reports whether a principal whose role in the team is role may
+// perform perm on it. An empty role means no active membership.
+func CanTeam(p Principal, role entity.TeamRole, perm entity.TeamPermission) bool {
+	return p.Admin || role.Allows(perm)
+}
+
+// CanReassign covers manual reviewer changes on a PR: its author and the leads
+// of the author's team may make them. role is the principal's role in that team.
+func CanReassign(p Principal, authorID string, role entity.TeamRole) bool {
+	return p.Is(authorID) || CanTeam(p, role, entity.PermReassign)
+}
+
+// CanSubmitVerdict allows only the reviewer to submit their verdict. Admins
+// are no exception: a verdict speaks for the reviewer.
+func CanSubmitVerdict(p Principal, reviewerID string) bool {
+	return p.Is(reviewerID)
+}
+
+// CanSetIsActive lets users toggle their own activity; admins may toggle anyone's.
+func CanSetIsActive(p Principal, userID string) bool {
+	return p.Admin || p.Is(userID)
+}
+
+// Policy enforces the rules for a request, returning entity.ErrForbidden when
+// the principal is not allowed.
+type Policy struct {
+	userRepo repo.UserRepo
+	prRepo   repo.PullRequestRepo
+}
+
+func New(ur repo.UserRepo, prr repo.PullRequestRepo) *Policy {
+	return &Policy{
+		userRepo: ur,
+		prRepo:   prr,
+	}
+}
+
+func (p *Policy) AuthorizeManageTeams(ctx context.Context) error {
+	return allow(CanManageTeams(PrincipalFromContext(ctx)))
+}
+
+func (p *Policy) AuthorizeManageUsers(ctx context.Context) error {
+	return allow(CanManageUsers(PrincipalFromContext(ctx)))
+}
+
+func (p *Policy) AuthorizeReadAudit(ctx context.Context) error {
+	return allow(CanReadAudit(PrincipalFromContext(ctx)))
+}
+
+// AuthorizeTeam checks that the principal may perform perm on the team.
+func (p *Policy) AuthorizeTeam(ctx context.Context, teamName string, perm entity.TeamPermission) error {
+	principal := PrincipalFromContext(ctx)
+	if CanTeam(principal, "", perm) {
+		return nil
+	}
+
+	role, err := p.roleIn(ctx, principal.UserID, teamName)
+	if err != nil {
+		return fmt.Errorf("Policy - AuthorizeTeam - %w", err)
+	}
+
+	return allow(CanTeam(principal, role, perm))
+}
+
+// AuthorizeReassign checks that the principal may change the PR's reviewers.
+// The PR belongs to its author's primary team.
+func (p *Policy) AuthorizeReassign(ctx context.Context, prID string) error {
+	principal := PrincipalFromContext(ctx)
+	if CanTeam(principal, "", entity.PermReassign) {
+		return nil
+	}
+
+	pr, err := p.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return fmt.Errorf("Policy - AuthorizeReassign - p.prRepo.GetByID: %w", err)
+	}
+	if CanReassign(principal, pr.AuthorID, "") {
+		return nil
+	}
+
+	author, err := p.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("Policy - AuthorizeReassign - p.userRepo.GetByID: %w", err)
+	}
+
+	role, err := p.roleIn(ctx, principal.UserID, author.TeamName)
+	if err != nil {
+		return fmt.Errorf("Policy - AuthorizeReassign - %w", err)
+	}
+
+	return allow(CanReassign(principal, pr.AuthorID, role))
+}
+
+// AuthorizeMoveMember checks that the principal may move the user from
+// fromTeam to toTeam, which takes PermMembers on both teams, and returns the
+// source team: an empty fromTeam means the user's primary team.
+func (p *Policy) AuthorizeMoveMember(ctx context.Context, userID, fromTeam, toTeam string) (string, error) {
+	if fromTeam == "" {
+		user, err := p.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return "", fmt.Errorf("Policy - AuthorizeMoveMember - p.userRepo.GetByID: %w", err)
+		}
+		fromTeam = user.TeamName
+	}
+
+	for _, teamName := range []string{fromTeam, toTeam} {
+		if err := p.AuthorizeTeam(ctx, teamName, entity.PermMembers); err != nil {
+			return "", err
+		}
+	}
+
+	return fromTeam, nil
+}
+
+func (p *Policy) AuthorizeVerdict(ctx context.Context, reviewerID string) error {
+	return allow(CanSubmitVerdict(PrincipalFromContext(ctx), reviewerID))
+}
+
+func (p *Policy) AuthorizeSetIsActive(ctx context.Context, userID string) error {
+	return allow(CanSetIsActive(PrincipalFromContext(ctx), userID))
+}
+
+// roleIn returns the user's role in the team, or an empty role when the user
+// has no active membership there.
+func (p *Policy) roleIn(ctx context.Context, userID, teamName string) (entity.TeamRole, error) {
+	memberships, err := p.userRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("p.userRepo.GetMemberships: %w", err)
+	}
+
+	for _, m := range memberships {
+		if m.TeamName == teamName && m.IsActive {
+			return m.Role, nil
+		}
+	}
+
+	return "", nil
+}
+
+func allow(ok bool) error {
+	if !ok {
+		return entity.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/policy"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+var (
+	admin   = policy.Principal{UserID: "u-admin", Admin: true}
+	service = policy.Principal{}
+	author  = policy.Principal{UserID: "u-author"}
+	other   = policy.Principal{UserID: "u-other"}
+)
+
+func TestPrincipalFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want policy.Principal
+	}{
+		{
+			name: "trusted client without auth",
+			ctx:  context.Background(),
+			want: policy.Principal{Admin: true},
+		},
+		{
+			name: "user header without auth",
+			ctx:  entity.WithActor(context.Background(), "u1"),
+			want: policy.Principal{UserID: "u1"},
+		},
+		{
+			name: "write token without user",
+			ctx:  entity.WithAPIToken(context.Background(), entity.APIToken{Scopes: []entity.Scope{entity.ScopeWrite}}),
+			want: policy.Principal{},
+		},
+		{
+			name: "admin token bound to user",
+			ctx: entity.WithActor(
+				entity.WithAPIToken(context.Background(), entity.APIToken{Scopes: []entity.Scope{entity.ScopeAdmin}, UserID: "u1"}),
+				"u1",
+			),
+			want: policy.Principal{UserID: "u1", Admin: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.PrincipalFromContext(tt.ctx); got != tt.want {
+				t.Errorf("PrincipalFromContext() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow func(p policy.Principal) bool
+		want  map[policy.Principal]bool
+	}{
+		{
+			name:  "manage teams",
+			allow: policy.CanManageTeams,
+			want:  map[policy.Principal]bool{admin: true, service: false, author: false, other: false},
+		},
+		{
+			name:  "manage users",
+			allow: policy.CanManageUsers,
+			want:  map[policy.Principal]bool{admin: true, service: false, author: false, other: false},
+		},
+		{
+			name:  "read audit log",
+			allow: policy.CanReadAudit,
+			want:  map[policy.Principal]bool{admin: true, service: false, author: false, other: false},
+		},
+		{
+			name: "reassign as member",
+			allow: func(p policy.Principal) bool {
+				return policy.CanReassign(p, author.UserID, entity.RoleMember)
+			},
+			want: map[policy.Principal]bool{admin: true, service: false, author: true, other: false},
+		},
+		{
+			name: "reassign without membership",
+			allow: func(p policy.Principal) bool {
+				return policy.CanReassign(p, author.UserID, "")
+			},
+			want: map[policy.Principal]bool{admin: true, service: false, author: true, other: false},
+		},
+		{
+			name: "reassign as lead",
+			allow: func(p policy.Principal) bool {
+				return policy.CanReassign(p, author.UserID, entity.RoleLead)
+			},
+			want: map[policy.Principal]bool{admin: true, service: true, author: true, other: true},
+		},
+		{
+			name: "reassign as maintainer",
+			allow: func(p policy.Principal) bool {
+				return policy.CanReassign(p, author.UserID, entity.RoleMaintainer)
+			},
+			want: map[policy.Principal]bool{admin: true, service: true, author: true, other: true},
+		},
+		{
+			name: "submit own verdict",
+			allow: func(p policy.Principal) bool {
+				return policy.CanSubmitVerdict(p, other.UserID)
+			},
+			want: map[policy.Principal]bool{admin: false, service: false, author: false, other: true},
+		},
+		{
+			name: "set own is_active",
+			allow: func(p policy.Principal) bool {
+				return policy.CanSetIsActive(p, other.UserID)
+			},
+			want: map[policy.Principal]bool{admin: true, service: false, author: false, other: true},
+		},
+		{
+			name: "team members as lead",
+			allow: func(p policy.Principal) bool {
+				return policy.CanTeam(p, entity.RoleLead, entity.PermMembers)
+			},
+			want: map[policy.Principal]bool{admin: true, service: false, author: false, other: false},
+		},
+		{
+			name: "team members as maintainer",
+			allow: func(p policy.Principal) bool {
+				return policy.CanTeam(p, entity.RoleMaintainer, entity.PermMembers)
+			},
+			want: map[policy.Principal]bool{admin: true, service: true, author: true, other: true},
+		},
+		{
+			name: "team settings as lead",
+			allow: func(p policy.Principal) bool {
+				return policy.CanTeam(p, entity.RoleLead, entity.PermSettings)
+			},
+			want: map[policy.Principal]bool{admin: true, service: true, author: true, other: true},
+		},
+		{
+			name: "team settings as member",
+			allow: func(p policy.Principal) bool {
+				return policy.CanTeam(p, entity.RoleMember, entity.PermSettings)
+			},
+			want: map[policy.Principal]bool{admin: true, service: false, author: false, other: false},
+		},
+	}
+
+	for _, tt := range tests {
+		for p, want := range tt.want {
+			name := tt.name + "/" + p.UserID
+			if p.UserID == "" {
+				name = tt.name + "/service"
+			}
+			t.Run(name, func(t *testing.T) {
+				if got := tt.allow(p); got != want {
+					t.Errorf("allow(%+v) = %t, want %t", p, got, want)
+				}
+			})
+		}
+	}
+}
+
+type userRepo struct {
+	repo.UserRepo
+	users       map[string]entity.User
+	memberships map[string][]entity.Membership
+}
+
+func (r userRepo) GetByID(_ context.Context, userID string) (entity.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return entity.User{}, entity.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r userRepo) GetMemberships(_ context.Context, userID string) ([]entity.Membership, error) {
+	return r.memberships[userID], nil
+}
+
+func TestAuthorizeMoveMember(t *testing.T) {
+	maintainer := func(teamName string) entity.Membership {
+		return entity.Membership{TeamName: teamName, IsActive: true, Role: entity.RoleMaintainer}
+	}
+	users := map[string]entity.User{"u1": {UserID: "u1", TeamName: "backend"}}
+
+	tests := []struct {
+		name        string
+		memberships []entity.Membership
+		fromTeam    string
+		wantErr     error
+	}{
+		{
+			name:        "maintainer of the target team only, source team omitted",
+			memberships: []entity.Membership{maintainer("frontend")},
+			wantErr:     entity.ErrForbidden,
+		},
+		{
+			name:        "maintainer of the target team only, source team named",
+			memberships: []entity.Membership{maintainer("frontend")},
+			fromTeam:    "backend",
+			wantErr:     entity.ErrForbidden,
+		},
+		{
+			name:        "maintainer of both teams",
+			memberships: []entity.Membership{maintainer("backend"), maintainer("frontend")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy.New(userRepo{
+				users:       users,
+				memberships: map[string][]entity.Membership{"u-lead": tt.memberships},
+			}, nil)
+			ctx := entity.WithActor(context.Background(), "u-lead")
+
+			fromTeam, err := p.AuthorizeMoveMember(ctx, "u1", tt.fromTeam, "frontend")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthorizeMoveMember() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && fromTeam != "backend" {
+				t.Errorf("AuthorizeMoveMember() = %q, want the primary team backend", fromTeam)
+			}
+		})
+	}
+}
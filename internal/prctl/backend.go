@@ -0,0 +1,25 @@
+// Package prctl implements the prctl admin command line tool. Commands run
+// against the HTTP API of the service or directly against its database.
+package prctl
+
+import (
+	"context"
+	"pr-reviewer-service/internal/entity"
+)
+
+// Backend performs the operations behind the prctl commands.
+type Backend interface {
+	CreateTeam(ctx context.Context, teamName string, members []entity.User) (entity.Team, error)
+	// SyncTeams makes the teams match cfg, or only plans it when dryRun is set.
+	SyncTeams(ctx context.Context, cfg entity.TeamConfig, dryRun, prune bool) (entity.TeamSyncResult, error)
+	DeactivateTeam(ctx context.Context, teamName string) ([]entity.PullRequest, error)
+	GetReviews(ctx context.Context, userID string) ([]entity.PullRequest, error)
+	// Reassign replaces oldUserID on the PR with newUserID, or with a
+	// teammate picked by the service when newUserID is empty.
+	Reassign(ctx context.Context, prID, oldUserID, newUserID string) (entity.PullRequest, string, error)
+	MergePR(ctx context.Context, prID string) (entity.PullRequest, error)
+	UserStats(ctx context.Context) ([]entity.UserStats, error)
+	PRStats(ctx context.Context) (*entity.PRStats, error)
+	TeamStats(ctx context.Context, root string) ([]entity.TeamStats, error)
+	Close()
+}
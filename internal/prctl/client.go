@@ -0,0 +1,235 @@
+package prctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const clientTimeout = 30 * time.Second
+
+// APIError is an error response of the service.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.Status)
+}
+
+// Client is a Backend that calls the HTTP API.
+type Client struct {
+	baseURL string
+	token   string
+	actor   string
+	http    *http.Client
+}
+
+// NewClient returns a client of the service at baseURL. token is sent as a
+// bearer token and actor as the X-User-ID header; both may be empty.
+func NewClient(baseURL, token, actor string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		actor:   actor,
+		http:    &http.Client{Timeout: clientTimeout},
+	}
+}
+
+type teamMember struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+func (c *Client) CreateTeam(ctx context.Context, teamName string, members []entity.User) (entity.Team, error) {
+	input := struct {
+		TeamName string       `json:"team_name"`
+		Members  []teamMember `json:"members"`
+	}{TeamName: teamName, Members: make([]teamMember, len(members))}
+	for i, m := range members {
+		input.Members[i] = teamMember{UserID: m.UserID, Username: m.Username, IsActive: m.IsActive}
+	}
+
+	var output struct {
+		Team entity.Team `json:"team"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/team/add", nil, input, &output); err != nil {
+		return entity.Team{}, fmt.Errorf("Client - CreateTeam - c.do: %w", err)
+	}
+	return output.Team, nil
+}
+
+func (c *Client) SyncTeams(ctx context.Context, cfg entity.TeamConfig, dryRun, prune bool) (entity.TeamSyncResult, error) {
+	query := url.Values{
+		"dry_run": {strconv.FormatBool(dryRun)},
+		"prune":   {strconv.FormatBool(prune)},
+	}
+
+	var output entity.TeamSyncResult
+	if err := c.do(ctx, http.MethodPost, "/team/sync", query, cfg, &output); err != nil {
+		return entity.TeamSyncResult{}, fmt.Errorf("Client - SyncTeams - c.do: %w", err)
+	}
+	return output, nil
+}
+
+func (c *Client) DeactivateTeam(ctx context.Context, teamName string) ([]entity.PullRequest, error) {
+	input := map[string]string{"team_name": teamName}
+
+	var output struct {
+		ReassignedPRs []entity.PullRequest `json:"reassigned_prs"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/team/deactivate", nil, input, &output); err != nil {
+		return nil, fmt.Errorf("Client - DeactivateTeam - c.do: %w", err)
+	}
+	return output.ReassignedPRs, nil
+}
+
+func (c *Client) GetReviews(ctx context.Context, userID string) ([]entity.PullRequest, error) {
+	var output struct {
+		PullRequests []entity.PullRequest `json:"pull_requests"`
+	}
+	query := url.Values{"user_id": {userID}}
+	if err := c.do(ctx, http.MethodGet, "/users/getReview", query, nil, &output); err != nil {
+		return nil, fmt.Errorf("Client - GetReviews - c.do: %w", err)
+	}
+	return output.PullRequests, nil
+}
+
+func (c *Client) Reassign(ctx context.Context, prID, oldUserID, newUserID string) (entity.PullRequest, string, error) {
+	input := map[string]string{"pull_request_id": prID, "old_user_id": oldUserID}
+	if newUserID != "" {
+		input["new_user_id"] = newUserID
+	}
+
+	var output struct {
+		PR         entity.PullRequest `json:"pr"`
+		ReplacedBy string             `json:"replaced_by"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/pullRequest/reassign", nil, input, &output); err != nil {
+		return entity.PullRequest{}, "", fmt.Errorf("Client - Reassign - c.do: %w", err)
+	}
+	return output.PR, output.ReplacedBy, nil
+}
+
+func (c *Client) MergePR(ctx context.Context, prID string) (entity.PullRequest, error) {
+	input := map[string]string{"pull_request_id": prID}
+
+	var output struct {
+		PR entity.PullRequest `json:"pr"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/pullRequest/merge", nil, input, &output); err != nil {
+		return entity.PullRequest{}, fmt.Errorf("Client - MergePR - c.do: %w", err)
+	}
+	return output.PR, nil
+}
+
+func (c *Client) UserStats(ctx context.Context) ([]entity.UserStats, error) {
+	var output struct {
+		Users []entity.UserStats `json:"users"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/stats/users", nil, nil, &output); err != nil {
+		return nil, fmt.Errorf("Client - UserStats - c.do: %w", err)
+	}
+	return output.Users, nil
+}
+
+func (c *Client) PRStats(ctx context.Context) (*entity.PRStats, error) {
+	var output entity.PRStats
+	if err := c.do(ctx, http.MethodGet, "/stats/prs", nil, nil, &output); err != nil {
+		return nil, fmt.Errorf("Client - PRStats - c.do: %w", err)
+	}
+	return &output, nil
+}
+
+func (c *Client) TeamStats(ctx context.Context, root string) ([]entity.TeamStats, error) {
+	var query url.Values
+	if root != "" {
+		query = url.Values{"root": {root}}
+	}
+
+	var output struct {
+		Teams []entity.TeamStats `json:"teams"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/stats/teams", query, nil, &output); err != nil {
+		return nil, fmt.Errorf("Client - TeamStats - c.do: %w", err)
+	}
+	return output.Teams, nil
+}
+
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
+
+// do sends input as the JSON body and decodes a successful response into output.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, input, output any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("http.NewRequest: %w", err)
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.actor != "" {
+		req.Header.Set("X-User-ID", c.actor)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeAPIError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	apiErr := &APIError{Status: resp.StatusCode, Code: "HTTP_ERROR", Message: resp.Status}
+
+	var output struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&output); err == nil && output.Error.Code != "" {
+		apiErr.Code = output.Error.Code
+		apiErr.Message = output.Error.Message
+	}
+
+	return apiErr
+}
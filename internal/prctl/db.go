@@ -0,0 +1,104 @@
+package prctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/repo/persistent"
+	"pr-reviewer-service/internal/usecase"
+	"pr-reviewer-service/pkg/postgres"
+)
+
+// DB is a Backend that runs the use cases against the database directly.
+// It bypasses authentication and access checks, so it is meant for operators
+// with database credentials when the API is unavailable.
+type DB struct {
+	pg    *postgres.Postgres
+	actor string
+	team  *usecase.TeamUseCase
+	sync  *usecase.TeamSyncUseCase
+	user  *usecase.UserUseCase
+	pr    *usecase.PullRequestUseCase
+	stats *usecase.StatsUseCase
+}
+
+// NewDB connects to the database at databaseURL. Changes are audited as made
+// by actor, which must not be empty.
+func NewDB(databaseURL, actor string) (*DB, error) {
+	if actor == "" {
+		return nil, errors.New("DB - NewDB - no actor to audit changes as")
+	}
+
+	pg, err := postgres.New(databaseURL, postgres.MaxPoolSize(1))
+	if err != nil {
+		return nil, fmt.Errorf("DB - NewDB - postgres.New: %w", err)
+	}
+
+	userRepo := persistent.NewUserRepo(pg)
+	teamRepo := persistent.NewTeamRepo(pg, userRepo)
+	prRepo := persistent.NewPullRequestRepo(pg)
+	auditUC := usecase.NewAuditUseCase(persistent.NewAuditRepo(pg))
+	prUC := usecase.NewPullRequestUseCase(pg, prRepo, userRepo, teamRepo, usecase.NewReviewerSelector(), auditUC, nil, nil)
+	teamUC := usecase.NewTeamUseCase(pg, teamRepo, userRepo, prUC, auditUC)
+	userUC := usecase.NewUserUseCase(pg, userRepo, prRepo, prUC, auditUC)
+
+	return &DB{
+		pg:    pg,
+		actor: actor,
+		team:  teamUC,
+		sync:  usecase.NewTeamSyncUseCase(pg, teamRepo, userRepo, teamUC, userUC),
+		user:  userUC,
+		pr:    prUC,
+		stats: usecase.NewStatsUseCase(prRepo, userRepo),
+	}, nil
+}
+
+func (d *DB) CreateTeam(ctx context.Context, teamName string, members []entity.User) (entity.Team, error) {
+	return d.team.CreateTeam(d.withActor(ctx), teamName, members)
+}
+
+func (d *DB) SyncTeams(ctx context.Context, cfg entity.TeamConfig, dryRun, prune bool) (entity.TeamSyncResult, error) {
+	return d.sync.Sync(d.withActor(ctx), cfg, dryRun, prune)
+}
+
+func (d *DB) DeactivateTeam(ctx context.Context, teamName string) ([]entity.PullRequest, error) {
+	return d.team.DeactivateTeamAndReassign(d.withActor(ctx), teamName)
+}
+
+func (d *DB) GetReviews(ctx context.Context, userID string) ([]entity.PullRequest, error) {
+	return d.user.GetReviews(ctx, userID)
+}
+
+func (d *DB) Reassign(ctx context.Context, prID, oldUserID, newUserID string) (entity.PullRequest, string, error) {
+	ctx = d.withActor(ctx)
+	if newUserID != "" {
+		pr, err := d.pr.ReassignReviewerTo(ctx, prID, oldUserID, newUserID)
+		return pr, newUserID, err
+	}
+	return d.pr.ReassignReviewer(ctx, prID, oldUserID)
+}
+
+func (d *DB) MergePR(ctx context.Context, prID string) (entity.PullRequest, error) {
+	return d.pr.MergePR(d.withActor(ctx), prID)
+}
+
+func (d *DB) UserStats(ctx context.Context) ([]entity.UserStats, error) {
+	return d.stats.GetUserStats(ctx)
+}
+
+func (d *DB) PRStats(ctx context.Context) (*entity.PRStats, error) {
+	return d.stats.GetPRStats(ctx)
+}
+
+func (d *DB) TeamStats(ctx context.Context, root string) ([]entity.TeamStats, error) {
+	return d.stats.GetTeamStats(ctx, root)
+}
+
+func (d *DB) Close() {
+	d.pg.Close()
+}
+
+func (d *DB) withActor(ctx context.Context) context.Context {
+	return entity.WithActor(ctx, d.actor)
+}
@@ -0,0 +1,118 @@
+package prctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pr-reviewer-service/internal/entity"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer writes command results as an aligned table or as indented JSON.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// print writes v as JSON, or the rows produced by table otherwise.
+func (p printer) print(v any, table func(tw io.Writer)) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+func row(w io.Writer, cells ...any) {
+	for i, cell := range cells {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, cell)
+	}
+	fmt.Fprintln(w)
+}
+
+func pullRequestTable(prs []entity.PullRequest) func(io.Writer) {
+	return func(w io.Writer) {
+		row(w, "PR_ID", "NAME", "AUTHOR", "STATUS", "REVIEWERS")
+		for _, pr := range prs {
+			row(w, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, list(pr.AssignedReviewers))
+		}
+	}
+}
+
+func teamTable(teams []entity.Team) func(io.Writer) {
+	return func(w io.Writer) {
+		row(w, "TEAM", "USER_ID", "USERNAME", "ACTIVE")
+		for _, team := range teams {
+			for _, m := range team.Members {
+				row(w, team.TeamName, m.UserID, m.Username, m.IsActive)
+			}
+		}
+	}
+}
+
+func teamSyncTable(result entity.TeamSyncResult) func(io.Writer) {
+	return func(w io.Writer) {
+		if len(result.Changes) == 0 {
+			fmt.Fprintln(w, "No changes.")
+			return
+		}
+
+		row(w, "ACTION", "TEAM", "USER_ID", "CHANGES")
+		for _, c := range result.Changes {
+			row(w, c.Action, c.TeamName, list([]string{c.UserID}), strings.Join(c.Changes, "; "))
+		}
+
+		if result.DryRun {
+			fmt.Fprintf(w, "\nDry run: %d change(s) not applied.\n", len(result.Changes))
+			return
+		}
+		fmt.Fprintf(w, "\nApplied %d change(s), reassigned %d review(s).\n", len(result.Changes), len(result.ReassignedPRs))
+	}
+}
+
+func userStatsTable(stats []entity.UserStats) func(io.Writer) {
+	return func(w io.Writer) {
+		row(w, "USER_ID", "USERNAME", "TOTAL", "OPEN", "MERGED")
+		for _, s := range stats {
+			row(w, s.UserID, s.Username, s.TotalAssigned, s.OpenAssigned, s.MergedAssigned)
+		}
+	}
+}
+
+func prStatsTable(stats *entity.PRStats) func(io.Writer) {
+	return func(w io.Writer) {
+		row(w, "TOTAL", "OPEN", "MERGED", "REVIEWERS")
+		row(w, stats.TotalPRs, stats.OpenPRs, stats.MergedPRs, stats.TotalReviewers)
+	}
+}
+
+func teamStatsTable(stats []entity.TeamStats) func(io.Writer) {
+	return func(w io.Writer) {
+		row(w, "TEAM", "PARENT", "SUB_TEAMS", "MEMBERS", "TOTAL_PRS", "OPEN_PRS", "MERGED_PRS", "OPEN_REVIEWS")
+		for _, s := range stats {
+			row(w, s.TeamName, list([]string{s.ParentTeam}), s.SubTeams, s.Memberships, s.TotalPRs, s.OpenPRs, s.MergedPRs, s.OpenReviews)
+		}
+	}
+}
+
+// list joins values for a table cell and marks an empty cell with "-".
+func list(values []string) string {
+	joined := strings.Join(values, ",")
+	if joined == "" {
+		return "-"
+	}
+	return joined
+}
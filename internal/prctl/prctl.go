@@ -0,0 +1,349 @@
+package prctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"pr-reviewer-service/internal/entity"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUsage means the command line is invalid; the usage has been printed.
+var ErrUsage = errors.New("invalid usage")
+
+const defaultAddr = "http://localhost:8080"
+
+// session is what a command runs with.
+type session struct {
+	backend Backend
+	out     printer
+	stdin   io.Reader
+	stderr  io.Writer
+}
+
+type command struct {
+	group, name string
+	usage       string
+	run         func(ctx context.Context, s *session, args []string) error
+}
+
+var commands = []command{
+	{"team", "create", "-f FILE", teamCreate},
+	{"team", "sync", "-f FILE [-dry-run] [-prune]", teamSync},
+	{"team", "deactivate", "TEAM", teamDeactivate},
+	{"user", "reviews", "USER_ID", userReviews},
+	{"pr", "reassign", "[-to USER_ID] PR_ID OLD_USER_ID", prReassign},
+	{"pr", "merge", "PR_ID", prMerge},
+	{"stats", "users", "", statsUsers},
+	{"stats", "prs", "", statsPRs},
+	{"stats", "teams", "[-root TEAM]", statsTeams},
+}
+
+// Run executes the command line args, without the program name.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", envOr("PRCTL_ADDR", defaultAddr), "base `URL` of the service API")
+	token := fs.String("token", os.Getenv("PRCTL_TOKEN"), "API `token` sent as a bearer token")
+	dbURL := fs.String("db", os.Getenv("PRCTL_DB_URL"), "Postgres `URL`; when set, commands run against the database instead of the API")
+	actor := fs.String("as", os.Getenv("PRCTL_ACTOR"), "`USER_ID` to act as, recorded in the audit log; required with -db, over the API the token needs the impersonate scope")
+	format := fs.String("o", formatTable, "output `format`: table or json")
+	fs.Usage = func() { usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return ErrUsage
+	}
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return ErrUsage
+	}
+	// Without the API there is no token to attribute changes to
+	if *dbURL != "" && *actor == "" {
+		fmt.Fprintln(stderr, "-db requires -as (PRCTL_ACTOR): changes are recorded in the audit log as made by that user")
+		return ErrUsage
+	}
+
+	cmd, ok := findCommand(fs.Args())
+	if !ok {
+		usage(fs)
+		return ErrUsage
+	}
+
+	var backend Backend
+	if *dbURL != "" {
+		db, err := NewDB(*dbURL, *actor)
+		if err != nil {
+			return err
+		}
+		backend = db
+	} else {
+		backend = NewClient(*addr, *token, *actor)
+	}
+	defer backend.Close()
+
+	s := &session{
+		backend: backend,
+		out:     printer{w: stdout, format: *format},
+		stdin:   stdin,
+		stderr:  stderr,
+	}
+	return cmd.run(ctx, s, fs.Args()[2:])
+}
+
+func findCommand(args []string) (command, bool) {
+	if len(args) < 2 {
+		return command{}, false
+	}
+	for _, cmd := range commands {
+		if cmd.group == args[0] && cmd.name == args[1] {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: prctl [flags] COMMAND [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintln(w, "  "+strings.TrimSpace(cmd.group+" "+cmd.name+" "+cmd.usage))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
+
+// commandFlags parses the flags of a command and checks the number of its
+// positional arguments.
+func commandFlags(s *session, name string, args []string, nargs int, define func(fs *flag.FlagSet)) ([]string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(s.stderr)
+	if define != nil {
+		define(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, ErrUsage
+	}
+	if fs.NArg() != nargs {
+		fmt.Fprintf(s.stderr, "%s: expected %d argument(s), got %d\n", name, nargs, fs.NArg())
+		return nil, ErrUsage
+	}
+	return fs.Args(), nil
+}
+
+// readTeams reads a YAML or JSON team config from path, or from stdin when
+// path is "-".
+func readTeams(s *session, path string) (entity.TeamConfig, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(s.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return entity.TeamConfig{}, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var cfg entity.TeamConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return entity.TeamConfig{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if _, err := cfg.Validate(); err != nil {
+		return entity.TeamConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func teamCreate(ctx context.Context, s *session, args []string) error {
+	var path string
+	if _, err := commandFlags(s, "team create", args, 0, func(fs *flag.FlagSet) {
+		fs.StringVar(&path, "f", "", "YAML or JSON `FILE` with the teams, - for stdin")
+	}); err != nil {
+		return err
+	}
+	if path == "" {
+		fmt.Fprintln(s.stderr, "team create: -f is required")
+		return ErrUsage
+	}
+
+	cfg, err := readTeams(s, path)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Teams) == 0 {
+		return fmt.Errorf("%s: no teams defined", path)
+	}
+
+	created := make([]entity.Team, 0, len(cfg.Teams))
+	for _, t := range cfg.Teams {
+		members := make([]entity.User, len(t.Members))
+		for i, m := range t.Members {
+			members[i] = entity.User{UserID: m.UserID, Username: m.Username, IsActive: m.IsActive == nil || *m.IsActive}
+		}
+
+		team, err := s.backend.CreateTeam(ctx, t.TeamName, members)
+		if err != nil {
+			if len(created) > 0 {
+				s.out.print(created, teamTable(created))
+			}
+			return fmt.Errorf("create team %s: %w", t.TeamName, err)
+		}
+		created = append(created, team)
+	}
+
+	return s.out.print(created, teamTable(created))
+}
+
+func teamSync(ctx context.Context, s *session, args []string) error {
+	var path string
+	var dryRun, prune bool
+	if _, err := commandFlags(s, "team sync", args, 0, func(fs *flag.FlagSet) {
+		fs.StringVar(&path, "f", "", "YAML or JSON `FILE` with all teams, - for stdin")
+		fs.BoolVar(&dryRun, "dry-run", false, "only print the plan")
+		fs.BoolVar(&prune, "prune", false, "archive teams missing from the file")
+	}); err != nil {
+		return err
+	}
+	if path == "" {
+		fmt.Fprintln(s.stderr, "team sync: -f is required")
+		return ErrUsage
+	}
+
+	cfg, err := readTeams(s, path)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.backend.SyncTeams(ctx, cfg, dryRun, prune)
+	if err != nil {
+		return err
+	}
+
+	return s.out.print(result, teamSyncTable(result))
+}
+
+func teamDeactivate(ctx context.Context, s *session, args []string) error {
+	args, err := commandFlags(s, "team deactivate", args, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	reassigned, err := s.backend.DeactivateTeam(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	output := map[string]any{"team_name": args[0], "reassigned_prs": reassigned}
+	return s.out.print(output, pullRequestTable(reassigned))
+}
+
+func userReviews(ctx context.Context, s *session, args []string) error {
+	args, err := commandFlags(s, "user reviews", args, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	prs, err := s.backend.GetReviews(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	output := map[string]any{"user_id": args[0], "pull_requests": prs}
+	return s.out.print(output, pullRequestTable(prs))
+}
+
+func prReassign(ctx context.Context, s *session, args []string) error {
+	var to string
+	args, err := commandFlags(s, "pr reassign", args, 2, func(fs *flag.FlagSet) {
+		fs.StringVar(&to, "to", "", "replacement `USER_ID`; a teammate is picked when empty")
+	})
+	if err != nil {
+		return err
+	}
+
+	pr, replacedBy, err := s.backend.Reassign(ctx, args[0], args[1], to)
+	if err != nil {
+		return err
+	}
+
+	output := map[string]any{"pr": pr, "replaced_by": replacedBy}
+	return s.out.print(output, func(w io.Writer) {
+		pullRequestTable([]entity.PullRequest{pr})(w)
+		fmt.Fprintf(w, "\n%s replaced by %s\n", args[1], replacedBy)
+	})
+}
+
+func prMerge(ctx context.Context, s *session, args []string) error {
+	args, err := commandFlags(s, "pr merge", args, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	pr, err := s.backend.MergePR(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return s.out.print(map[string]any{"pr": pr}, pullRequestTable([]entity.PullRequest{pr}))
+}
+
+func statsUsers(ctx context.Context, s *session, args []string) error {
+	if _, err := commandFlags(s, "stats users", args, 0, nil); err != nil {
+		return err
+	}
+
+	stats, err := s.backend.UserStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.out.print(map[string]any{"users": stats}, userStatsTable(stats))
+}
+
+func statsPRs(ctx context.Context, s *session, args []string) error {
+	if _, err := commandFlags(s, "stats prs", args, 0, nil); err != nil {
+		return err
+	}
+
+	stats, err := s.backend.PRStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.out.print(stats, prStatsTable(stats))
+}
+
+func statsTeams(ctx context.Context, s *session, args []string) error {
+	var root string
+	if _, err := commandFlags(s, "stats teams", args, 0, func(fs *flag.FlagSet) {
+		fs.StringVar(&root, "root", "", "only the sub-tree of `TEAM`")
+	}); err != nil {
+		return err
+	}
+
+	stats, err := s.backend.TeamStats(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	return s.out.print(map[string]any{"teams": stats}, teamStatsTable(stats))
+}
+
+func envOr(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,172 @@
+package prctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/prctl"
+)
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func run(t *testing.T, srv *httptest.Server, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-addr", srv.URL, "-token", "secret"}, args...)
+	err := prctl.Run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestTeamCreate(t *testing.T) {
+	var got map[string]any
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/team/add" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"team": got})
+	})
+
+	file := `
+teams:
+  - team_name: backend
+    members:
+      - user_id: u1
+        username: Alice
+      - user_id: u2
+        username: Bob
+        is_active: false
+`
+	out, err := run(t, srv, file, "team", "create", "-f", "-")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	members := got["members"].([]any)
+	if got["team_name"] != "backend" || len(members) != 2 {
+		t.Fatalf("request body = %v", got)
+	}
+	if active := members[0].(map[string]any)["is_active"]; active != true {
+		t.Errorf("u1 is_active = %v, want the default true", active)
+	}
+	if active := members[1].(map[string]any)["is_active"]; active != false {
+		t.Errorf("u2 is_active = %v", active)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TEAM") || !strings.Contains(lines[2], "u2") {
+		t.Errorf("output =\n%s", out)
+	}
+}
+
+func TestReassignJSON(t *testing.T) {
+	var got map[string]string
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(map[string]any{
+			"pr":          map[string]any{"pull_request_id": "pr-1", "assigned_reviewers": []string{"u3"}},
+			"replaced_by": "u3",
+		})
+	})
+
+	out, err := run(t, srv, "", "-o", "json", "pr", "reassign", "-to", "u3", "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := map[string]string{"pull_request_id": "pr-1", "old_user_id": "u2", "new_user_id": "u3"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("request %s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	var output struct {
+		ReplacedBy string `json:"replaced_by"`
+	}
+	if err := json.Unmarshal([]byte(out), &output); err != nil || output.ReplacedBy != "u3" {
+		t.Errorf("output = %s (%v)", out, err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":{"code":"PR_CLOSED","message":"cannot merge closed PR"}}`))
+	})
+
+	_, err := run(t, srv, "", "pr", "merge", "pr-1")
+
+	var apiErr *prctl.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "PR_CLOSED" || apiErr.Status != http.StatusConflict {
+		t.Errorf("err = %v, want a PR_CLOSED API error", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	})
+
+	for _, args := range [][]string{
+		{"team"},
+		{"pr", "unknown"},
+		{"pr", "merge"},
+		{"-o", "yaml", "stats", "prs"},
+	} {
+		if _, err := run(t, srv, "", args...); !errors.Is(err, prctl.ErrUsage) {
+			t.Errorf("%v: err = %v, want ErrUsage", args, err)
+		}
+	}
+}
+
+func TestTeamSyncDryRun(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/team/sync" || r.URL.Query().Get("dry_run") != "true" || r.URL.Query().Get("prune") != "false" {
+			t.Errorf("request = %s", r.URL)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"dry_run": true,
+			"changes": []map[string]any{{"action": "remove_member", "team_name": "backend", "user_id": "u3"}},
+		})
+	})
+
+	out, err := run(t, srv, "teams: [{team_name: backend}]", "team", "sync", "-dry-run", "-f", "-")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(out, "remove_member  backend  u3") || !strings.Contains(out, "Dry run: 1 change(s) not applied.") {
+		t.Errorf("output =\n%s", out)
+	}
+}
+
+func TestDBRequiresActor(t *testing.T) {
+	t.Setenv("PRCTL_ACTOR", "")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-db", "postgres://localhost/unused", "pr", "merge", "pr-1"}
+	err := prctl.Run(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+
+	if !errors.Is(err, prctl.ErrUsage) {
+		t.Fatalf("Run error = %v, want ErrUsage", err)
+	}
+	if !strings.Contains(stderr.String(), "-as") {
+		t.Errorf("stderr = %q, want it to mention -as", stderr.String())
+	}
+}
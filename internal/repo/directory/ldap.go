@@ -0,0 +1,177 @@
+// Package directory reads users and groups from external directories.
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"pr-reviewer-service/internal/entity"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const ldapPageSize = 500
+
+// LDAPConfig selects the users and groups to read and maps their attributes.
+type LDAPConfig struct {
+	// URL is ldap://host:389 or ldaps://host:636
+	URL      string
+	StartTLS bool
+	// BindDN and BindPassword authenticate the search; empty means anonymous
+	BindDN       string
+	BindPassword string
+	Timeout      time.Duration
+
+	UserBaseDN  string
+	UserFilter  string
+	GroupBaseDN string
+	GroupFilter string
+
+	// UserIDAttribute holds users.user_id, e.g. uid or sAMAccountName
+	UserIDAttribute string
+	// UsernameAttribute holds the display name, e.g. cn or displayName
+	UsernameAttribute string
+	// GroupNameAttribute holds the team name
+	GroupNameAttribute string
+	// MemberAttribute lists group members by DN (member, uniqueMember) or by
+	// user id (memberUid)
+	MemberAttribute string
+}
+
+// LDAP reads a directory from an LDAP server.
+type LDAP struct {
+	cfg LDAPConfig
+}
+
+func NewLDAP(cfg LDAPConfig) *LDAP {
+	return &LDAP{cfg: cfg}
+}
+
+// Fetch reads the users and groups. Group members that are not found among
+// the users are dropped, as are users without a user id.
+func (d *LDAP) Fetch(ctx context.Context) (entity.Directory, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return entity.Directory{}, err
+	}
+	defer conn.Close()
+
+	// The client has no context support: closing the connection aborts a search
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	var dir entity.Directory
+	byDN := make(map[string]string)
+	byID := make(map[string]bool)
+
+	users, err := d.search(conn, d.cfg.UserBaseDN, d.cfg.UserFilter, d.cfg.UserIDAttribute, d.cfg.UsernameAttribute)
+	if err != nil {
+		return entity.Directory{}, fmt.Errorf("LDAP - Fetch - users: %w", d.ctxErr(ctx, err))
+	}
+	for _, entry := range users {
+		userID := entry.GetAttributeValue(d.cfg.UserIDAttribute)
+		if userID == "" || byID[userID] {
+			continue
+		}
+		username := entry.GetAttributeValue(d.cfg.UsernameAttribute)
+		if username == "" {
+			username = userID
+		}
+
+		byID[userID] = true
+		byDN[normalizeDN(entry.DN)] = userID
+		dir.Users = append(dir.Users, entity.DirectoryUser{UserID: userID, Username: username})
+	}
+
+	groups, err := d.search(conn, d.cfg.GroupBaseDN, d.cfg.GroupFilter, d.cfg.GroupNameAttribute, d.cfg.MemberAttribute)
+	if err != nil {
+		return entity.Directory{}, fmt.Errorf("LDAP - Fetch - groups: %w", d.ctxErr(ctx, err))
+	}
+	for _, entry := range groups {
+		name := entry.GetAttributeValue(d.cfg.GroupNameAttribute)
+		if name == "" {
+			continue
+		}
+
+		group := entity.DirectoryGroup{TeamName: name, UserIDs: []string{}}
+		for _, member := range entry.GetAttributeValues(d.cfg.MemberAttribute) {
+			if userID, ok := byDN[normalizeDN(member)]; ok {
+				group.UserIDs = append(group.UserIDs, userID)
+			} else if byID[member] {
+				group.UserIDs = append(group.UserIDs, member)
+			}
+		}
+		dir.Groups = append(dir.Groups, group)
+	}
+
+	return dir, nil
+}
+
+func (d *LDAP) connect() (*ldap.Conn, error) {
+	u, err := url.Parse(d.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("LDAP - connect - url.Parse: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname()}
+
+	conn, err := ldap.DialURL(d.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("LDAP - connect - ldap.DialURL: %w", err)
+	}
+	conn.SetTimeout(d.cfg.Timeout)
+
+	if d.cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP - connect - conn.StartTLS: %w", err)
+		}
+	}
+
+	if d.cfg.BindDN != "" {
+		if err := conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP - connect - conn.Bind: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (d *LDAP) search(conn *ldap.Conn, baseDN, filter string, attributes ...string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(
+		baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(d.cfg.Timeout.Seconds()), false,
+		filter, attributes, nil,
+	)
+
+	result, err := conn.SearchWithPaging(req, ldapPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// ctxErr reports the cancellation of ctx rather than the closed connection.
+func (d *LDAP) ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// normalizeDN makes equal DNs compare equal: attribute types and values are
+// matched case-insensitively and spacing is dropped.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	return strings.ToLower(parsed.String())
+}
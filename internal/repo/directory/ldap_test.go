@@ -0,0 +1,210 @@
+package directory_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/repo/directory"
+)
+
+type ldapEntry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+// ldapServer is an in-process LDAP stand-in. It accepts one bind DN and
+// answers searches with the entries under the base DN, recording the filters.
+type ldapServer struct {
+	addr     string
+	bindDN   string
+	password string
+	entries  []ldapEntry
+
+	mu      sync.Mutex
+	filters []string
+}
+
+func newLDAPServer(t *testing.T, bindDN, password string, entries ...ldapEntry) *ldapServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &ldapServer{addr: l.Addr().String(), bindDN: bindDN, password: password, entries: entries}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *ldapServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := ldap.LDAPResultSuccess
+			if dn != s.bindDN || password != s.password {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			s.write(conn, id, result(ldap.ApplicationBindResponse, code))
+
+		case ldap.ApplicationSearchRequest:
+			base := strings.ToLower(op.Children[0].Data.String())
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.filters = append(s.filters, filter)
+			s.mu.Unlock()
+
+			for _, e := range s.entries {
+				if strings.HasSuffix(strings.ToLower(e.dn), base) {
+					s.write(conn, id, searchEntry(e))
+				}
+			}
+			s.write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *ldapServer) write(w io.Writer, id int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	packet.AppendChild(op)
+	w.Write(packet.Bytes())
+}
+
+func result(tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return op
+}
+
+func searchEntry(e ldapEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+func config(addr string) directory.LDAPConfig {
+	return directory.LDAPConfig{
+		URL:                "ldap://" + addr,
+		BindDN:             "cn=sync,dc=example,dc=com",
+		BindPassword:       "secret",
+		Timeout:            5 * time.Second,
+		UserBaseDN:         "ou=people,dc=example,dc=com",
+		UserFilter:         "(objectClass=person)",
+		GroupBaseDN:        "ou=groups,dc=example,dc=com",
+		GroupFilter:        "(objectClass=groupOfNames)",
+		UserIDAttribute:    "uid",
+		UsernameAttribute:  "cn",
+		GroupNameAttribute: "cn",
+		MemberAttribute:    "member",
+	}
+}
+
+func TestLDAPFetch(t *testing.T) {
+	srv := newLDAPServer(t, "cn=sync,dc=example,dc=com", "secret",
+		ldapEntry{"uid=alice,ou=people,dc=example,dc=com", map[string][]string{"uid": {"alice"}, "cn": {"Alice"}}},
+		ldapEntry{"uid=bob,ou=people,dc=example,dc=com", map[string][]string{"uid": {"bob"}}},
+		ldapEntry{"cn=printer,ou=people,dc=example,dc=com", map[string][]string{"cn": {"Printer"}}},
+		ldapEntry{"cn=backend,ou=groups,dc=example,dc=com", map[string][]string{
+			"cn": {"backend"},
+			// DNs match case-insensitively; unknown members are dropped
+			"member": {"uid=alice,ou=people,dc=example,dc=com", "UID=Bob, OU=People, DC=example, DC=com", "uid=gone,ou=people,dc=example,dc=com"},
+		}},
+		ldapEntry{"cn=platform,ou=groups,dc=example,dc=com", map[string][]string{"cn": {"platform"}, "member": {"bob"}}},
+	)
+
+	dir, err := directory.NewLDAP(config(srv.addr)).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	want := entity.Directory{
+		Users: []entity.DirectoryUser{
+			{UserID: "alice", Username: "Alice"},
+			{UserID: "bob", Username: "bob"},
+		},
+		Groups: []entity.DirectoryGroup{
+			{TeamName: "backend", UserIDs: []string{"alice", "bob"}},
+			{TeamName: "platform", UserIDs: []string{"bob"}},
+		},
+	}
+	if !reflect.DeepEqual(dir, want) {
+		t.Errorf("directory =\n%+v\nwant\n%+v", dir, want)
+	}
+
+	wantFilters := []string{"(objectClass=person)", "(objectClass=groupOfNames)"}
+	if !reflect.DeepEqual(srv.filters, wantFilters) {
+		t.Errorf("filters = %v, want %v", srv.filters, wantFilters)
+	}
+}
+
+func TestLDAPFetchBindError(t *testing.T) {
+	srv := newLDAPServer(t, "cn=sync,dc=example,dc=com", "other")
+
+	_, err := directory.NewLDAP(config(srv.addr)).Fetch(context.Background())
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		t.Errorf("err = %v, want invalid credentials", err)
+	}
+}
+
+func TestLDAPFetchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	srv := newLDAPServer(t, "cn=sync,dc=example,dc=com", "secret")
+	if _, err := directory.NewLDAP(config(srv.addr)).Fetch(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
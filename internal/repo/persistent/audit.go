@@ -0,0 +1,115 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/pkg/postgres"
+)
+
+// AuditRepo stores the audit log. The log is append-only, which a trigger
+// enforces: entries are never updated or deleted, except for the
+// anonymisation done by UserRepo.Erase.
+type AuditRepo struct {
+	*postgres.Postgres
+}
+
+func NewAuditRepo(pg *postgres.Postgres) *AuditRepo {
+	return &AuditRepo{pg}
+}
+
+func (r *AuditRepo) Create(ctx context.Context, entry entity.AuditEntry) error {
+	sql, args, err := r.Builder.
+		Insert("audit_log").
+		Columns("actor_id", "actor_token_id", "action", "entity_type", "entity_id", "before", "after", "request_id", "created_at").
+		Values(
+			nullString(entry.ActorID),
+			nullInt64(entry.ActorTokenID),
+			entry.Action,
+			entry.EntityType,
+			entry.EntityID,
+			nullJSON(entry.Before),
+			nullJSON(entry.After),
+			nullString(entry.RequestID),
+			entry.CreatedAt,
+		).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("AuditRepo - Create - r.Builder: %w", err)
+	}
+
+	if _, err := r.DB(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("AuditRepo - Create - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// List returns a page of entries matching filter, newest first, and the total
+// number of matching entries.
+func (r *AuditRepo) List(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, int, error) {
+	builder := r.Builder.
+		Select("audit_id", "COALESCE(actor_id, '')", "COALESCE(actor_token_id, 0)", "action", "entity_type", "entity_id", "before", "after",
+			"COALESCE(request_id, '')", "created_at", "COUNT(*) OVER ()").
+		From("audit_log")
+
+	if filter.ActorID != "" {
+		builder = builder.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		builder = builder.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		builder = builder.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		builder = builder.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Since != nil {
+		builder = builder.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		builder = builder.Where("created_at < ?", *filter.Until)
+	}
+
+	sql, args, err := builder.
+		OrderBy("created_at DESC", "audit_id DESC").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("AuditRepo - List - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("AuditRepo - List - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []entity.AuditEntry{}
+	total := 0
+	for rows.Next() {
+		var e entity.AuditEntry
+		if err := rows.Scan(
+			&e.AuditID,
+			&e.ActorID,
+			&e.ActorTokenID,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&e.Before,
+			&e.After,
+			&e.RequestID,
+			&e.CreatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("AuditRepo - List - rows.Scan: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, total, nil
+}
@@ -1,11 +1,19 @@
 package persistent
 
-import "pr-reviewer-service/pkg/postgres"
+import (
+	"encoding/json"
+	"pr-reviewer-service/pkg/postgres"
+	"strings"
+)
 
 type Repositories struct {
 	User        *UserRepo
 	Team        *TeamRepo
 	PullRequest *PullRequestRepo
+	Token       *TokenRepo
+	Audit       *AuditRepo
+	RateLimit   *RateLimitRepo
+	Schema      *SchemaRepo
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
@@ -17,5 +25,41 @@ func NewRepositories(pg *postgres.Postgres) *Repositories {
 		User:        userRepo,
 		Team:        teamRepo,
 		PullRequest: prRepo,
+		Token:       NewTokenRepo(pg),
+		Audit:       NewAuditRepo(pg),
+		RateLimit:   NewRateLimitRepo(pg),
+		Schema:      NewSchemaRepo(pg),
 	}
 }
+
+// nullString maps an empty string to SQL NULL.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullInt64 maps zero to SQL NULL.
+func nullInt64(n int64) *int64 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
+// nullJSON maps an empty JSON document to SQL NULL.
+func nullJSON(raw json.RawMessage) *string {
+	if len(raw) == 0 {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes LIKE wildcards so s is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
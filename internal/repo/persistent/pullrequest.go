@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/pkg/postgres"
+	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -17,17 +19,17 @@ func NewPullRequestRepo(pg *postgres.Postgres) *PullRequestRepo {
 	return &PullRequestRepo{pg}
 }
 
-func (r *PullRequestRepo) Create(ctx context.Context, pr entity.PullRequest) error {
-	tx, err := r.Pool.Begin(ctx)
+func (r *PullRequestRepo) Create(ctx context.Context, pr entity.PullRequest, meta entity.AssignmentMeta) error {
+	tx, err := r.DB(ctx).Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("PullRequestRepo - Create - r.Pool.Begin: %w", err)
+		return fmt.Errorf("PullRequestRepo - Create - r.DB.Begin: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
 	sql, args, err := r.Builder.
 		Insert("pull_requests").
-		Columns("pull_request_id", "pull_request_name", "author_id", "status", "created_at").
-		Values(pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt).
+		Columns("pull_request_id", "pull_request_name", "author_id", "status", "created_at", "updated_at").
+		Values(pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt, pr.CreatedAt).
 		ToSql()
 
 	if err != nil {
@@ -39,21 +41,14 @@ func (r *PullRequestRepo) Create(ctx context.Context, pr entity.PullRequest) err
 		return fmt.Errorf("PullRequestRepo - Create - tx.Exec: %w", err)
 	}
 
-	for _, reviewerID := range pr.AssignedReviewers {
-		sql, args, err := r.Builder.
-			Insert("pr_reviewers").
-			Columns("pull_request_id", "reviewer_id").
-			Values(pr.PullRequestID, reviewerID).
-			ToSql()
+	added, _, events := entity.DiffReviewers(nil, pr, meta)
 
-		if err != nil {
-			return fmt.Errorf("PullRequestRepo - Create - r.Builder (reviewers): %w", err)
-		}
+	if err := r.insertReviewers(ctx, tx, pr, added); err != nil {
+		return fmt.Errorf("PullRequestRepo - Create - %w", err)
+	}
 
-		_, err = tx.Exec(ctx, sql, args...)
-		if err != nil {
-			return fmt.Errorf("PullRequestRepo - Create - tx.Exec (reviewers): %w", err)
-		}
+	if err := r.insertEvents(ctx, tx, events); err != nil {
+		return fmt.Errorf("PullRequestRepo - Create - %w", err)
 	}
 
 	return tx.Commit(ctx)
@@ -61,7 +56,7 @@ func (r *PullRequestRepo) Create(ctx context.Context, pr entity.PullRequest) err
 
 func (r *PullRequestRepo) GetByID(ctx context.Context, prID string) (entity.PullRequest, error) {
 	sql, args, err := r.Builder.
-		Select("pull_request_id", "pull_request_name", "author_id", "status", "created_at", "merged_at").
+		Select("pull_request_id", "pull_request_name", "author_id", "status", "created_at", "updated_at", "merged_at", "closed_at", "stale_at").
 		From("pull_requests").
 		Where("pull_request_id = ?", prID).
 		ToSql()
@@ -71,54 +66,71 @@ func (r *PullRequestRepo) GetByID(ctx context.Context, prID string) (entity.Pull
 	}
 
 	var pr entity.PullRequest
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+	err = r.DB(ctx).QueryRow(ctx, sql, args...).Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.CreatedAt,
+		&pr.UpdatedAt,
 		&pr.MergedAt,
+		&pr.ClosedAt,
+		&pr.StaleAt,
 	)
 
 	if err == pgx.ErrNoRows {
 		return entity.PullRequest{}, entity.ErrNotFound
 	}
 	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - r.Pool.QueryRow: %w", err)
+		return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - r.DB.QueryRow: %w", err)
 	}
 
 	// Get reviewers
 	reviewerSQL, reviewerArgs, err := r.Builder.
-		Select("reviewer_id").
+		Select("reviewer_id", "COALESCE(source_team, '')", "approved_at IS NOT NULL").
 		From("pr_reviewers").
 		Where("pull_request_id = ?", prID).
+		OrderBy("assigned_at", "reviewer_id").
 		ToSql()
 
 	if err != nil {
 		return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - r.Builder (reviewers): %w", err)
 	}
 
-	rows, err := r.Pool.Query(ctx, reviewerSQL, reviewerArgs...)
+	rows, err := r.DB(ctx).Query(ctx, reviewerSQL, reviewerArgs...)
 	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - r.Pool.Query: %w", err)
+		return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - r.DB.Query: %w", err)
 	}
 	defer rows.Close()
 
+	pr.ReviewerTeams = make(map[string]string)
 	for rows.Next() {
-		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
+		var (
+			reviewerID, sourceTeam string
+			approved               bool
+		)
+		if err := rows.Scan(&reviewerID, &sourceTeam, &approved); err != nil {
 			return entity.PullRequest{}, fmt.Errorf("PullRequestRepo - GetByID - rows.Scan: %w", err)
 		}
 		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		if sourceTeam != "" {
+			pr.ReviewerTeams[reviewerID] = sourceTeam
+		}
+		if approved {
+			pr.ApprovedBy = append(pr.ApprovedBy, reviewerID)
+		}
 	}
 
 	return pr, nil
 }
 
-func (r *PullRequestRepo) Update(ctx context.Context, pr entity.PullRequest) error {
-	tx, err := r.Pool.Begin(ctx)
+// Update saves the PR state and applies the reviewer list as a diff, so reviewers
+// that stay assigned keep their original assigned_at. Every reviewer change is
+// appended to the PR's assignment timeline with the given meta.
+func (r *PullRequestRepo) Update(ctx context.Context, pr entity.PullRequest, meta entity.AssignmentMeta) error {
+	tx, err := r.DB(ctx).Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("PullRequestRepo - Update - r.Pool.Begin: %w", err)
+		return fmt.Errorf("PullRequestRepo - Update - r.DB.Begin: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
@@ -126,6 +138,9 @@ func (r *PullRequestRepo) Update(ctx context.Context, pr entity.PullRequest) err
 		Update("pull_requests").
 		Set("status", pr.Status).
 		Set("merged_at", pr.MergedAt).
+		Set("closed_at", pr.ClosedAt).
+		Set("stale_at", pr.StaleAt).
+		Set("updated_at", pr.UpdatedAt).
 		Where("pull_request_id = ?", pr.PullRequestID).
 		ToSql()
 
@@ -138,40 +153,175 @@ func (r *PullRequestRepo) Update(ctx context.Context, pr entity.PullRequest) err
 		return fmt.Errorf("PullRequestRepo - Update - tx.Exec: %w", err)
 	}
 
-	// Delete old reviewers
-	deleteSQL, deleteArgs, err := r.Builder.
-		Delete("pr_reviewers").
-		Where("pull_request_id = ?", pr.PullRequestID).
+	// The row lock taken by the UPDATE above serialises concurrent reviewer diffs
+	current, err := r.getReviewerIDs(ctx, tx, pr.PullRequestID)
+	if err != nil {
+		return fmt.Errorf("PullRequestRepo - Update - %w", err)
+	}
+
+	added, removed, events := entity.DiffReviewers(current, pr, meta)
+
+	if len(removed) > 0 {
+		deleteSQL, deleteArgs, err := r.Builder.
+			Delete("pr_reviewers").
+			Where(squirrel.Eq{"pull_request_id": pr.PullRequestID, "reviewer_id": removed}).
+			ToSql()
+
+		if err != nil {
+			return fmt.Errorf("PullRequestRepo - Update - r.Builder (delete): %w", err)
+		}
+
+		_, err = tx.Exec(ctx, deleteSQL, deleteArgs...)
+		if err != nil {
+			return fmt.Errorf("PullRequestRepo - Update - tx.Exec (delete): %w", err)
+		}
+	}
+
+	if err := r.insertReviewers(ctx, tx, pr, added); err != nil {
+		return fmt.Errorf("PullRequestRepo - Update - %w", err)
+	}
+
+	if err := r.insertEvents(ctx, tx, events); err != nil {
+		return fmt.Errorf("PullRequestRepo - Update - %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PullRequestRepo) getReviewerIDs(ctx context.Context, tx pgx.Tx, prID string) ([]string, error) {
+	sql, args, err := r.Builder.
+		Select("reviewer_id").
+		From("pr_reviewers").
+		Where("pull_request_id = ?", prID).
+		OrderBy("assigned_at", "reviewer_id").
 		ToSql()
 
 	if err != nil {
-		return fmt.Errorf("PullRequestRepo - Update - r.Builder (delete): %w", err)
+		return nil, fmt.Errorf("getReviewerIDs - r.Builder: %w", err)
 	}
 
-	_, err = tx.Exec(ctx, deleteSQL, deleteArgs...)
+	rows, err := tx.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("PullRequestRepo - Update - tx.Exec (delete): %w", err)
+		return nil, fmt.Errorf("getReviewerIDs - tx.Query: %w", err)
 	}
+	defer rows.Close()
 
-	// Insert new reviewers
-	for _, reviewerID := range pr.AssignedReviewers {
-		insertSQL, insertArgs, err := r.Builder.
+	var reviewers []string
+	for rows.Next() {
+		var reviewerID string
+		if err := rows.Scan(&reviewerID); err != nil {
+			return nil, fmt.Errorf("getReviewerIDs - rows.Scan: %w", err)
+		}
+		reviewers = append(reviewers, reviewerID)
+	}
+
+	return reviewers, rows.Err()
+}
+
+func (r *PullRequestRepo) insertReviewers(ctx context.Context, tx pgx.Tx, pr entity.PullRequest, reviewers []string) error {
+	for _, reviewerID := range reviewers {
+		sql, args, err := r.Builder.
 			Insert("pr_reviewers").
-			Columns("pull_request_id", "reviewer_id").
-			Values(pr.PullRequestID, reviewerID).
+			Columns("pull_request_id", "reviewer_id", "source_team").
+			Values(pr.PullRequestID, reviewerID, nullString(pr.ReviewerTeams[reviewerID])).
 			ToSql()
 
 		if err != nil {
-			return fmt.Errorf("PullRequestRepo - Update - r.Builder (insert): %w", err)
+			return fmt.Errorf("insertReviewers - r.Builder: %w", err)
 		}
 
-		_, err = tx.Exec(ctx, insertSQL, insertArgs...)
+		_, err = tx.Exec(ctx, sql, args...)
 		if err != nil {
-			return fmt.Errorf("PullRequestRepo - Update - tx.Exec (insert): %w", err)
+			return fmt.Errorf("insertReviewers - tx.Exec: %w", err)
 		}
 	}
 
-	return tx.Commit(ctx)
+	return nil
+}
+
+func (r *PullRequestRepo) insertEvents(ctx context.Context, tx pgx.Tx, events []entity.AssignmentEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := r.Builder.
+		Insert("pr_assignment_events").
+		Columns("pull_request_id", "event_type", "reviewer_id", "previous_reviewer_id", "source_team", "actor_id", "reason")
+
+	for _, e := range events {
+		builder = builder.Values(
+			e.PullRequestID,
+			e.EventType,
+			e.ReviewerID,
+			nullString(e.PreviousReviewerID),
+			nullString(e.SourceTeam),
+			nullString(e.ActorID),
+			e.Reason,
+		)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("insertEvents - r.Builder: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insertEvents - tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetAssignmentEvents returns the reviewer assignment timeline of a PR, oldest first.
+func (r *PullRequestRepo) GetAssignmentEvents(ctx context.Context, prID string) ([]entity.AssignmentEvent, error) {
+	sql, args, err := r.Builder.
+		Select(
+			"event_id",
+			"pull_request_id",
+			"event_type",
+			"reviewer_id",
+			"COALESCE(previous_reviewer_id, '')",
+			"COALESCE(source_team, '')",
+			"COALESCE(actor_id, '')",
+			"reason",
+			"created_at",
+		).
+		From("pr_assignment_events").
+		Where("pull_request_id = ?", prID).
+		OrderBy("event_id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetAssignmentEvents - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetAssignmentEvents - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []entity.AssignmentEvent{}
+	for rows.Next() {
+		var e entity.AssignmentEvent
+		if err := rows.Scan(
+			&e.EventID,
+			&e.PullRequestID,
+			&e.EventType,
+			&e.ReviewerID,
+			&e.PreviousReviewerID,
+			&e.SourceTeam,
+			&e.ActorID,
+			&e.Reason,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("PullRequestRepo - GetAssignmentEvents - rows.Scan: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
 }
 
 func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]entity.PullRequest, error) {
@@ -186,9 +336,9 @@ func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]e
 		return nil, fmt.Errorf("PullRequestRepo - GetByReviewer - r.Builder: %w", err)
 	}
 
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("PullRequestRepo - GetByReviewer - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("PullRequestRepo - GetByReviewer - r.DB.Query: %w", err)
 	}
 	defer rows.Close()
 
@@ -208,9 +358,9 @@ func (r *PullRequestRepo) Exists(ctx context.Context, prID string) (bool, error)
 	query := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`
 
 	var exists bool
-	err := r.Pool.QueryRow(ctx, query, prID).Scan(&exists)
+	err := r.DB(ctx).QueryRow(ctx, query, prID).Scan(&exists)
 	if err != nil {
-		return false, fmt.Errorf("PullRequestRepo - Exists - r.Pool.QueryRow: %w", err)
+		return false, fmt.Errorf("PullRequestRepo - Exists - r.DB.QueryRow: %w", err)
 	}
 
 	return exists, nil
@@ -231,9 +381,9 @@ func (r *PullRequestRepo) GetUserStats(ctx context.Context) ([]entity.UserStats,
 		ORDER BY total_assigned DESC
 	`
 
-	rows, err := r.Pool.Query(ctx, query)
+	rows, err := r.DB(ctx).Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("PullRequestRepo - GetUserStats - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("PullRequestRepo - GetUserStats - r.DB.Query: %w", err)
 	}
 	defer rows.Close()
 
@@ -260,7 +410,7 @@ func (r *PullRequestRepo) GetPRStats(ctx context.Context) (*entity.PRStats, erro
 	`
 
 	var stats entity.PRStats
-	err := r.Pool.QueryRow(ctx, query).Scan(
+	err := r.DB(ctx).QueryRow(ctx, query).Scan(
 		&stats.TotalPRs,
 		&stats.OpenPRs,
 		&stats.MergedPRs,
@@ -268,22 +418,144 @@ func (r *PullRequestRepo) GetPRStats(ctx context.Context) (*entity.PRStats, erro
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("PullRequestRepo - GetPRStats - r.Pool.QueryRow: %w", err)
+		return nil, fmt.Errorf("PullRequestRepo - GetPRStats - r.DB.QueryRow: %w", err)
 	}
 
 	return &stats, nil
 }
 
+// Approve records the reviewer's approval and bumps the PR's updated_at.
+func (r *PullRequestRepo) Approve(ctx context.Context, prID, reviewerID string, at time.Time) error {
+	tx, err := r.DB(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("PullRequestRepo - Approve - r.DB.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Update("pr_reviewers").
+		Set("approved_at", at).
+		Where("pull_request_id = ? AND reviewer_id = ?", prID, reviewerID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("PullRequestRepo - Approve - r.Builder: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("PullRequestRepo - Approve - tx.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrReviewerNotAssigned
+	}
+
+	prSQL, prArgs, err := r.Builder.
+		Update("pull_requests").
+		Set("updated_at", at).
+		Set("stale_at", nil).
+		Where("pull_request_id = ?", prID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("PullRequestRepo - Approve - r.Builder (pr): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, prSQL, prArgs...); err != nil {
+		return fmt.Errorf("PullRequestRepo - Approve - tx.Exec (pr): %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetTeamStats returns per-team counters rolled up over each team's subtree.
+// Authored PRs are attributed to the author's primary team and reviews to the
+// reviewer's source team.
+func (r *PullRequestRepo) GetTeamStats(ctx context.Context) ([]entity.TeamStats, error) {
+	query := `
+		WITH RECURSIVE closure AS (
+			SELECT team_name AS ancestor, team_name AS descendant
+			FROM teams
+			UNION ALL
+			SELECT c.ancestor, t.team_name
+			FROM closure c
+			JOIN teams t ON t.parent_team = c.descendant
+		) CYCLE descendant SET is_cycle USING path,
+		memberships AS (
+			SELECT team_name, COUNT(*) AS total
+			FROM team_memberships
+			GROUP BY team_name
+		),
+		authored AS (
+			SELECT u.team_name,
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE p.status = 'OPEN') AS open,
+				COUNT(*) FILTER (WHERE p.status = 'MERGED') AS merged
+			FROM pull_requests p
+			JOIN users u ON u.user_id = p.author_id
+			WHERE u.team_name IS NOT NULL
+			GROUP BY u.team_name
+		),
+		reviews AS (
+			SELECT r.source_team AS team_name, COUNT(*) AS open
+			FROM pr_reviewers r
+			JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+			WHERE r.source_team IS NOT NULL AND p.status = 'OPEN'
+			GROUP BY r.source_team
+		)
+		SELECT c.ancestor, COALESCE(t.parent_team, ''),
+			COUNT(*) - 1,
+			COALESCE(SUM(m.total), 0),
+			COALESCE(SUM(a.total), 0),
+			COALESCE(SUM(a.open), 0),
+			COALESCE(SUM(a.merged), 0),
+			COALESCE(SUM(rv.open), 0)
+		FROM closure c
+		JOIN teams t ON t.team_name = c.ancestor AND NOT c.is_cycle
+		LEFT JOIN memberships m ON m.team_name = c.descendant
+		LEFT JOIN authored a ON a.team_name = c.descendant
+		LEFT JOIN reviews rv ON rv.team_name = c.descendant
+		GROUP BY c.ancestor, t.parent_team
+		ORDER BY c.ancestor
+	`
+
+	rows, err := r.DB(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetTeamStats - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []entity.TeamStats{}
+	for rows.Next() {
+		var s entity.TeamStats
+		if err := rows.Scan(
+			&s.TeamName,
+			&s.ParentTeam,
+			&s.SubTeams,
+			&s.Memberships,
+			&s.TotalPRs,
+			&s.OpenPRs,
+			&s.MergedPRs,
+			&s.OpenReviews,
+		); err != nil {
+			return nil, fmt.Errorf("PullRequestRepo - GetTeamStats - rows.Scan: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
 func (r *PullRequestRepo) GetOpenPRsByTeam(ctx context.Context, teamName string) ([]entity.PullRequest, error) {
 	query := `
-		SELECT DISTINCT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.created_at, p.merged_at
+		SELECT DISTINCT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.created_at, p.updated_at, p.merged_at
 		FROM pull_requests p
 		JOIN pr_reviewers pr ON p.pull_request_id = pr.pull_request_id
-		JOIN users u ON pr.reviewer_id = u.user_id
-		WHERE u.team_name = $1 AND p.status = 'OPEN'
+		JOIN team_memberships m ON pr.reviewer_id = m.user_id
+		WHERE m.team_name = $1 AND p.status = 'OPEN'
 	`
 
-	rows, err := r.Pool.Query(ctx, query, teamName)
+	rows, err := r.DB(ctx).Query(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("PullRequestRepo - GetOpenPRsByTeam: %w", err)
 	}
@@ -292,7 +564,7 @@ func (r *PullRequestRepo) GetOpenPRsByTeam(ctx context.Context, teamName string)
 	var prs []entity.PullRequest
 	for rows.Next() {
 		var pr entity.PullRequest
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.UpdatedAt, &pr.MergedAt); err != nil {
 			return nil, err
 		}
 		prs = append(prs, pr)
@@ -300,3 +572,126 @@ func (r *PullRequestRepo) GetOpenPRsByTeam(ctx context.Context, teamName string)
 
 	return prs, nil
 }
+
+// GetOpenReviewsByTeam counts the reviewers assigned to OPEN PRs by the team
+// each reviewer was drawn from. Teams are not rolled up.
+func (r *PullRequestRepo) GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	sql, args, err := r.Builder.
+		Select("r.source_team", "COUNT(*)").
+		From("pr_reviewers r").
+		Join("pull_requests p ON p.pull_request_id = r.pull_request_id").
+		Where("p.status = 'OPEN' AND r.source_team IS NOT NULL").
+		GroupBy("r.source_team").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetOpenReviewsByTeam - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetOpenReviewsByTeam - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var team string
+		var count int
+		if err := rows.Scan(&team, &count); err != nil {
+			return nil, fmt.Errorf("PullRequestRepo - GetOpenReviewsByTeam - rows.Scan: %w", err)
+		}
+		counts[team] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetOpenReviewsByTeam - rows.Err: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetStale returns OPEN PRs with no activity since before, oldest first.
+func (r *PullRequestRepo) GetStale(ctx context.Context, before time.Time) ([]entity.PullRequest, error) {
+	query := `
+		SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status,
+			p.created_at, p.updated_at, p.stale_at,
+			COALESCE(array_agg(pr.reviewer_id) FILTER (WHERE pr.reviewer_id IS NOT NULL), '{}') AS reviewers
+		FROM pull_requests p
+		LEFT JOIN pr_reviewers pr ON p.pull_request_id = pr.pull_request_id
+		WHERE p.status = 'OPEN' AND p.updated_at < $1
+		GROUP BY p.pull_request_id
+		ORDER BY p.updated_at
+	`
+
+	rows, err := r.DB(ctx).Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - GetStale - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	prs := []entity.PullRequest{}
+	for rows.Next() {
+		var pr entity.PullRequest
+		if err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.CreatedAt,
+			&pr.UpdatedAt,
+			&pr.StaleAt,
+			&pr.AssignedReviewers,
+		); err != nil {
+			return nil, fmt.Errorf("PullRequestRepo - GetStale - rows.Scan: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
+
+// ClaimSLABreaches marks the reviewers of OPEN PRs without a verdict that were
+// assigned at or before deadline and not reported yet as notified at the given
+// time, and returns them oldest first. Selecting and marking is one statement,
+// so concurrent callers never claim the same review.
+func (r *PullRequestRepo) ClaimSLABreaches(ctx context.Context, deadline, at time.Time) ([]entity.PendingReview, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE pr_reviewers pr SET sla_notified_at = $2
+			FROM pull_requests p
+			WHERE p.pull_request_id = pr.pull_request_id
+				AND p.status = 'OPEN' AND pr.approved_at IS NULL
+				AND pr.sla_notified_at IS NULL AND pr.assigned_at <= $1
+			RETURNING p.pull_request_id, p.pull_request_name, p.author_id,
+				pr.reviewer_id, COALESCE(pr.source_team, '') AS source_team, pr.assigned_at
+		)
+		SELECT pull_request_id, pull_request_name, author_id, reviewer_id, source_team, assigned_at
+		FROM claimed
+		ORDER BY assigned_at
+	`
+
+	rows, err := r.DB(ctx).Query(ctx, query, deadline, at)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestRepo - ClaimSLABreaches - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []entity.PendingReview{}
+	for rows.Next() {
+		var review entity.PendingReview
+		if err := rows.Scan(
+			&review.PullRequestID,
+			&review.PullRequestName,
+			&review.AuthorID,
+			&review.ReviewerID,
+			&review.SourceTeam,
+			&review.AssignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("PullRequestRepo - ClaimSLABreaches - rows.Scan: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,106 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/pkg/postgres"
+	"pr-reviewer-service/pkg/ratelimit"
+	"time"
+)
+
+// RateLimitRepo is a ratelimit.Store shared by every instance of the service.
+// Times are stored in UTC: updated_at has no time zone.
+type RateLimitRepo struct {
+	*postgres.Postgres
+}
+
+func NewRateLimitRepo(pg *postgres.Postgres) *RateLimitRepo {
+	return &RateLimitRepo{pg}
+}
+
+// Take takes a token from the bucket under key. The bucket row is locked for
+// the duration of the update, so concurrent requests are counted exactly.
+func (r *RateLimitRepo) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	now = now.UTC()
+
+	tx, err := r.DB(ctx).Begin(ctx)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - r.DB.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A new bucket starts full
+	insertSQL, insertArgs, err := r.Builder.
+		Insert("rate_limit_buckets").
+		Columns("bucket_key", "tokens", "updated_at").
+		Values(key, float64(limit.Burst), now).
+		Suffix("ON CONFLICT (bucket_key) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - r.Builder (insert): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, insertSQL, insertArgs...); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - tx.Exec (insert): %w", err)
+	}
+
+	selectSQL, selectArgs, err := r.Builder.
+		Select("tokens", "updated_at").
+		From("rate_limit_buckets").
+		Where("bucket_key = ?", key).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - r.Builder (select): %w", err)
+	}
+
+	var state ratelimit.State
+	if err := tx.QueryRow(ctx, selectSQL, selectArgs...).Scan(&state.Tokens, &state.UpdatedAt); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - tx.QueryRow: %w", err)
+	}
+
+	state, result := ratelimit.Take(state, limit, now)
+
+	updateSQL, updateArgs, err := r.Builder.
+		Update("rate_limit_buckets").
+		Set("tokens", state.Tokens).
+		Set("updated_at", state.UpdatedAt).
+		Where("bucket_key = ?", key).
+		ToSql()
+
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - r.Builder (update): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, updateSQL, updateArgs...); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - tx.Exec (update): %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("RateLimitRepo - Take - tx.Commit: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteIdle removes buckets untouched since before. Any bucket idle for
+// longer than it takes to refill is equivalent to a missing one.
+func (r *RateLimitRepo) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	sql, args, err := r.Builder.
+		Delete("rate_limit_buckets").
+		Where("updated_at < ?", before.UTC()).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("RateLimitRepo - DeleteIdle - r.Builder: %w", err)
+	}
+
+	tag, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("RateLimitRepo - DeleteIdle - r.DB.Exec: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,58 @@
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// undefinedTable is the Postgres error code of a missing table.
+const undefinedTable = "42P01"
+
+// SchemaRepo reads the schema version golang-migrate records in schema_migrations.
+type SchemaRepo struct {
+	*postgres.Postgres
+}
+
+func NewSchemaRepo(pg *postgres.Postgres) *SchemaRepo {
+	return &SchemaRepo{pg}
+}
+
+// Ping checks that a connection can be acquired and used.
+func (r *SchemaRepo) Ping(ctx context.Context) error {
+	if err := r.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("SchemaRepo - Ping - r.Pool.Ping: %w", err)
+	}
+	return nil
+}
+
+// Version returns the applied schema version and whether the last migration
+// failed halfway. A database without migrations is at version 0.
+func (r *SchemaRepo) Version(ctx context.Context) (uint, bool, error) {
+	sql, args, err := r.Builder.
+		Select("version", "dirty").
+		From("schema_migrations").
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		return 0, false, fmt.Errorf("SchemaRepo - Version - r.Builder: %w", err)
+	}
+
+	var version int64
+	var dirty bool
+	err = r.DB(ctx).QueryRow(ctx, sql, args...).Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTable) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("SchemaRepo - Version - r.DB.QueryRow: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
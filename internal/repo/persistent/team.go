@@ -6,6 +6,7 @@ import (
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/pkg/postgres"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -24,17 +25,17 @@ func NewTeamRepo(pg *postgres.Postgres, ur *UserRepo) *TeamRepo {
 func (r *TeamRepo) Create(ctx context.Context, team entity.Team) error {
 	sql, args, err := r.Builder.
 		Insert("teams").
-		Columns("team_name", "created_at").
-		Values(team.TeamName, team.CreatedAt).
+		Columns("team_name", "parent_team", "max_reviewers", "allow_cross_team", "created_at", "managed_by").
+		Values(team.TeamName, nullString(team.ParentTeam), team.Settings.MaxReviewers, team.Settings.AllowCrossTeam, team.CreatedAt, nullString(team.ManagedBy)).
 		ToSql()
 
 	if err != nil {
 		return fmt.Errorf("TeamRepo - Create - r.Builder: %w", err)
 	}
 
-	_, err = r.Pool.Exec(ctx, sql, args...)
+	_, err = r.DB(ctx).Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("TeamRepo - Create - r.Pool.Exec: %w", err)
+		return fmt.Errorf("TeamRepo - Create - r.DB.Exec: %w", err)
 	}
 
 	return nil
@@ -42,7 +43,7 @@ func (r *TeamRepo) Create(ctx context.Context, team entity.Team) error {
 
 func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (entity.Team, error) {
 	sql, args, err := r.Builder.
-		Select("team_name", "created_at").
+		Select("team_name", "COALESCE(parent_team, '')", "max_reviewers", "allow_cross_team", "created_at", "archived_at", "COALESCE(managed_by, '')").
 		From("teams").
 		Where("team_name = ?", teamName).
 		ToSql()
@@ -52,13 +53,21 @@ func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (entity.Team,
 	}
 
 	var team entity.Team
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&team.TeamName, &team.CreatedAt)
+	err = r.DB(ctx).QueryRow(ctx, sql, args...).Scan(
+		&team.TeamName,
+		&team.ParentTeam,
+		&team.Settings.MaxReviewers,
+		&team.Settings.AllowCrossTeam,
+		&team.CreatedAt,
+		&team.ArchivedAt,
+		&team.ManagedBy,
+	)
 
 	if err == pgx.ErrNoRows {
 		return entity.Team{}, entity.ErrNotFound
 	}
 	if err != nil {
-		return entity.Team{}, fmt.Errorf("TeamRepo - GetByName - r.Pool.QueryRow: %w", err)
+		return entity.Team{}, fmt.Errorf("TeamRepo - GetByName - r.DB.QueryRow: %w", err)
 	}
 
 	members, err := r.userRepo.GetByTeam(ctx, teamName)
@@ -66,18 +75,275 @@ func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (entity.Team,
 		return entity.Team{}, fmt.Errorf("TeamRepo - GetByName - r.userRepo.GetByTeam: %w", err)
 	}
 
+	fallbacks, err := r.getFallbacks(ctx, teamName)
+	if err != nil {
+		return entity.Team{}, fmt.Errorf("TeamRepo - GetByName - %w", err)
+	}
+
 	team.Members = members
+	team.Settings.FallbackTeams = fallbacks
 	return team, nil
 }
 
+func (r *TeamRepo) getFallbacks(ctx context.Context, teamName string) ([]string, error) {
+	sql, args, err := r.Builder.
+		Select("fallback_team").
+		From("team_fallbacks").
+		Where("team_name = ?", teamName).
+		OrderBy("priority").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("getFallbacks - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("getFallbacks - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	fallbacks := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("getFallbacks - rows.Scan: %w", err)
+		}
+		fallbacks = append(fallbacks, name)
+	}
+
+	return fallbacks, nil
+}
+
 func (r *TeamRepo) Exists(ctx context.Context, teamName string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`
 
 	var exists bool
-	err := r.Pool.QueryRow(ctx, query, teamName).Scan(&exists)
+	err := r.DB(ctx).QueryRow(ctx, query, teamName).Scan(&exists)
 	if err != nil {
-		return false, fmt.Errorf("TeamRepo - Exists - r.Pool.QueryRow: %w", err)
+		return false, fmt.Errorf("TeamRepo - Exists - r.DB.QueryRow: %w", err)
 	}
 
 	return exists, nil
 }
+
+// UpdateSettings stores the team settings, replacing its fallback teams with
+// settings.FallbackTeams in the given priority order.
+func (r *TeamRepo) UpdateSettings(ctx context.Context, teamName string, settings entity.TeamSettings) error {
+	tx, err := r.DB(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("TeamRepo - UpdateSettings - r.DB.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Update("teams").
+		Set("max_reviewers", settings.MaxReviewers).
+		Set("allow_cross_team", settings.AllowCrossTeam).
+		Where("team_name = ?", teamName).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TeamRepo - UpdateSettings - r.Builder: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TeamRepo - UpdateSettings - tx.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	deleteSQL, deleteArgs, err := r.Builder.
+		Delete("team_fallbacks").
+		Where("team_name = ?", teamName).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TeamRepo - UpdateSettings - r.Builder (delete): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+		return fmt.Errorf("TeamRepo - UpdateSettings - tx.Exec (delete): %w", err)
+	}
+
+	for priority, fallback := range settings.FallbackTeams {
+		insertSQL, insertArgs, err := r.Builder.
+			Insert("team_fallbacks").
+			Columns("team_name", "fallback_team", "priority").
+			Values(teamName, fallback, priority).
+			ToSql()
+
+		if err != nil {
+			return fmt.Errorf("TeamRepo - UpdateSettings - r.Builder (insert): %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, insertSQL, insertArgs...); err != nil {
+			return fmt.Errorf("TeamRepo - UpdateSettings - tx.Exec (insert): %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// LockHierarchy serializes changes of the team hierarchy until the transaction
+// of ctx ends, so that concurrent cycle checks see each other's changes.
+func (r *TeamRepo) LockHierarchy(ctx context.Context) error {
+	if _, err := r.DB(ctx).Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('teams.parent_team'))"); err != nil {
+		return fmt.Errorf("TeamRepo - LockHierarchy - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// SetParent moves the team under parentTeam. An empty parentTeam makes it a root team.
+func (r *TeamRepo) SetParent(ctx context.Context, teamName, parentTeam string) error {
+	sql, args, err := r.Builder.
+		Update("teams").
+		Set("parent_team", nullString(parentTeam)).
+		Where("team_name = ?", teamName).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TeamRepo - SetParent - r.Builder: %w", err)
+	}
+
+	tag, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TeamRepo - SetParent - r.DB.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetTree returns the teams of the hierarchy as a flat list without members.
+// When root is set only root and its descendants are returned.
+func (r *TeamRepo) GetTree(ctx context.Context, root string) ([]entity.Team, error) {
+	query := `
+		SELECT team_name, COALESCE(parent_team, ''), created_at
+		FROM teams
+		ORDER BY team_name`
+	var args []any
+
+	if root != "" {
+		query = `
+			WITH RECURSIVE subtree AS (
+				SELECT team_name, parent_team, created_at
+				FROM teams
+				WHERE team_name = $1
+				UNION ALL
+				SELECT t.team_name, t.parent_team, t.created_at
+				FROM teams t
+				JOIN subtree s ON t.parent_team = s.team_name
+			) CYCLE team_name SET is_cycle USING path
+			SELECT team_name, CASE WHEN team_name = $1 THEN COALESCE(parent_team, '') ELSE parent_team END, created_at
+			FROM subtree
+			WHERE NOT is_cycle
+			ORDER BY team_name`
+		args = append(args, root)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TeamRepo - GetTree - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []entity.Team{}
+	for rows.Next() {
+		var team entity.Team
+		if err := rows.Scan(&team.TeamName, &team.ParentTeam, &team.CreatedAt); err != nil {
+			return nil, fmt.Errorf("TeamRepo - GetTree - rows.Scan: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
+// GetAncestors returns the names of the team's ancestors, nearest first.
+func (r *TeamRepo) GetAncestors(ctx context.Context, teamName string) ([]string, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_team, 1 AS depth
+			FROM teams
+			WHERE team_name = $1 AND parent_team IS NOT NULL
+			UNION ALL
+			SELECT t.parent_team, a.depth + 1
+			FROM teams t
+			JOIN ancestors a ON t.team_name = a.parent_team
+			WHERE t.parent_team IS NOT NULL
+		) CYCLE parent_team SET is_cycle USING path
+		SELECT parent_team FROM ancestors WHERE NOT is_cycle ORDER BY depth`
+
+	rows, err := r.DB(ctx).Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("TeamRepo - GetAncestors - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	ancestors := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("TeamRepo - GetAncestors - rows.Scan: %w", err)
+		}
+		ancestors = append(ancestors, name)
+	}
+
+	return ancestors, nil
+}
+
+// GetChildren returns the names of the team's direct sub-teams.
+func (r *TeamRepo) GetChildren(ctx context.Context, teamName string) ([]string, error) {
+	sql, args, err := r.Builder.
+		Select("team_name").
+		From("teams").
+		Where("parent_team = ?", teamName).
+		OrderBy("team_name").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("TeamRepo - GetChildren - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TeamRepo - GetChildren - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	children := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("TeamRepo - GetChildren - rows.Scan: %w", err)
+		}
+		children = append(children, name)
+	}
+
+	return children, nil
+}
+
+func (r *TeamRepo) Archive(ctx context.Context, teamName string) error {
+	sql, args, err := r.Builder.
+		Update("teams").
+		Set("archived_at", squirrel.Expr("NOW()")).
+		Where("team_name = ? AND archived_at IS NULL", teamName).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TeamRepo - Archive - r.Builder: %w", err)
+	}
+
+	_, err = r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TeamRepo - Archive - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/pkg/postgres"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type TokenRepo struct {
+	*postgres.Postgres
+}
+
+func NewTokenRepo(pg *postgres.Postgres) *TokenRepo {
+	return &TokenRepo{pg}
+}
+
+var tokenColumns = []string{
+	"token_id", "name", "scopes", "COALESCE(user_id, '')", "created_at", "expires_at", "last_used_at", "revoked_at",
+}
+
+// Create stores the token under hash and returns it with its assigned id.
+func (r *TokenRepo) Create(ctx context.Context, token entity.APIToken, hash string) (entity.APIToken, error) {
+	sql, args, err := r.Builder.
+		Insert("api_tokens").
+		Columns("name", "token_hash", "scopes", "user_id", "created_at", "expires_at").
+		Values(token.Name, hash, scopeStrings(token.Scopes), nullString(token.UserID), token.CreatedAt, token.ExpiresAt).
+		Suffix("RETURNING token_id").
+		ToSql()
+
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("TokenRepo - Create - r.Builder: %w", err)
+	}
+
+	if err := r.DB(ctx).QueryRow(ctx, sql, args...).Scan(&token.TokenID); err != nil {
+		return entity.APIToken{}, fmt.Errorf("TokenRepo - Create - r.DB.QueryRow: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *TokenRepo) GetByHash(ctx context.Context, hash string) (entity.APIToken, error) {
+	sql, args, err := r.Builder.
+		Select(tokenColumns...).
+		From("api_tokens").
+		Where("token_hash = ?", hash).
+		ToSql()
+
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("TokenRepo - GetByHash - r.Builder: %w", err)
+	}
+
+	token, err := scanToken(r.DB(ctx).QueryRow(ctx, sql, args...))
+	if err == pgx.ErrNoRows {
+		return entity.APIToken{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("TokenRepo - GetByHash - r.DB.QueryRow: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *TokenRepo) List(ctx context.Context) ([]entity.APIToken, error) {
+	sql, args, err := r.Builder.
+		Select(tokenColumns...).
+		From("api_tokens").
+		OrderBy("token_id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("TokenRepo - List - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TokenRepo - List - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []entity.APIToken{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("TokenRepo - List - rows.Scan: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (r *TokenRepo) Revoke(ctx context.Context, tokenID int64, at time.Time) error {
+	sql, args, err := r.Builder.
+		Update("api_tokens").
+		Set("revoked_at", at).
+		Where("token_id = ? AND revoked_at IS NULL", tokenID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TokenRepo - Revoke - r.Builder: %w", err)
+	}
+
+	tag, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TokenRepo - Revoke - r.DB.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TokenRepo) TouchLastUsed(ctx context.Context, tokenID int64, at time.Time) error {
+	sql, args, err := r.Builder.
+		Update("api_tokens").
+		Set("last_used_at", at).
+		Where("token_id = ?", tokenID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("TokenRepo - TouchLastUsed - r.Builder: %w", err)
+	}
+
+	if _, err := r.DB(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("TokenRepo - TouchLastUsed - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func scanToken(row pgx.Row) (entity.APIToken, error) {
+	var (
+		token  entity.APIToken
+		scopes []string
+	)
+
+	err := row.Scan(
+		&token.TokenID,
+		&token.Name,
+		&scopes,
+		&token.UserID,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		return entity.APIToken{}, err
+	}
+
+	token.Scopes = make([]entity.Scope, len(scopes))
+	for i, s := range scopes {
+		token.Scopes[i] = entity.Scope(s)
+	}
+
+	return token, nil
+}
+
+func scopeStrings(scopes []entity.Scope) []string {
+	out := make([]string, len(scopes))
+	for i, s := range scopes {
+		out[i] = string(s)
+	}
+	return out
+}
@@ -2,10 +2,13 @@ package persistent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/pkg/postgres"
+	"strings"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -17,29 +20,57 @@ func NewUserRepo(pg *postgres.Postgres) *UserRepo {
 	return &UserRepo{pg}
 }
 
+// Create inserts or updates the user and, when TeamName is set, makes sure the user
+// is a member of that team. An existing user's primary team is kept and archived
+// users are left unchanged.
 func (r *UserRepo) Create(ctx context.Context, user entity.User) error {
+	tx, err := r.DB(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("UserRepo - Create - r.DB.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	sql, args, err := r.Builder.
 		Insert("users").
-		Columns("user_id", "username", "team_name", "is_active", "created_at").
-		Values(user.UserID, user.Username, user.TeamName, user.IsActive, user.CreatedAt).
-		Suffix("ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, team_name = EXCLUDED.team_name, is_active = EXCLUDED.is_active").
+		Columns("user_id", "username", "team_name", "is_active", "created_at", "managed_by").
+		Values(user.UserID, user.Username, nullString(user.TeamName), user.IsActive, user.CreatedAt, nullString(user.ManagedBy)).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, " +
+			"team_name = COALESCE(users.team_name, EXCLUDED.team_name), is_active = EXCLUDED.is_active " +
+			"WHERE users.archived_at IS NULL").
 		ToSql()
 
 	if err != nil {
 		return fmt.Errorf("UserRepo - Create - r.Builder: %w", err)
 	}
 
-	_, err = r.Pool.Exec(ctx, sql, args...)
+	_, err = tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("UserRepo - Create - r.Pool.Exec: %w", err)
+		return fmt.Errorf("UserRepo - Create - tx.Exec: %w", err)
 	}
 
-	return nil
+	if user.TeamName != "" {
+		membershipSQL, membershipArgs, err := r.Builder.
+			Insert("team_memberships").
+			Columns("user_id", "team_name", "review_weight", "role", "created_at").
+			Values(user.UserID, user.TeamName, entity.DefaultReviewWeight, entity.RoleMember, user.CreatedAt).
+			Suffix("ON CONFLICT (user_id, team_name) DO NOTHING").
+			ToSql()
+
+		if err != nil {
+			return fmt.Errorf("UserRepo - Create - r.Builder (membership): %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, membershipSQL, membershipArgs...); err != nil {
+			return fmt.Errorf("UserRepo - Create - tx.Exec (membership): %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (r *UserRepo) GetByID(ctx context.Context, userID string) (entity.User, error) {
 	sql, args, err := r.Builder.
-		Select("user_id", "username", "team_name", "is_active", "created_at").
+		Select("user_id", "username", "COALESCE(team_name, '')", "is_active", "created_at", "archived_at", "COALESCE(managed_by, '')").
 		From("users").
 		Where("user_id = ?", userID).
 		ToSql()
@@ -49,19 +80,21 @@ func (r *UserRepo) GetByID(ctx context.Context, userID string) (entity.User, err
 	}
 
 	var user entity.User
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+	err = r.DB(ctx).QueryRow(ctx, sql, args...).Scan(
 		&user.UserID,
 		&user.Username,
 		&user.TeamName,
 		&user.IsActive,
 		&user.CreatedAt,
+		&user.ArchivedAt,
+		&user.ManagedBy,
 	)
 
 	if err == pgx.ErrNoRows {
 		return entity.User{}, entity.ErrNotFound
 	}
 	if err != nil {
-		return entity.User{}, fmt.Errorf("UserRepo - GetByID - r.Pool.QueryRow: %w", err)
+		return entity.User{}, fmt.Errorf("UserRepo - GetByID - r.DB.QueryRow: %w", err)
 	}
 
 	return user, nil
@@ -71,8 +104,9 @@ func (r *UserRepo) Update(ctx context.Context, user entity.User) error {
 	sql, args, err := r.Builder.
 		Update("users").
 		Set("username", user.Username).
-		Set("team_name", user.TeamName).
+		Set("team_name", nullString(user.TeamName)).
 		Set("is_active", user.IsActive).
+		Set("archived_at", user.ArchivedAt).
 		Where("user_id = ?", user.UserID).
 		ToSql()
 
@@ -80,35 +114,40 @@ func (r *UserRepo) Update(ctx context.Context, user entity.User) error {
 		return fmt.Errorf("UserRepo - Update - r.Builder: %w", err)
 	}
 
-	_, err = r.Pool.Exec(ctx, sql, args...)
+	_, err = r.DB(ctx).Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("UserRepo - Update - r.Pool.Exec: %w", err)
+		return fmt.Errorf("UserRepo - Update - r.DB.Exec: %w", err)
 	}
 
 	return nil
 }
 
+// GetByTeam returns the team's members, leaving out archived users. Each user is returned in the team context:
+// TeamName is the given team, IsActive combines the user and membership flags and
+// ReviewWeight is the membership's weight.
 func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]entity.User, error) {
 	sql, args, err := r.Builder.
-		Select("user_id", "username", "team_name", "is_active", "created_at").
-		From("users").
-		Where("team_name = ?", teamName).
+		Select("u.user_id", "u.username", "m.team_name", "u.is_active AND m.is_active", "m.review_weight", "m.role", "u.created_at").
+		From("team_memberships m").
+		Join("users u ON u.user_id = m.user_id").
+		Where("m.team_name = ? AND u.archived_at IS NULL", teamName).
+		OrderBy("m.created_at", "u.user_id").
 		ToSql()
 
 	if err != nil {
 		return nil, fmt.Errorf("UserRepo - GetByTeam - r.Builder: %w", err)
 	}
 
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("UserRepo - GetByTeam - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("UserRepo - GetByTeam - r.DB.Query: %w", err)
 	}
 	defer rows.Close()
 
 	var users []entity.User
 	for rows.Next() {
 		var user entity.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.ReviewWeight, &user.Role, &user.CreatedAt); err != nil {
 			return nil, fmt.Errorf("UserRepo - GetByTeam - rows.Scan: %w", err)
 		}
 		users = append(users, user)
@@ -117,9 +156,11 @@ func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]entity.Use
 	return users, nil
 }
 
+// DeactivateTeam deactivates every membership of the team. Members stay active
+// in their other teams.
 func (r *UserRepo) DeactivateTeam(ctx context.Context, teamName string) error {
 	sql, args, err := r.Builder.
-		Update("users").
+		Update("team_memberships").
 		Set("is_active", false).
 		Where("team_name = ?", teamName).
 		ToSql()
@@ -128,10 +169,335 @@ func (r *UserRepo) DeactivateTeam(ctx context.Context, teamName string) error {
 		return fmt.Errorf("UserRepo - DeactivateTeam - r.Builder: %w", err)
 	}
 
-	_, err = r.Pool.Exec(ctx, sql, args...)
+	_, err = r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo - DeactivateTeam - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetManagedBy returns the users that are not archived and are managed by
+// managedBy, e.g. entity.ManagedByDirectory.
+func (r *UserRepo) GetManagedBy(ctx context.Context, managedBy string) ([]entity.User, error) {
+	sql, args, err := r.Builder.
+		Select("user_id", "username", "COALESCE(team_name, '')", "is_active", "created_at", "managed_by").
+		From("users").
+		Where("managed_by = ? AND archived_at IS NULL", managedBy).
+		OrderBy("user_id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo - GetManagedBy - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo - GetManagedBy - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var users []entity.User
+	for rows.Next() {
+		var user entity.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.CreatedAt, &user.ManagedBy); err != nil {
+			return nil, fmt.Errorf("UserRepo - GetManagedBy - rows.Scan: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepo) GetMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	sql, args, err := r.Builder.
+		Select("team_name", "is_active", "review_weight", "role", "created_at").
+		From("team_memberships").
+		Where("user_id = ?", userID).
+		OrderBy("created_at", "team_name").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo - GetMemberships - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo - GetMemberships - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []entity.Membership{}
+	for rows.Next() {
+		var m entity.Membership
+		if err := rows.Scan(&m.TeamName, &m.IsActive, &m.ReviewWeight, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("UserRepo - GetMemberships - rows.Scan: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships, nil
+}
+
+// SaveMembership inserts the membership or updates an existing one.
+func (r *UserRepo) SaveMembership(ctx context.Context, userID string, m entity.Membership) error {
+	sql, args, err := r.Builder.
+		Insert("team_memberships").
+		Columns("user_id", "team_name", "is_active", "review_weight", "role", "created_at").
+		Values(userID, m.TeamName, m.IsActive, m.ReviewWeight, m.Role, m.CreatedAt).
+		Suffix("ON CONFLICT (user_id, team_name) DO UPDATE SET is_active = EXCLUDED.is_active, " +
+			"review_weight = EXCLUDED.review_weight, role = EXCLUDED.role").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - SaveMembership - r.Builder: %w", err)
+	}
+
+	_, err = r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo - SaveMembership - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepo) RemoveMembership(ctx context.Context, userID, teamName string) error {
+	sql, args, err := r.Builder.
+		Delete("team_memberships").
+		Where("user_id = ? AND team_name = ?", userID, teamName).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - RemoveMembership - r.Builder: %w", err)
+	}
+
+	tag, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo - RemoveMembership - r.DB.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotTeamMember
+	}
+
+	return nil
+}
+
+// Erase anonymises the user: the id is replaced with anonymousID everywhere it is
+// referenced, the username and primary team are cleared and all memberships are
+// dropped. PRs and assignment history stay in place under the anonymous id.
+func (r *UserRepo) Erase(ctx context.Context, userID, anonymousID string) error {
+	tx, err := r.DB(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - r.DB.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	usernameSQL, usernameArgs, err := r.Builder.
+		Select("username").
+		From("users").
+		Where("user_id = ?", userID).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - r.Builder (username): %w", err)
+	}
+
+	var username string
+	err = tx.QueryRow(ctx, usernameSQL, usernameArgs...).Scan(&username)
+	if err == pgx.ErrNoRows {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.QueryRow (username): %w", err)
+	}
+
+	// The append-only triggers of audit_log and pr_assignment_events let this
+	// transaction rewrite their rows, including the ones the foreign keys cascade to
+	if _, err := tx.Exec(ctx, "SELECT set_config('pr_reviewer.audit_erasure', 'on', true)"); err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.Exec (erasure on): %w", err)
+	}
+
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("user_id", anonymousID).
+		Set("username", entity.ErasedUsername).
+		Set("team_name", nil).
+		Set("is_active", false).
+		Set("archived_at", squirrel.Expr("COALESCE(archived_at, NOW())")).
+		Set("erased_at", squirrel.Expr("NOW()")).
+		Where("user_id = ?", userID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - r.Builder: %w", err)
+	}
+
+	// Foreign keys cascade the new id to PRs, reviewers, events and memberships
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	membershipSQL, membershipArgs, err := r.Builder.
+		Delete("team_memberships").
+		Where("user_id = ?", anonymousID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - r.Builder (memberships): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, membershipSQL, membershipArgs...); err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.Exec (memberships): %w", err)
+	}
+
+	// actor_id is free text, so it is not covered by the foreign keys
+	actorSQL, actorArgs, err := r.Builder.
+		Update("pr_assignment_events").
+		Set("actor_id", anonymousID).
+		Where("actor_id = ?", userID).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("UserRepo - Erase - r.Builder (actor): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, actorSQL, actorArgs...); err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.Exec (actor): %w", err)
+	}
+
+	if err := r.eraseFromAuditLog(ctx, tx, userID, username, anonymousID); err != nil {
+		return fmt.Errorf("UserRepo - Erase - %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT set_config('pr_reviewer.audit_erasure', 'off', true)"); err != nil {
+		return fmt.Errorf("UserRepo - Erase - tx.Exec (erasure off): %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// eraseFromAuditLog rewrites the user's id to anonymousID in the audit log and
+// blanks their username in the recorded snapshots. Snapshots of the user
+// themselves are dropped entirely. Erase has turned on pr_reviewer.audit_erasure,
+// which the append-only trigger requires.
+func (r *UserRepo) eraseFromAuditLog(ctx context.Context, tx pgx.Tx, userID, username, anonymousID string) error {
+	// jsonb renders as canonical text, so an id or username is always spelled
+	// the same way inside a snapshot
+	quotedID := jsonString(userID)
+	nameField := `"username": ` + jsonString(username)
+	erasedNameField := `"username": ` + jsonString(entity.ErasedUsername)
+
+	scrub := func(column string) squirrel.Sqlizer {
+		return squirrel.Expr(
+			"CASE WHEN entity_type = ? AND entity_id = ? THEN NULL "+
+				"ELSE replace(replace("+column+"::text, ?, ?), ?, ?)::jsonb END",
+			entity.AuditEntityUser, userID, quotedID, jsonString(anonymousID), nameField, erasedNameField,
+		)
+	}
+	mentions := "%" + escapeLike(quotedID) + "%"
+
+	sql, args, err := r.Builder.
+		Update("audit_log").
+		Set("before", scrub("before")).
+		Set("after", scrub("after")).
+		Set("actor_id", squirrel.Expr("CASE WHEN actor_id = ? THEN ? ELSE actor_id END", userID, anonymousID)).
+		Set("entity_id", squirrel.Expr("CASE WHEN entity_type = ? AND entity_id = ? THEN ? ELSE entity_id END",
+			entity.AuditEntityUser, userID, anonymousID)).
+		Where(squirrel.Or{
+			squirrel.Eq{"actor_id": userID},
+			squirrel.Eq{"entity_type": entity.AuditEntityUser, "entity_id": userID},
+			squirrel.Expr("before::text LIKE ?", mentions),
+			squirrel.Expr("after::text LIKE ?", mentions),
+		}).
+		ToSql()
+
 	if err != nil {
-		return fmt.Errorf("UserRepo - DeactivateTeam - r.Pool.Exec: %w", err)
+		return fmt.Errorf("r.Builder (audit): %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("tx.Exec (audit): %w", err)
 	}
 
 	return nil
 }
+
+// jsonString returns s encoded as a JSON string literal.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// List returns a page of the user directory together with the total number of
+// matching users. Erased users are never listed.
+func (r *UserRepo) List(ctx context.Context, filter entity.UserFilter) ([]entity.UserSummary, int, error) {
+	builder := r.Builder.
+		Select("u.user_id", "u.username", "COALESCE(u.team_name, '')", "u.is_active", "u.created_at", "u.archived_at",
+			"o.open_reviews", "COUNT(*) OVER ()").
+		From("users u").
+		JoinClause("CROSS JOIN LATERAL (SELECT COUNT(*) AS open_reviews FROM pr_reviewers r " +
+			"JOIN pull_requests p ON p.pull_request_id = r.pull_request_id " +
+			"WHERE r.reviewer_id = u.user_id AND p.status = 'OPEN') o").
+		Where("u.erased_at IS NULL")
+
+	if !filter.IncludeArchived {
+		builder = builder.Where("u.archived_at IS NULL")
+	}
+	if filter.TeamName != "" {
+		builder = builder.Where("EXISTS (SELECT 1 FROM team_memberships m WHERE m.user_id = u.user_id AND m.team_name = ?)", filter.TeamName)
+	}
+	if filter.IsActive != nil {
+		builder = builder.Where("u.is_active = ?", *filter.IsActive)
+	}
+	if filter.MaxOpenReviews > 0 {
+		builder = builder.Where("o.open_reviews < ?", filter.MaxOpenReviews)
+	}
+	if filter.Query != "" {
+		pattern := escapeLike(strings.ToLower(filter.Query))
+		builder = builder.
+			Where("lower(u.username) LIKE ?", "%"+pattern+"%").
+			OrderByClause("lower(u.username) LIKE ? DESC", pattern+"%")
+	}
+
+	sql, args, err := builder.
+		OrderBy("u.username", "u.user_id").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("UserRepo - List - r.Builder: %w", err)
+	}
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("UserRepo - List - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	users := []entity.UserSummary{}
+	total := 0
+	for rows.Next() {
+		var u entity.UserSummary
+		if err := rows.Scan(
+			&u.UserID,
+			&u.Username,
+			&u.TeamName,
+			&u.IsActive,
+			&u.CreatedAt,
+			&u.ArchivedAt,
+			&u.OpenReviews,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("UserRepo - List - rows.Scan: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, total, nil
+}
@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+)
+
+func TestArchiveUser(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	uc := newUserUseCase(s, &notifier{})
+
+	user, reassigned, err := uc.ArchiveUser(context.Background(), "u2")
+	if err != nil {
+		t.Fatalf("ArchiveUser: %v", err)
+	}
+	if !user.IsArchived() || user.IsActive {
+		t.Errorf("user = %+v, want archived and inactive", user)
+	}
+	if len(reassigned) != 1 || s.PRs["pr-1"].AssignedReviewers[0] != "u3" {
+		t.Errorf("pr-1 reviewers = %v, want u2 replaced by u3", s.PRs["pr-1"].AssignedReviewers)
+	}
+
+	// Archiving again changes nothing
+	audited := len(s.Audit)
+	if _, reassigned, err := uc.ArchiveUser(context.Background(), "u2"); err != nil || len(reassigned) != 0 {
+		t.Fatalf("second ArchiveUser = %v reassigned, %v; want none", reassigned, err)
+	}
+	if len(s.Audit) != audited {
+		t.Errorf("audit entries = %d, want %d", len(s.Audit), audited)
+	}
+
+	// An archived user is never selected again
+	pr, err := newPRUseCase(s, &notifier{}).CreatePR(context.Background(), "pr-2", "Fix", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if pr.HasReviewer("u2") {
+		t.Errorf("pr-2 reviewers = %v, want no archived u2", pr.AssignedReviewers)
+	}
+}
+
+func TestArchiveTeam(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2")
+	s.addTeam("platform", "p1", "u2")
+	backend := s.Teams["backend"]
+	backend.Settings.FallbackTeams = []string{"platform"}
+	s.Teams["backend"] = backend
+	openReview(s, "pr-1", "p1", "u2", "backend")
+	openReview(s, "pr-2", "p1", "u2", "platform")
+	uc := newTeamUseCase(s, &notifier{})
+
+	team, reassigned, err := uc.ArchiveTeam(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("ArchiveTeam: %v", err)
+	}
+	if !team.IsArchived() {
+		t.Error("team is not archived")
+	}
+	if len(reassigned) != 1 || reassigned[0].PullRequestID != "pr-1" {
+		t.Errorf("reassigned = %v, want only pr-1, drawn from backend", prIDs(reassigned))
+	}
+	if pr := s.PRs["pr-2"]; !pr.HasReviewer("u2") {
+		t.Errorf("pr-2 reviewers = %v, want u2 kept: drawn from platform", pr.AssignedReviewers)
+	}
+	if m, _ := s.membership("u2", "platform"); !m.IsActive {
+		t.Error("u2 is no longer active in platform")
+	}
+
+	if _, err := uc.AddMembers(context.Background(), "backend", []entity.User{{UserID: "u9", IsActive: true}}); !errors.Is(err, entity.ErrTeamArchived) {
+		t.Errorf("AddMembers error = %v, want %v", err, entity.ErrTeamArchived)
+	}
+}
+
+func TestEraseUser(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	openReview(s, "pr-2", "u2", "u1", "backend")
+	merged := s.PRs["pr-2"]
+	merged.Status = entity.StatusMerged
+	s.PRs["pr-2"] = merged
+
+	anonymousID, reassigned, err := newUserUseCase(s, &notifier{}).EraseUser(context.Background(), "u2")
+	if err != nil {
+		t.Fatalf("EraseUser: %v", err)
+	}
+
+	if _, ok := s.Users["u2"]; ok {
+		t.Error("u2 still exists")
+	}
+	if erased := s.Users[anonymousID]; erased.Username != entity.ErasedUsername || !erased.IsArchived() {
+		t.Errorf("erased user = %+v, want anonymised and archived", erased)
+	}
+	if len(reassigned) != 1 || s.PRs["pr-1"].AssignedReviewers[0] != "u3" {
+		t.Errorf("pr-1 reviewers = %v, want u2 replaced by u3 before erasure", s.PRs["pr-1"].AssignedReviewers)
+	}
+	if got := s.PRs["pr-2"].AuthorID; got != anonymousID {
+		t.Errorf("pr-2 author = %q, want the anonymous id %q", got, anonymousID)
+	}
+
+	last := s.Audit[len(s.Audit)-1]
+	if last.Action != entity.AuditUserErase || last.EntityID != anonymousID || last.Before != nil || last.After != nil {
+		t.Errorf("audit entry = %+v, want a bare %s of %s", last, entity.AuditUserErase, anonymousID)
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"time"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 500
+)
+
+// AuditUseCase records mutations in the audit log and reads it back.
+type AuditUseCase struct {
+	auditRepo repo.AuditRepo
+}
+
+func NewAuditUseCase(ar repo.AuditRepo) *AuditUseCase {
+	return &AuditUseCase{
+		auditRepo: ar,
+	}
+}
+
+// Record appends an entry for a mutation. Callers record it in the mutation's
+// transaction, so that the change and its entry are committed together. The
+// actor, the API token and the request id are taken from ctx. before and after
+// are snapshotted as JSON; a nil value leaves the snapshot empty. Values the
+// caller mutates afterwards must be passed through snapshot first.
+func (uc *AuditUseCase) Record(ctx context.Context, action entity.AuditAction, entityType entity.AuditEntityType, entityID string, before, after any) error {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.Record")
+	defer span.End()
+
+	entry := entity.AuditEntry{
+		ActorID:    entity.ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+		RequestID:  entity.RequestIDFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+	if token, ok := entity.APITokenFromContext(ctx); ok {
+		entry.ActorTokenID = token.TokenID
+	}
+
+	if err := uc.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("uc.auditRepo.Create: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries returns a page of the audit log, newest first. The page size
+// defaults to defaultAuditPageSize and is capped at maxAuditPageSize.
+func (uc *AuditUseCase) ListEntries(ctx context.Context, filter entity.AuditFilter) (entity.AuditPage, error) {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.ListEntries")
+	defer span.End()
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	entries, total, err := uc.auditRepo.List(ctx, filter)
+	if err != nil {
+		return entity.AuditPage{}, fmt.Errorf("AuditUseCase - ListEntries - uc.auditRepo.List: %w", err)
+	}
+
+	return entity.AuditPage{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// snapshot encodes v as JSON at the time of the call. nil and JSON null
+// produce an empty snapshot.
+func snapshot(v any) json.RawMessage {
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw
+	}
+	if v == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil || string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
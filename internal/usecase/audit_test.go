@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+// newPRUseCase wires the use cases of a PR over s.
+func newPRUseCase(s *store, n *notifier) *usecase.PullRequestUseCase {
+	audit := usecase.NewAuditUseCase(s.auditLog())
+	return usecase.NewPullRequestUseCase(s, s.prs(), s.users(), s.teams(), usecase.NewReviewerSelector(), audit, nil, n)
+}
+
+func TestCreatePRNotifiesAfterCommit(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	n := &notifier{}
+
+	pr, err := newPRUseCase(s, n).CreatePR(context.Background(), "pr-1", "Fix", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+
+	if len(pr.AssignedReviewers) != entity.AutoReviewers {
+		t.Fatalf("reviewers = %v, want %d", pr.AssignedReviewers, entity.AutoReviewers)
+	}
+	if got, want := n.events(), []entity.NotificationEvent{entity.NotifyAssigned, entity.NotifyAssigned}; !slices.Equal(got, want) {
+		t.Errorf("notifications = %v, want %v", got, want)
+	}
+	if len(s.Audit) != 1 || s.Audit[0].Action != entity.AuditPRCreate {
+		t.Errorf("audit = %+v, want one %s entry", s.Audit, entity.AuditPRCreate)
+	}
+}
+
+func TestAuditFailureRollsBack(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	s.failAudit = errAuditDown
+	n := &notifier{}
+	ctx := context.Background()
+
+	if _, err := newPRUseCase(s, n).CreatePR(ctx, "pr-1", "Fix", "u1"); !errors.Is(err, errAuditDown) {
+		t.Fatalf("CreatePR error = %v, want %v", err, errAuditDown)
+	}
+	if _, ok := s.PRs["pr-1"]; ok {
+		t.Error("PR created without its audit entry")
+	}
+	if len(n.sent) != 0 {
+		t.Errorf("notifications of a rolled back PR: %v", n.events())
+	}
+
+	users := usecase.NewUserUseCase(s, s.users(), s.prs(), newPRUseCase(s, n), usecase.NewAuditUseCase(s.auditLog()))
+	if _, err := users.SetIsActive(ctx, "u2", false); !errors.Is(err, errAuditDown) {
+		t.Fatalf("SetIsActive error = %v, want %v", err, errAuditDown)
+	}
+	if !s.Users["u2"].IsActive {
+		t.Error("user deactivated without its audit entry")
+	}
+}
+
+func TestRecordTokenOfServicePrincipal(t *testing.T) {
+	s := newStore()
+	audit := usecase.NewAuditUseCase(s.auditLog())
+	ctx := entity.WithAPIToken(context.Background(), entity.APIToken{TokenID: 7, Name: "ci"})
+
+	if err := audit.Record(ctx, entity.AuditUserCreate, entity.AuditEntityUser, "u1", nil, nil); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if len(s.Audit) != 1 {
+		t.Fatalf("audit = %+v, want one entry", s.Audit)
+	}
+	if got := s.Audit[0]; got.ActorID != "" || got.ActorTokenID != 7 {
+		t.Errorf("actor = %q, token = %d, want no user and token 7", got.ActorID, got.ActorTokenID)
+	}
+}
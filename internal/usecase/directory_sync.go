@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// DirectorySyncUseCase reconciles users and teams with an external directory
+// such as LDAP.
+type DirectorySyncUseCase struct {
+	tx        repo.Transactor
+	directory repo.DirectoryRepo
+	teamRepo  repo.TeamRepo
+	userRepo  repo.UserRepo
+	teamUC    *TeamUseCase
+	userUC    *UserUseCase
+}
+
+func NewDirectorySyncUseCase(tx repo.Transactor, d repo.DirectoryRepo, tr repo.TeamRepo, ur repo.UserRepo, team *TeamUseCase, user *UserUseCase) *DirectorySyncUseCase {
+	return &DirectorySyncUseCase{
+		tx:        tx,
+		directory: d,
+		teamRepo:  tr,
+		userRepo:  ur,
+		teamUC:    team,
+		userUC:    user,
+	}
+}
+
+// Sync reads the directory and plans the changes that make the users and the
+// teams of its groups match it, see entity.PlanDirectorySync. Unless dryRun is
+// set, the changes are applied in one transaction. An empty directory is
+// rejected rather than taken as every user leaving.
+func (uc *DirectorySyncUseCase) Sync(ctx context.Context, dryRun bool) (entity.TeamSyncResult, error) {
+	ctx, span := tracer.Start(ctx, "DirectorySyncUseCase.Sync")
+	defer span.End()
+
+	dir, err := uc.directory.Fetch(ctx)
+	if err != nil {
+		return entity.TeamSyncResult{}, fmt.Errorf("DirectorySyncUseCase - Sync - uc.directory.Fetch: %w", err)
+	}
+	if len(dir.Users) == 0 {
+		return entity.TeamSyncResult{}, entity.ErrEmptyDirectory
+	}
+
+	result := entity.TeamSyncResult{DryRun: dryRun, ReassignedPRs: []entity.PullRequest{}}
+	plan := func(ctx context.Context) error {
+		users, teams, err := uc.current(ctx, dir)
+		if err != nil {
+			return err
+		}
+		result.Changes = entity.PlanDirectorySync(dir, users, teams)
+		return nil
+	}
+
+	if dryRun {
+		if err := plan(ctx); err != nil {
+			return entity.TeamSyncResult{}, fmt.Errorf("DirectorySyncUseCase - Sync - %w", err)
+		}
+		return result, nil
+	}
+
+	names := make(map[string]string, len(dir.Users))
+	for _, u := range dir.Users {
+		names[u.UserID] = u.Username
+	}
+
+	ctx, release := holdNotifications(ctx)
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := plan(ctx); err != nil {
+			return err
+		}
+
+		for _, change := range result.Changes {
+			reassigned, err := uc.apply(ctx, change, names[change.UserID])
+			if err != nil {
+				return fmt.Errorf("%s: %w", change, err)
+			}
+			result.ReassignedPRs = append(result.ReassignedPRs, reassigned...)
+		}
+		return nil
+	})
+	release(err == nil)
+	if err != nil {
+		return entity.TeamSyncResult{}, fmt.Errorf("DirectorySyncUseCase - Sync - %w", err)
+	}
+
+	return result, nil
+}
+
+// current loads the stored teams of the directory groups and the stored
+// directory users, members of those teams and users managed by the directory.
+func (uc *DirectorySyncUseCase) current(ctx context.Context, dir entity.Directory) (map[string]entity.User, map[string]entity.Team, error) {
+	managed, err := uc.userRepo.GetManagedBy(ctx, entity.ManagedByDirectory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("uc.userRepo.GetManagedBy: %w", err)
+	}
+
+	teams := make(map[string]entity.Team, len(dir.Groups))
+	userIDs := make([]string, 0, len(dir.Users)+len(managed))
+	for _, u := range dir.Users {
+		userIDs = append(userIDs, u.UserID)
+	}
+	for _, u := range managed {
+		userIDs = append(userIDs, u.UserID)
+	}
+
+	for _, g := range dir.Groups {
+		team, err := uc.teamRepo.GetByName(ctx, g.TeamName)
+		if errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
+		}
+		teams[g.TeamName] = team
+		for _, m := range team.Members {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+
+	users := make(map[string]entity.User, len(userIDs))
+	for _, userID := range userIDs {
+		if _, ok := users[userID]; ok {
+			continue
+		}
+
+		// Team members carry their membership flags: load the account instead
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("uc.userRepo.GetByID: %w", err)
+		}
+		users[userID] = user
+	}
+
+	return users, teams, nil
+}
+
+func (uc *DirectorySyncUseCase) apply(ctx context.Context, change entity.TeamSyncChange, username string) ([]entity.PullRequest, error) {
+	switch change.Action {
+	case entity.SyncCreateUser:
+		_, err := uc.userUC.CreateUser(ctx, entity.User{UserID: change.UserID, Username: username, IsActive: true, ManagedBy: entity.ManagedByDirectory})
+		return nil, err
+
+	case entity.SyncRenameUser:
+		_, err := uc.userUC.RenameUser(ctx, change.UserID, username)
+		return nil, err
+
+	case entity.SyncCreateTeam:
+		_, err := uc.teamUC.createTeam(ctx, change.TeamName, entity.ManagedByDirectory, nil)
+		return nil, err
+
+	case entity.SyncAddMember:
+		_, err := uc.teamUC.AddUsers(ctx, change.TeamName, []string{change.UserID})
+		return nil, err
+
+	case entity.SyncRemoveMember:
+		return uc.teamUC.RemoveMember(ctx, change.TeamName, change.UserID, true)
+
+	case entity.SyncDeactivateUser:
+		_, reassigned, err := uc.userUC.DeactivateAndReassign(ctx, change.UserID)
+		return reassigned, err
+	}
+
+	return nil, fmt.Errorf("unknown action %q", change.Action)
+}
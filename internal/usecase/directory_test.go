@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+)
+
+func TestListUsersPaging(t *testing.T) {
+	s := newStore()
+	ids := make([]string, 0, 250)
+	for i := range 250 {
+		ids = append(ids, fmt.Sprintf("u%03d", i))
+	}
+	s.addTeam("backend", ids...)
+	archived := s.Users["u000"]
+	archived.Archive()
+	s.Users["u000"] = archived
+	uc := newUserUseCase(s, &notifier{})
+
+	tests := []struct {
+		name       string
+		filter     entity.UserFilter
+		wantLimit  int
+		wantOffset int
+		wantFirst  string
+		wantLen    int
+		wantTotal  int
+	}{
+		{name: "default page size", filter: entity.UserFilter{}, wantLimit: 50, wantFirst: "u001", wantLen: 50, wantTotal: 249},
+		{name: "page size capped", filter: entity.UserFilter{Limit: 1000}, wantLimit: 200, wantFirst: "u001", wantLen: 200, wantTotal: 249},
+		{name: "negative offset", filter: entity.UserFilter{Limit: 10, Offset: -5}, wantLimit: 10, wantFirst: "u001", wantLen: 10, wantTotal: 249},
+		{name: "last page", filter: entity.UserFilter{Limit: 100, Offset: 200}, wantLimit: 100, wantOffset: 200, wantFirst: "u201", wantLen: 49, wantTotal: 249},
+		{name: "archived included", filter: entity.UserFilter{Limit: 1, IncludeArchived: true}, wantLimit: 1, wantFirst: "u000", wantLen: 1, wantTotal: 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := uc.ListUsers(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("ListUsers: %v", err)
+			}
+			if page.Limit != tt.wantLimit || page.Offset != tt.wantOffset {
+				t.Errorf("limit, offset = %d, %d; want %d, %d", page.Limit, page.Offset, tt.wantLimit, tt.wantOffset)
+			}
+			if page.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", page.Total, tt.wantTotal)
+			}
+			if len(page.Users) != tt.wantLen || page.Users[0].UserID != tt.wantFirst {
+				t.Errorf("page = %d users from %q, want %d from %q", len(page.Users), page.Users[0].UserID, tt.wantLen, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestGetUserCountsOpenReviews(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2")
+	s.addTeam("platform", "u2")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	openReview(s, "pr-2", "u1", "u2", "platform")
+	openReview(s, "pr-3", "u1", "u2", "backend")
+	merged := s.PRs["pr-3"]
+	merged.Status = entity.StatusMerged
+	s.PRs["pr-3"] = merged
+
+	profile, err := newUserUseCase(s, &notifier{}).GetUser(context.Background(), "u2")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if profile.OpenReviews != 2 {
+		t.Errorf("open reviews = %d, want 2", profile.OpenReviews)
+	}
+	if len(profile.Memberships) != 2 {
+		t.Errorf("memberships = %v, want backend and platform", profile.Memberships)
+	}
+}
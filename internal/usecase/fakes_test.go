@@ -0,0 +1,477 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// state is the data behind the fake repositories.
+type state struct {
+	Users       map[string]entity.User
+	Memberships map[string][]entity.Membership
+	Teams       map[string]entity.Team
+	PRs         map[string]entity.PullRequest
+	Audit       []entity.AuditEntry
+}
+
+// store is an in-memory database. Its transactions snapshot the state and
+// restore it when fn fails.
+type store struct {
+	mu sync.Mutex
+	state
+	// failAudit, when set, fails every audit entry write.
+	failAudit error
+}
+
+func newStore() *store {
+	return &store{state: state{
+		Users:       map[string]entity.User{},
+		Memberships: map[string][]entity.Membership{},
+		Teams:       map[string]entity.Team{},
+		PRs:         map[string]entity.PullRequest{},
+	}}
+}
+
+type txKey struct{}
+
+func (s *store) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	saved, err := json.Marshal(s.state)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		s.mu.Lock()
+		s.state = state{}
+		if jerr := json.Unmarshal(saved, &s.state); jerr != nil {
+			panic(jerr)
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// addTeam creates a team whose members are active users with a membership.
+func (s *store) addTeam(teamName string, userIDs ...string) {
+	s.Teams[teamName] = entity.Team{TeamName: teamName, Settings: entity.DefaultTeamSettings()}
+	for _, userID := range userIDs {
+		if _, ok := s.Users[userID]; !ok {
+			s.Users[userID] = entity.User{UserID: userID, Username: userID, TeamName: teamName, IsActive: true}
+		}
+		s.Memberships[userID] = append(s.Memberships[userID], entity.Membership{
+			TeamName:     teamName,
+			IsActive:     true,
+			ReviewWeight: entity.DefaultReviewWeight,
+			Role:         entity.RoleMember,
+		})
+	}
+}
+
+func (s *store) users() repo.UserRepo      { return &fakeUserRepo{s: s} }
+func (s *store) teams() repo.TeamRepo      { return &fakeTeamRepo{s: s} }
+func (s *store) prs() repo.PullRequestRepo { return &fakePRRepo{s: s} }
+func (s *store) auditLog() repo.AuditRepo  { return &fakeAuditRepo{s: s} }
+
+// membership returns the user's membership of the team.
+func (s *store) membership(userID, teamName string) (entity.Membership, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.Memberships[userID] {
+		if m.TeamName == teamName {
+			return m, true
+		}
+	}
+	return entity.Membership{}, false
+}
+
+// The fakes embed the repository interfaces: methods a test does not reach
+// are left unimplemented and panic.
+
+type fakeUserRepo struct {
+	repo.UserRepo
+	s *store
+}
+
+// Create mirrors UserRepo.Create: it upserts the user, keeping the primary
+// team and archived users, and adds the membership of TeamName.
+func (r *fakeUserRepo) Create(_ context.Context, user entity.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if existing, ok := r.s.Users[user.UserID]; ok {
+		if existing.IsArchived() {
+			return nil
+		}
+		existing.Username, existing.IsActive = user.Username, user.IsActive
+		if existing.TeamName == "" {
+			existing.TeamName = user.TeamName
+		}
+		r.s.Users[user.UserID] = existing
+	} else {
+		user.ReviewWeight, user.Role = 0, ""
+		r.s.Users[user.UserID] = user
+	}
+
+	if user.TeamName == "" {
+		return nil
+	}
+	for _, m := range r.s.Memberships[user.UserID] {
+		if m.TeamName == user.TeamName {
+			return nil
+		}
+	}
+	r.s.Memberships[user.UserID] = append(r.s.Memberships[user.UserID], entity.Membership{
+		TeamName:     user.TeamName,
+		IsActive:     true,
+		ReviewWeight: entity.DefaultReviewWeight,
+		Role:         entity.RoleMember,
+	})
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, userID string) (entity.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.Users[userID]
+	if !ok {
+		return entity.User{}, entity.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user entity.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.Users[user.UserID]; !ok {
+		return entity.ErrNotFound
+	}
+	user.ReviewWeight, user.Role = 0, ""
+	r.s.Users[user.UserID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetByTeam(_ context.Context, teamName string) ([]entity.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.members(teamName), nil
+}
+
+func (r *fakeUserRepo) GetMemberships(_ context.Context, userID string) ([]entity.Membership, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return slices.Clone(r.s.Memberships[userID]), nil
+}
+
+func (r *fakeUserRepo) SaveMembership(_ context.Context, userID string, m entity.Membership) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	memberships := r.s.Memberships[userID]
+	for i := range memberships {
+		if memberships[i].TeamName == m.TeamName {
+			memberships[i] = m
+			return nil
+		}
+	}
+	r.s.Memberships[userID] = append(memberships, m)
+	return nil
+}
+
+func (r *fakeUserRepo) RemoveMembership(_ context.Context, userID, teamName string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.Memberships[userID] = slices.DeleteFunc(r.s.Memberships[userID], func(m entity.Membership) bool {
+		return m.TeamName == teamName
+	})
+	return nil
+}
+
+func (r *fakeUserRepo) DeactivateTeam(_ context.Context, teamName string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, memberships := range r.s.Memberships {
+		for i := range memberships {
+			if memberships[i].TeamName == teamName {
+				memberships[i].IsActive = false
+			}
+		}
+	}
+	return nil
+}
+
+// Erase mirrors UserRepo.Erase: the user and the PRs referencing them move to
+// anonymousID and the memberships are dropped.
+func (r *fakeUserRepo) Erase(_ context.Context, userID, anonymousID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.Users[userID]
+	if !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.s.Users, userID)
+	delete(r.s.Memberships, userID)
+	user.UserID, user.Username, user.TeamName = anonymousID, entity.ErasedUsername, ""
+	user.Archive()
+	r.s.Users[anonymousID] = user
+
+	rename := func(ids []string) {
+		for i := range ids {
+			if ids[i] == userID {
+				ids[i] = anonymousID
+			}
+		}
+	}
+	for id, pr := range r.s.PRs {
+		if pr.AuthorID == userID {
+			pr.AuthorID = anonymousID
+		}
+		rename(pr.AssignedReviewers)
+		rename(pr.ApprovedBy)
+		if team, ok := pr.ReviewerTeams[userID]; ok {
+			delete(pr.ReviewerTeams, userID)
+			pr.ReviewerTeams[anonymousID] = team
+		}
+		r.s.PRs[id] = pr
+	}
+	return nil
+}
+
+// List pages through the users in id order; only IncludeArchived is honoured
+// among the filters.
+func (r *fakeUserRepo) List(_ context.Context, filter entity.UserFilter) ([]entity.UserSummary, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var all []entity.UserSummary
+	for _, user := range r.s.Users {
+		if filter.IncludeArchived || !user.IsArchived() {
+			all = append(all, entity.UserSummary{User: user})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].UserID < all[j].UserID })
+	page := all[min(filter.Offset, len(all)):]
+	return page[:min(filter.Limit, len(page))], len(all), nil
+}
+
+// members mirrors UserRepo.GetByTeam. The caller holds s.mu.
+func (s *store) members(teamName string) []entity.User {
+	var users []entity.User
+	for userID, memberships := range s.Memberships {
+		user := s.Users[userID]
+		if user.IsArchived() {
+			continue
+		}
+		for _, m := range memberships {
+			if m.TeamName == teamName {
+				user.TeamName = teamName
+				user.IsActive = user.IsActive && m.IsActive
+				user.ReviewWeight = m.ReviewWeight
+				user.Role = m.Role
+				users = append(users, user)
+			}
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
+
+type fakeTeamRepo struct {
+	repo.TeamRepo
+	s *store
+}
+
+func (r *fakeTeamRepo) Create(_ context.Context, team entity.Team) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.Teams[team.TeamName]; ok {
+		return entity.ErrTeamAlreadyExists
+	}
+	team.Members = nil
+	r.s.Teams[team.TeamName] = team
+	return nil
+}
+
+func (r *fakeTeamRepo) GetByName(_ context.Context, teamName string) (entity.Team, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	team, ok := r.s.Teams[teamName]
+	if !ok {
+		return entity.Team{}, entity.ErrNotFound
+	}
+	team.Members = r.s.members(teamName)
+	return team, nil
+}
+
+func (r *fakeTeamRepo) Exists(_ context.Context, teamName string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, ok := r.s.Teams[teamName]
+	return ok, nil
+}
+
+func (r *fakeTeamRepo) SetParent(_ context.Context, teamName, parentTeam string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	team, ok := r.s.Teams[teamName]
+	if !ok {
+		return entity.ErrNotFound
+	}
+	team.ParentTeam = parentTeam
+	r.s.Teams[teamName] = team
+	return nil
+}
+
+func (r *fakeTeamRepo) LockHierarchy(context.Context) error {
+	return nil
+}
+
+// GetAncestors mirrors TeamRepo.GetAncestors: nearest first, stopping at a cycle.
+func (r *fakeTeamRepo) GetAncestors(_ context.Context, teamName string) ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ancestors []string
+	for parent := r.s.Teams[teamName].ParentTeam; parent != "" && !slices.Contains(ancestors, parent); parent = r.s.Teams[parent].ParentTeam {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors, nil
+}
+
+func (r *fakeTeamRepo) GetChildren(_ context.Context, teamName string) ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var children []string
+	for name, team := range r.s.Teams {
+		if team.ParentTeam == teamName {
+			children = append(children, name)
+		}
+	}
+	sort.Strings(children)
+	return children, nil
+}
+
+func (r *fakeTeamRepo) Archive(_ context.Context, teamName string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	team, ok := r.s.Teams[teamName]
+	if !ok || team.IsArchived() {
+		return nil
+	}
+	now := time.Now()
+	team.ArchivedAt = &now
+	r.s.Teams[teamName] = team
+	return nil
+}
+
+type fakePRRepo struct {
+	repo.PullRequestRepo
+	s *store
+}
+
+func (r *fakePRRepo) Create(_ context.Context, pr entity.PullRequest, _ entity.AssignmentMeta) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.PRs[pr.PullRequestID] = pr
+	return nil
+}
+
+func (r *fakePRRepo) GetByID(_ context.Context, prID string) (entity.PullRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	pr, ok := r.s.PRs[prID]
+	if !ok {
+		return entity.PullRequest{}, entity.ErrNotFound
+	}
+	return pr, nil
+}
+
+func (r *fakePRRepo) Update(_ context.Context, pr entity.PullRequest, _ entity.AssignmentMeta) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.PRs[pr.PullRequestID] = pr
+	return nil
+}
+
+func (r *fakePRRepo) Exists(_ context.Context, prID string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, ok := r.s.PRs[prID]
+	return ok, nil
+}
+
+func (r *fakePRRepo) GetByReviewer(_ context.Context, userID string) ([]entity.PullRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var prs []entity.PullRequest
+	for _, pr := range r.s.PRs {
+		if slices.Contains(pr.AssignedReviewers, userID) {
+			prs = append(prs, pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].PullRequestID < prs[j].PullRequestID })
+	return prs, nil
+}
+
+// GetStale mirrors PullRequestRepo.GetStale: OPEN PRs idle since before, oldest first.
+func (r *fakePRRepo) GetStale(_ context.Context, before time.Time) ([]entity.PullRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	prs := []entity.PullRequest{}
+	for _, pr := range r.s.PRs {
+		if pr.IsOpen() && pr.UpdatedAt.Before(before) {
+			prs = append(prs, pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].UpdatedAt.Before(prs[j].UpdatedAt) })
+	return prs, nil
+}
+
+type fakeAuditRepo struct {
+	repo.AuditRepo
+	s *store
+}
+
+func (r *fakeAuditRepo) Create(_ context.Context, entry entity.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.failAudit != nil {
+		return r.s.failAudit
+	}
+	r.s.Audit = append(r.s.Audit, entry)
+	return nil
+}
+
+// notifier records the notifications sent.
+type notifier struct {
+	mu   sync.Mutex
+	sent []entity.Notification
+}
+
+func (n *notifier) Notify(_ context.Context, notification entity.Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+}
+
+func (n *notifier) events() []entity.NotificationEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	events := make([]entity.NotificationEvent, 0, len(n.sent))
+	for _, sent := range n.sent {
+		events = append(events, sent.Event)
+	}
+	return events
+}
+
+var errAuditDown = errors.New("audit log unavailable")
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"strings"
+)
+
+// ClaimsVerifier validates a signed bearer token and returns its claims.
+type ClaimsVerifier interface {
+	Verify(ctx context.Context, raw string) (map[string]any, error)
+}
+
+// JWTAuthenticator turns identity provider JWTs into request credentials: the
+// user claim names the acting user and the scope claim grants API scopes.
+type JWTAuthenticator struct {
+	verifier     ClaimsVerifier
+	userRepo     repo.UserRepo
+	userClaim    string
+	scopeClaim   string
+	defaultScope entity.Scope
+}
+
+func NewJWTAuthenticator(v ClaimsVerifier, ur repo.UserRepo, userClaim, scopeClaim string, defaultScope entity.Scope) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		verifier:     v,
+		userRepo:     ur,
+		userClaim:    userClaim,
+		scopeClaim:   scopeClaim,
+		defaultScope: defaultScope,
+	}
+}
+
+// Authenticate verifies the JWT and maps it to a user-bound credential. Tokens
+// that fail verification or name an unknown or archived user yield ErrUnauthorized.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, raw string) (entity.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "JWTAuthenticator.Authenticate")
+	defer span.End()
+
+	claims, err := a.verifier.Verify(ctx, raw)
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("JWTAuthenticator - Authenticate - %w: %w", entity.ErrUnauthorized, err)
+	}
+
+	userID, _ := claims[a.userClaim].(string)
+	if userID == "" {
+		return entity.APIToken{}, entity.ErrUnauthorized
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, entity.ErrNotFound) {
+		return entity.APIToken{}, entity.ErrUnauthorized
+	}
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("JWTAuthenticator - Authenticate - a.userRepo.GetByID: %w", err)
+	}
+	if user.IsArchived() {
+		return entity.APIToken{}, entity.ErrUnauthorized
+	}
+
+	return entity.APIToken{
+		Name:   "jwt",
+		Scopes: a.scopes(claims),
+		UserID: user.UserID,
+	}, nil
+}
+
+// scopes reads API scopes from a space separated string or a list claim,
+// ignoring values that are not API scopes. Impersonation is left to API tokens.
+// Without any, the default scope applies.
+func (a *JWTAuthenticator) scopes(claims map[string]any) []entity.Scope {
+	var values []string
+	switch v := claims[a.scopeClaim].(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	scopes := []entity.Scope{}
+	for _, v := range values {
+		if s := entity.Scope(v); s.IsValid() && s != entity.ScopeImpersonate {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 && a.defaultScope != "" {
+		scopes = append(scopes, a.defaultScope)
+	}
+
+	return scopes
+}
+
+// looksLikeJWT tells JWTs apart from API tokens, which never contain dots.
+func looksLikeJWT(raw string) bool {
+	return strings.Count(raw, ".") == 2
+}
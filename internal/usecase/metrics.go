@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"pr-reviewer-service/pkg/metrics"
+)
+
+// DomainMetrics counts reviewer reassignments and replacements that found no
+// candidate, and exposes open PRs and open reviews per team read from the
+// database at scrape time. A nil *DomainMetrics counts nothing.
+type DomainMetrics struct {
+	reassignments *metrics.Counter
+	noCandidates  *metrics.Counter
+}
+
+func NewDomainMetrics(reg *metrics.Registry, prr repo.PullRequestRepo) *DomainMetrics {
+	reg.NewFunc("pr_reviewer_open_pull_requests", "Open pull requests.", metrics.TypeGauge, nil,
+		func(ctx context.Context) ([]metrics.Sample, error) {
+			stats, err := prr.GetPRStats(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("DomainMetrics - prr.GetPRStats: %w", err)
+			}
+			return []metrics.Sample{{Value: float64(stats.OpenPRs)}}, nil
+		})
+
+	reg.NewFunc("pr_reviewer_open_review_assignments", "Reviewers assigned to open pull requests by the team they were drawn from.", metrics.TypeGauge, []string{"team"},
+		func(ctx context.Context) ([]metrics.Sample, error) {
+			counts, err := prr.GetOpenReviewsByTeam(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("DomainMetrics - prr.GetOpenReviewsByTeam: %w", err)
+			}
+			samples := make([]metrics.Sample, 0, len(counts))
+			for team, count := range counts {
+				samples = append(samples, metrics.Sample{Labels: []string{team}, Value: float64(count)})
+			}
+			return samples, nil
+		})
+
+	return &DomainMetrics{
+		reassignments: reg.NewCounter("pr_reviewer_reassignments_total",
+			"Reviewers replaced on a pull request, by reason.", "reason"),
+		noCandidates: reg.NewCounter("pr_reviewer_no_candidates_total",
+			"Reviewer replacements that found no candidate, by reason.", "reason"),
+	}
+}
+
+func (m *DomainMetrics) reassigned(reason entity.AssignmentReason) {
+	if m != nil {
+		m.reassignments.Inc(string(reason))
+	}
+}
+
+func (m *DomainMetrics) noCandidate(reason entity.AssignmentReason) {
+	if m != nil {
+		m.noCandidates.Inc(string(reason))
+	}
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"pr-reviewer-service/internal/entity"
+	"sync"
+)
+
+// Notifier delivers notifications, e.g. to chat. Notify must not block on
+// delivery.
+type Notifier interface {
+	Notify(ctx context.Context, n entity.Notification)
+}
+
+type heldKey struct{}
+
+type heldNotifications struct {
+	mu    sync.Mutex
+	sends []func()
+}
+
+// holdNotifications queues the notifications made with the returned ctx until
+// release is called, so that a rolled back transaction announces nothing.
+// release sends them when send is set and drops them otherwise. Inside an
+// outer hold the queue is the outer one and release does nothing.
+func holdNotifications(ctx context.Context) (context.Context, func(send bool)) {
+	if _, ok := ctx.Value(heldKey{}).(*heldNotifications); ok {
+		return ctx, func(bool) {}
+	}
+
+	held := &heldNotifications{}
+	release := func(send bool) {
+		held.mu.Lock()
+		sends := held.sends
+		held.sends = nil
+		held.mu.Unlock()
+
+		if send {
+			for _, fn := range sends {
+				fn()
+			}
+		}
+	}
+	return context.WithValue(ctx, heldKey{}, held), release
+}
+
+// notify sends n unless notifications are held for ctx. A nil notifier sends nothing.
+func notify(ctx context.Context, notifier Notifier, n entity.Notification) {
+	if notifier == nil {
+		return
+	}
+
+	held, ok := ctx.Value(heldKey{}).(*heldNotifications)
+	if !ok {
+		notifier.Notify(ctx, n)
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	held.mu.Lock()
+	held.sends = append(held.sends, func() { notifier.Notify(ctx, n) })
+	held.mu.Unlock()
+}
@@ -2,118 +2,624 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/internal/usecase/repo"
 	"time"
 )
 
 type PullRequestUseCase struct {
+	tx       repo.Transactor
 	prRepo   repo.PullRequestRepo
 	userRepo repo.UserRepo
+	teamRepo repo.TeamRepo
 	selector *ReviewerSelector
+	audit    *AuditUseCase
+	metrics  *DomainMetrics
+	notifier Notifier
 }
 
-func NewPullRequestUseCase(prr repo.PullRequestRepo, ur repo.UserRepo, rs *ReviewerSelector) *PullRequestUseCase {
+func NewPullRequestUseCase(tx repo.Transactor, prr repo.PullRequestRepo, ur repo.UserRepo, tr repo.TeamRepo, rs *ReviewerSelector, audit *AuditUseCase, m *DomainMetrics, n Notifier) *PullRequestUseCase {
 	return &PullRequestUseCase{
+		tx:       tx,
 		prRepo:   prr,
 		userRepo: ur,
+		teamRepo: tr,
 		selector: rs,
+		audit:    audit,
+		metrics:  m,
+		notifier: n,
 	}
 }
 
 func (uc *PullRequestUseCase) CreatePR(ctx context.Context, prID, prName, authorID string) (entity.PullRequest, error) {
-	exists, err := uc.prRepo.Exists(ctx, prID)
-	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.prRepo.Exists: %w", err)
-	}
-	if exists {
-		return entity.PullRequest{}, entity.ErrPRAlreadyExists
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.CreatePR")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		exists, err := uc.prRepo.Exists(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.prRepo.Exists: %w", err)
+		}
+		if exists {
+			return entity.PullRequest{}, entity.ErrPRAlreadyExists
+		}
+
+		author, err := uc.userRepo.GetByID(ctx, authorID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.userRepo.GetByID: %w", err)
+		}
+
+		team, err := uc.teamRepo.GetByName(ctx, author.TeamName)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.teamRepo.GetByName: %w", err)
+		}
+
+		pools, err := candidatePools(ctx, uc.teamRepo, team)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - %w", err)
+		}
+
+		pr := entity.PullRequest{
+			PullRequestID:     prID,
+			PullRequestName:   prName,
+			AuthorID:          authorID,
+			Status:            entity.StatusOpen,
+			AssignedReviewers: []string{},
+			CreatedAt:         time.Now(),
+		}
+		pr.UpdatedAt = pr.CreatedAt
+		applyPicks(&pr, uc.selector.SelectReviewers(pools, authorID, team.Settings.AutoReviewerCount()))
+
+		if err := uc.prRepo.Create(ctx, pr, entity.AssignmentMeta{Reason: entity.ReasonAuto}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.prRepo.Create: %w", err)
+		}
+		for _, reviewerID := range pr.AssignedReviewers {
+			uc.assigned(ctx, pr, reviewerID, entity.ReasonAuto)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditPRCreate, entity.AuditEntityPullRequest, pr.PullRequestID, nil, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+func (uc *PullRequestUseCase) MergePR(ctx context.Context, prID string) (entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.MergePR")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		pr, err := uc.prRepo.GetByID(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - MergePR - uc.prRepo.GetByID: %w", err)
+		}
+
+		if pr.IsClosed() {
+			return entity.PullRequest{}, entity.ErrPRClosed
+		}
+
+		before := snapshot(pr)
+		wasMerged := pr.IsMerged()
+		pr.Merge()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - MergePR - uc.prRepo.Update: %w", err)
+		}
+		if !wasMerged {
+			uc.merged(ctx, pr)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditPRMerge, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - MergePR - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+func (uc *PullRequestUseCase) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (entity.PullRequest, string, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.ReassignReviewer")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, string, error) {
+		pr, err := uc.prRepo.GetByID(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.prRepo.GetByID: %w", err)
+		}
+
+		if pr.IsMerged() {
+			return entity.PullRequest{}, "", entity.ErrPRAlreadyMerged
+		}
+
+		if pr.IsClosed() {
+			return entity.PullRequest{}, "", entity.ErrPRClosed
+		}
+
+		if !pr.HasReviewer(oldReviewerID) {
+			return entity.PullRequest{}, "", entity.ErrReviewerNotAssigned
+		}
+
+		pools, err := uc.replacementPools(ctx, pr, oldReviewerID)
+		if err != nil {
+			return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - %w", err)
+		}
+
+		replacement, err := uc.selector.FindReplacement(pools, pr.AuthorID, pr.AssignedReviewers)
+		if err != nil {
+			if errors.Is(err, entity.ErrNoCandidates) {
+				uc.noCandidate(ctx, prID, oldReviewerID, entity.ReasonManual)
+			}
+			return entity.PullRequest{}, "", err
+		}
+
+		before := snapshot(pr)
+		pr.ReplaceReviewer(oldReviewerID, replacement.UserID, replacement.TeamName)
+		pr.Touch()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{ActorID: entity.ActorFromContext(ctx), Reason: entity.ReasonManual}); err != nil {
+			return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.prRepo.Update: %w", err)
+		}
+		uc.reassigned(ctx, pr, oldReviewerID, replacement.UserID, entity.ReasonManual)
+
+		if err := uc.audit.Record(ctx, entity.AuditPRReassign, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.audit.Record: %w", err)
+		}
+
+		return pr, replacement.UserID, nil
+	})
+}
+
+// replacementPools lists where a replacement for oldReviewerID is looked for: the team
+// the old reviewer was drawn from first, then the author's team and its fallbacks.
+func (uc *PullRequestUseCase) replacementPools(ctx context.Context, pr entity.PullRequest, oldReviewerID string) ([]TeamPool, error) {
+	sourceTeam := pr.ReviewerTeams[oldReviewerID]
+	if sourceTeam == "" {
+		oldReviewer, err := uc.userRepo.GetByID(ctx, oldReviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("uc.userRepo.GetByID: %w", err)
+		}
+		sourceTeam = oldReviewer.TeamName
 	}
 
-	author, err := uc.userRepo.GetByID(ctx, authorID)
+	author, err := uc.userRepo.GetByID(ctx, pr.AuthorID)
 	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.userRepo.GetByID: %w", err)
+		return nil, fmt.Errorf("uc.userRepo.GetByID: %w", err)
 	}
 
-	teamMembers, err := uc.userRepo.GetByTeam(ctx, author.TeamName)
+	authorTeam, err := uc.teamRepo.GetByName(ctx, author.TeamName)
 	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.userRepo.GetByTeam: %w", err)
+		return nil, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
 	}
 
-	reviewers := uc.selector.SelectReviewers(teamMembers, authorID)
-
-	pr := entity.PullRequest{
-		PullRequestID:     prID,
-		PullRequestName:   prName,
-		AuthorID:          authorID,
-		Status:            entity.StatusOpen,
-		AssignedReviewers: reviewers,
-		CreatedAt:         time.Now(),
+	authorPools, err := candidatePools(ctx, uc.teamRepo, authorTeam)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := uc.prRepo.Create(ctx, pr); err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - CreatePR - uc.prRepo.Create: %w", err)
+	pools := []TeamPool{}
+	if sourceTeam != "" && sourceTeam != authorTeam.TeamName {
+		sourceMembers, err := uc.userRepo.GetByTeam(ctx, sourceTeam)
+		if err != nil {
+			return nil, fmt.Errorf("uc.userRepo.GetByTeam: %w", err)
+		}
+		pools = append(pools, TeamPool{TeamName: sourceTeam, Members: sourceMembers})
 	}
 
-	return pr, nil
+	return append(pools, authorPools...), nil
 }
 
-func (uc *PullRequestUseCase) MergePR(ctx context.Context, prID string) (entity.PullRequest, error) {
-	pr, err := uc.prRepo.GetByID(ctx, prID)
+// ReassignUserReviews moves every OPEN review of userID to a replacement reviewer.
+// Reviews without an available replacement are unassigned. The updated PRs are returned.
+// When fromTeam is set, only reviews the user was drawn into from that team are moved.
+func (uc *PullRequestUseCase) ReassignUserReviews(ctx context.Context, userID, fromTeam string, meta entity.AssignmentMeta) ([]entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.ReassignUserReviews")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) ([]entity.PullRequest, error) {
+		reviews, err := uc.prRepo.GetByReviewer(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("PullRequestUseCase - ReassignUserReviews - uc.prRepo.GetByReviewer: %w", err)
+		}
+
+		updated := []entity.PullRequest{}
+		for _, review := range reviews {
+			if !review.IsOpen() {
+				continue
+			}
+
+			pr, err := uc.prRepo.GetByID(ctx, review.PullRequestID)
+			if err != nil {
+				return updated, fmt.Errorf("PullRequestUseCase - ReassignUserReviews - uc.prRepo.GetByID: %w", err)
+			}
+
+			if source := pr.ReviewerTeams[userID]; fromTeam != "" && source != "" && source != fromTeam {
+				continue
+			}
+
+			pools, err := uc.replacementPools(ctx, pr, userID)
+			if err != nil {
+				return updated, fmt.Errorf("PullRequestUseCase - ReassignUserReviews - %w", err)
+			}
+
+			replacement, err := uc.selector.FindReplacement(pools, pr.AuthorID, pr.AssignedReviewers)
+			found := err == nil
+			switch {
+			case found:
+				pr.ReplaceReviewer(userID, replacement.UserID, replacement.TeamName)
+			case errors.Is(err, entity.ErrNoCandidates):
+				pr.RemoveReviewer(userID)
+			default:
+				return updated, fmt.Errorf("PullRequestUseCase - ReassignUserReviews - uc.selector.FindReplacement: %w", err)
+			}
+
+			if err := uc.prRepo.Update(ctx, pr, meta); err != nil {
+				return updated, fmt.Errorf("PullRequestUseCase - ReassignUserReviews - uc.prRepo.Update: %w", err)
+			}
+
+			if found {
+				uc.reassigned(ctx, pr, userID, replacement.UserID, meta.Reason)
+			} else {
+				uc.noCandidate(ctx, pr.PullRequestID, userID, meta.Reason)
+			}
+
+			updated = append(updated, pr)
+		}
+
+		return updated, nil
+	})
+}
+
+func (uc *PullRequestUseCase) GetTimeline(ctx context.Context, prID string) ([]entity.AssignmentEvent, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.GetTimeline")
+	defer span.End()
+
+	exists, err := uc.prRepo.Exists(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestUseCase - GetTimeline - uc.prRepo.Exists: %w", err)
+	}
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+
+	events, err := uc.prRepo.GetAssignmentEvents(ctx, prID)
 	if err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - MergePR - uc.prRepo.GetByID: %w", err)
+		return nil, fmt.Errorf("PullRequestUseCase - GetTimeline - uc.prRepo.GetAssignmentEvents: %w", err)
 	}
 
-	pr.Merge()
+	return events, nil
+}
+
+// AddReviewer assigns a specific extra reviewer to an open PR.
+func (uc *PullRequestUseCase) AddReviewer(ctx context.Context, prID, userID string) (entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.AddReviewer")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		pr, err := uc.getOpenPR(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - AddReviewer - %w", err)
+		}
+
+		if pr.HasReviewer(userID) {
+			return entity.PullRequest{}, entity.ErrAlreadyAssigned
+		}
 
-	if err := uc.prRepo.Update(ctx, pr); err != nil {
-		return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - MergePR - uc.prRepo.Update: %w", err)
+		candidate, team, err := uc.validateManualReviewer(ctx, pr, userID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - AddReviewer - %w", err)
+		}
+
+		if len(pr.AssignedReviewers) >= team.Settings.MaxReviewers {
+			return entity.PullRequest{}, entity.ErrMaxReviewers
+		}
+
+		before := snapshot(pr)
+		applyPicks(&pr, []Pick{{UserID: userID, TeamName: candidate.TeamName}})
+		pr.Touch()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{ActorID: entity.ActorFromContext(ctx), Reason: entity.ReasonManual}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - AddReviewer - uc.prRepo.Update: %w", err)
+		}
+		uc.assigned(ctx, pr, userID, entity.ReasonManual)
+
+		if err := uc.audit.Record(ctx, entity.AuditPRAddReviewer, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - AddReviewer - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+// RemoveReviewer unassigns a reviewer from an open PR without picking a replacement.
+func (uc *PullRequestUseCase) RemoveReviewer(ctx context.Context, prID, userID string) (entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.RemoveReviewer")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		pr, err := uc.getOpenPR(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - RemoveReviewer - %w", err)
+		}
+
+		if !pr.HasReviewer(userID) {
+			return entity.PullRequest{}, entity.ErrReviewerNotAssigned
+		}
+
+		before := snapshot(pr)
+		pr.RemoveReviewer(userID)
+		pr.Touch()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{ActorID: entity.ActorFromContext(ctx), Reason: entity.ReasonManual}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - RemoveReviewer - uc.prRepo.Update: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditPRRemoveReviewer, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - RemoveReviewer - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+// ReassignReviewerTo replaces a reviewer with an explicitly chosen one.
+func (uc *PullRequestUseCase) ReassignReviewerTo(ctx context.Context, prID, oldReviewerID, newReviewerID string) (entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.ReassignReviewerTo")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		pr, err := uc.getOpenPR(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - ReassignReviewerTo - %w", err)
+		}
+
+		if !pr.HasReviewer(oldReviewerID) {
+			return entity.PullRequest{}, entity.ErrReviewerNotAssigned
+		}
+		if pr.HasReviewer(newReviewerID) {
+			return entity.PullRequest{}, entity.ErrAlreadyAssigned
+		}
+
+		candidate, _, err := uc.validateManualReviewer(ctx, pr, newReviewerID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - ReassignReviewerTo - %w", err)
+		}
+
+		before := snapshot(pr)
+		pr.ReplaceReviewer(oldReviewerID, newReviewerID, candidate.TeamName)
+		pr.Touch()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{ActorID: entity.ActorFromContext(ctx), Reason: entity.ReasonManual}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - ReassignReviewerTo - uc.prRepo.Update: %w", err)
+		}
+		uc.reassigned(ctx, pr, oldReviewerID, newReviewerID, entity.ReasonManual)
+
+		if err := uc.audit.Record(ctx, entity.AuditPRReassign, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - ReassignReviewerTo - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+// SubmitVerdict records an assigned reviewer's verdict on an open PR. An
+// approval is kept on the PR; a decline hands the review to a replacement
+// drawn like a random reassignment, or just removes the reviewer when no
+// candidate is left.
+func (uc *PullRequestUseCase) SubmitVerdict(ctx context.Context, prID, reviewerID string, verdict entity.Verdict) (entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PullRequestUseCase.SubmitVerdict")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.PullRequest, error) {
+		if !verdict.IsValid() {
+			return entity.PullRequest{}, entity.ErrInvalidVerdict
+		}
+
+		pr, err := uc.getOpenPR(ctx, prID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - %w", err)
+		}
+
+		if !pr.HasReviewer(reviewerID) {
+			return entity.PullRequest{}, entity.ErrReviewerNotAssigned
+		}
+
+		if verdict == entity.VerdictApprove {
+			if pr.HasApproved(reviewerID) {
+				return pr, nil
+			}
+
+			before := snapshot(pr)
+			pr.Approve(reviewerID)
+			pr.Touch()
+
+			if err := uc.prRepo.Approve(ctx, prID, reviewerID, pr.UpdatedAt); err != nil {
+				return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - uc.prRepo.Approve: %w", err)
+			}
+
+			if err := uc.audit.Record(ctx, entity.AuditPRApprove, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+				return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - uc.audit.Record: %w", err)
+			}
+
+			return pr, nil
+		}
+
+		pools, err := uc.replacementPools(ctx, pr, reviewerID)
+		if err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - %w", err)
+		}
+
+		before := snapshot(pr)
+		replacement, err := uc.selector.FindReplacement(pools, pr.AuthorID, pr.AssignedReviewers)
+		found := err == nil
+		switch {
+		case errors.Is(err, entity.ErrNoCandidates):
+			pr.RemoveReviewer(reviewerID)
+		case err != nil:
+			return entity.PullRequest{}, err
+		default:
+			pr.ReplaceReviewer(reviewerID, replacement.UserID, replacement.TeamName)
+		}
+		pr.Touch()
+
+		if err := uc.prRepo.Update(ctx, pr, entity.AssignmentMeta{ActorID: entity.ActorFromContext(ctx), Reason: entity.ReasonDecline}); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - uc.prRepo.Update: %w", err)
+		}
+
+		if found {
+			uc.reassigned(ctx, pr, reviewerID, replacement.UserID, entity.ReasonDecline)
+		} else {
+			uc.noCandidate(ctx, prID, reviewerID, entity.ReasonDecline)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditPRDecline, entity.AuditEntityPullRequest, pr.PullRequestID, before, pr); err != nil {
+			return entity.PullRequest{}, fmt.Errorf("PullRequestUseCase - SubmitVerdict - uc.audit.Record: %w", err)
+		}
+
+		return pr, nil
+	})
+}
+
+// assigned notifies a reviewer newly assigned to a PR.
+func (uc *PullRequestUseCase) assigned(ctx context.Context, pr entity.PullRequest, reviewerID string, reason entity.AssignmentReason) {
+	notify(ctx, uc.notifier, entity.Notification{
+		Event:           entity.NotifyAssigned,
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		ReviewerID:      reviewerID,
+		TeamName:        pr.ReviewerTeams[reviewerID],
+		Reason:          reason,
+	})
+}
+
+// reassigned logs, counts and notifies a reviewer replaced on a PR.
+func (uc *PullRequestUseCase) reassigned(ctx context.Context, pr entity.PullRequest, oldReviewerID, newReviewerID string, reason entity.AssignmentReason) {
+	slog.InfoContext(ctx, "reviewer reassigned",
+		"pull_request_id", pr.PullRequestID, "old_reviewer_id", oldReviewerID, "new_reviewer_id", newReviewerID, "reason", reason)
+	uc.metrics.reassigned(reason)
+
+	notify(ctx, uc.notifier, entity.Notification{
+		Event:              entity.NotifyReassigned,
+		PullRequestID:      pr.PullRequestID,
+		PullRequestName:    pr.PullRequestName,
+		AuthorID:           pr.AuthorID,
+		ReviewerID:         newReviewerID,
+		PreviousReviewerID: oldReviewerID,
+		TeamName:           pr.ReviewerTeams[newReviewerID],
+		Reason:             reason,
+	})
+}
+
+// merged notifies the reviewers and the author's team of a merged PR.
+func (uc *PullRequestUseCase) merged(ctx context.Context, pr entity.PullRequest) {
+	if uc.notifier == nil {
+		return
 	}
 
-	return pr, nil
+	// The merge is done: a failed lookup only leaves the team out
+	author, err := uc.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		slog.WarnContext(ctx, "merge notification without author team", "pull_request_id", pr.PullRequestID, "error", err)
+	}
+
+	notify(ctx, uc.notifier, entity.Notification{
+		Event:           entity.NotifyMerged,
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		Reviewers:       pr.AssignedReviewers,
+		TeamName:        author.TeamName,
+	})
 }
 
-func (uc *PullRequestUseCase) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (entity.PullRequest, string, error) {
+// noCandidate logs and counts a reviewer that could not be replaced.
+func (uc *PullRequestUseCase) noCandidate(ctx context.Context, prID, reviewerID string, reason entity.AssignmentReason) {
+	slog.WarnContext(ctx, "no replacement reviewer available",
+		"pull_request_id", prID, "reviewer_id", reviewerID, "reason", reason)
+	uc.metrics.noCandidate(reason)
+}
+
+func (uc *PullRequestUseCase) getOpenPR(ctx context.Context, prID string) (entity.PullRequest, error) {
 	pr, err := uc.prRepo.GetByID(ctx, prID)
 	if err != nil {
-		return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.prRepo.GetByID: %w", err)
+		return entity.PullRequest{}, fmt.Errorf("uc.prRepo.GetByID: %w", err)
 	}
 
 	if pr.IsMerged() {
-		return entity.PullRequest{}, "", entity.ErrPRAlreadyMerged
+		return entity.PullRequest{}, entity.ErrPRAlreadyMerged
+	}
+	if pr.IsClosed() {
+		return entity.PullRequest{}, entity.ErrPRClosed
+	}
+
+	return pr, nil
+}
+
+// validateManualReviewer checks that userID may review pr and returns the candidate,
+// with TeamName set to the team the reviewer is drawn from, together with the
+// author's team, whose settings govern the PR's reviewer limits.
+func (uc *PullRequestUseCase) validateManualReviewer(ctx context.Context, pr entity.PullRequest, userID string) (entity.User, entity.Team, error) {
+	if userID == pr.AuthorID {
+		return entity.User{}, entity.Team{}, entity.ErrReviewerIsAuthor
 	}
 
-	if !pr.HasReviewer(oldReviewerID) {
-		return entity.PullRequest{}, "", entity.ErrReviewerNotAssigned
+	candidate, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.User{}, entity.Team{}, fmt.Errorf("uc.userRepo.GetByID: %w", err)
+	}
+	if !candidate.IsActive {
+		return entity.User{}, entity.Team{}, entity.ErrUserInactive
 	}
 
-	oldReviewer, err := uc.userRepo.GetByID(ctx, oldReviewerID)
+	author, err := uc.userRepo.GetByID(ctx, pr.AuthorID)
 	if err != nil {
-		return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.userRepo.GetByID: %w", err)
+		return entity.User{}, entity.Team{}, fmt.Errorf("uc.userRepo.GetByID: %w", err)
 	}
 
-	teamMembers, err := uc.userRepo.GetByTeam(ctx, oldReviewer.TeamName)
+	team, err := uc.teamRepo.GetByName(ctx, author.TeamName)
 	if err != nil {
-		return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.userRepo.GetByTeam: %w", err)
+		return entity.User{}, entity.Team{}, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
 	}
 
-	newReviewerID, err := uc.selector.FindReplacement(teamMembers, pr.AuthorID, pr.AssignedReviewers)
+	memberships, err := uc.userRepo.GetMemberships(ctx, userID)
 	if err != nil {
-		return entity.PullRequest{}, "", err
+		return entity.User{}, entity.Team{}, fmt.Errorf("uc.userRepo.GetMemberships: %w", err)
 	}
 
-	// Replace reviewer
-	for i, reviewerID := range pr.AssignedReviewers {
-		if reviewerID == oldReviewerID {
-			pr.AssignedReviewers[i] = newReviewerID
+	active := make(map[string]bool, len(memberships))
+	for _, m := range memberships {
+		active[m.TeamName] = m.IsActive
+	}
+
+	// Prefer the author's team, then partner teams in priority order
+	sourceTeam := ""
+	for _, name := range append([]string{team.TeamName}, team.Settings.FallbackTeams...) {
+		if active[name] {
+			sourceTeam = name
 			break
 		}
 	}
 
-	if err := uc.prRepo.Update(ctx, pr); err != nil {
-		return entity.PullRequest{}, "", fmt.Errorf("PullRequestUseCase - ReassignReviewer - uc.prRepo.Update: %w", err)
+	if sourceTeam == "" && team.Settings.AllowCrossTeam {
+		for _, m := range memberships {
+			if m.IsActive {
+				sourceTeam = m.TeamName
+				break
+			}
+		}
+	}
+
+	if sourceTeam == "" {
+		if _, isMember := active[team.TeamName]; isMember {
+			return entity.User{}, entity.Team{}, entity.ErrUserInactive
+		}
+		return entity.User{}, entity.Team{}, entity.ErrCrossTeamNotAllowed
 	}
 
-	return pr, newReviewerID, nil
+	candidate.TeamName = sourceTeam
+	return candidate, team, nil
 }
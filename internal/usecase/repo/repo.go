@@ -3,31 +3,75 @@ package repo
 import (
 	"context"
 	"pr-reviewer-service/internal/entity"
+	"time"
 )
 
 type (
+	// Transactor runs fn in a transaction. Repositories called with the ctx
+	// passed to fn take part in it.
+	Transactor interface {
+		InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	}
+
 	UserRepo interface {
 		Create(ctx context.Context, user entity.User) error
 		GetByID(ctx context.Context, userID string) (entity.User, error)
 		Update(ctx context.Context, user entity.User) error
 		GetByTeam(ctx context.Context, teamName string) ([]entity.User, error)
 		DeactivateTeam(ctx context.Context, teamName string) error
+		GetManagedBy(ctx context.Context, managedBy string) ([]entity.User, error)
+		GetMemberships(ctx context.Context, userID string) ([]entity.Membership, error)
+		SaveMembership(ctx context.Context, userID string, m entity.Membership) error
+		RemoveMembership(ctx context.Context, userID, teamName string) error
+		Erase(ctx context.Context, userID, anonymousID string) error
+		List(ctx context.Context, filter entity.UserFilter) ([]entity.UserSummary, int, error)
 	}
 
 	TeamRepo interface {
 		Create(ctx context.Context, team entity.Team) error
 		GetByName(ctx context.Context, teamName string) (entity.Team, error)
 		Exists(ctx context.Context, teamName string) (bool, error)
+		UpdateSettings(ctx context.Context, teamName string, settings entity.TeamSettings) error
+		SetParent(ctx context.Context, teamName, parentTeam string) error
+		LockHierarchy(ctx context.Context) error
+		GetTree(ctx context.Context, root string) ([]entity.Team, error)
+		GetAncestors(ctx context.Context, teamName string) ([]string, error)
+		GetChildren(ctx context.Context, teamName string) ([]string, error)
+		Archive(ctx context.Context, teamName string) error
 	}
 
 	PullRequestRepo interface {
-		Create(ctx context.Context, pr entity.PullRequest) error
+		Create(ctx context.Context, pr entity.PullRequest, meta entity.AssignmentMeta) error
 		GetByID(ctx context.Context, prID string) (entity.PullRequest, error)
-		Update(ctx context.Context, pr entity.PullRequest) error
+		Update(ctx context.Context, pr entity.PullRequest, meta entity.AssignmentMeta) error
 		GetByReviewer(ctx context.Context, userID string) ([]entity.PullRequest, error)
 		Exists(ctx context.Context, prID string) (bool, error)
 		GetUserStats(ctx context.Context) ([]entity.UserStats, error)
 		GetPRStats(ctx context.Context) (*entity.PRStats, error)
+		GetTeamStats(ctx context.Context) ([]entity.TeamStats, error)
+		GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
 		GetOpenPRsByTeam(ctx context.Context, teamName string) ([]entity.PullRequest, error)
+		GetStale(ctx context.Context, before time.Time) ([]entity.PullRequest, error)
+		ClaimSLABreaches(ctx context.Context, deadline, at time.Time) ([]entity.PendingReview, error)
+		GetAssignmentEvents(ctx context.Context, prID string) ([]entity.AssignmentEvent, error)
+		Approve(ctx context.Context, prID, reviewerID string, at time.Time) error
+	}
+
+	AuditRepo interface {
+		Create(ctx context.Context, entry entity.AuditEntry) error
+		List(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, int, error)
+	}
+
+	TokenRepo interface {
+		Create(ctx context.Context, token entity.APIToken, hash string) (entity.APIToken, error)
+		GetByHash(ctx context.Context, hash string) (entity.APIToken, error)
+		List(ctx context.Context) ([]entity.APIToken, error)
+		Revoke(ctx context.Context, tokenID int64, at time.Time) error
+		TouchLastUsed(ctx context.Context, tokenID int64, at time.Time) error
+	}
+
+	// DirectoryRepo reads users and groups from an external directory.
+	DirectoryRepo interface {
+		Fetch(ctx context.Context) (entity.Directory, error)
 	}
 )
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"time"
+)
+
+// ReviewSLAUseCase reports reviewers that gave no verdict within the review SLA.
+type ReviewSLAUseCase struct {
+	prRepo   repo.PullRequestRepo
+	notifier Notifier
+	sla      time.Duration
+}
+
+func NewReviewSLAUseCase(prr repo.PullRequestRepo, n Notifier, sla time.Duration) *ReviewSLAUseCase {
+	return &ReviewSLAUseCase{
+		prRepo:   prr,
+		notifier: n,
+		sla:      sla,
+	}
+}
+
+// NotifyBreaches notifies the reviewers whose SLA ran out by now and were not
+// notified yet. Each breach is claimed in the database before it is sent, so
+// it is reported at most once however often and on however many replicas the
+// check runs.
+func (uc *ReviewSLAUseCase) NotifyBreaches(ctx context.Context, now time.Time) ([]entity.PendingReview, error) {
+	ctx, span := tracer.Start(ctx, "ReviewSLAUseCase.NotifyBreaches")
+	defer span.End()
+
+	breaches, err := uc.prRepo.ClaimSLABreaches(ctx, now.Add(-uc.sla), now)
+	if err != nil {
+		return nil, fmt.Errorf("ReviewSLAUseCase - NotifyBreaches - uc.prRepo.ClaimSLABreaches: %w", err)
+	}
+
+	for _, review := range breaches {
+		notify(ctx, uc.notifier, entity.Notification{
+			Event:           entity.NotifySLABreach,
+			PullRequestID:   review.PullRequestID,
+			PullRequestName: review.PullRequestName,
+			AuthorID:        review.AuthorID,
+			ReviewerID:      review.ReviewerID,
+			TeamName:        review.SourceTeam,
+			AssignedAt:      review.AssignedAt,
+		})
+	}
+
+	return breaches, nil
+}
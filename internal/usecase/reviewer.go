@@ -1,54 +1,87 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
 )
 
+// TeamPool is a group of reviewer candidates drawn from one team.
+type TeamPool struct {
+	TeamName string
+	Members  []entity.User
+}
+
+// Pick is a reviewer chosen by the selector together with the team it was drawn from.
+type Pick struct {
+	UserID   string
+	TeamName string
+}
+
 type ReviewerSelector struct{}
 
 func NewReviewerSelector() *ReviewerSelector {
 	return &ReviewerSelector{}
 }
 
-func (rs *ReviewerSelector) SelectReviewers(teamMembers []entity.User, authorID string) []string {
-	candidates := rs.getCandidates(teamMembers, authorID, []string{})
-	return rs.randomPick(candidates, 2)
-}
+// SelectReviewers picks up to count reviewers, exhausting each pool in priority order
+// before spilling over to the next one.
+func (rs *ReviewerSelector) SelectReviewers(pools []TeamPool, authorID string, count int) []Pick {
+	picks := []Pick{}
+	exclude := []string{}
 
-func (rs *ReviewerSelector) FindReplacement(teamMembers []entity.User, authorID string, currentReviewers []string) (string, error) {
-	candidates := rs.getCandidates(teamMembers, authorID, currentReviewers)
+	for _, pool := range pools {
+		if len(picks) >= count {
+			break
+		}
 
-	if len(candidates) == 0 {
-		return "", entity.ErrNoCandidates
+		candidates := rs.getCandidates(pool.Members, authorID, exclude)
+		for _, userID := range rs.randomPick(candidates, count-len(picks)) {
+			picks = append(picks, Pick{UserID: userID, TeamName: pool.TeamName})
+			exclude = append(exclude, userID)
+		}
 	}
 
-	selected := rs.randomPick(candidates, 1)
-	if len(selected) == 0 {
-		return "", entity.ErrNoCandidates
+	return picks
+}
+
+// FindReplacement picks one reviewer from the first pool that has a candidate.
+func (rs *ReviewerSelector) FindReplacement(pools []TeamPool, authorID string, currentReviewers []string) (Pick, error) {
+	for _, pool := range pools {
+		candidates := rs.getCandidates(pool.Members, authorID, currentReviewers)
+
+		selected := rs.randomPick(candidates, 1)
+		if len(selected) > 0 {
+			return Pick{UserID: selected[0], TeamName: pool.TeamName}, nil
+		}
 	}
 
-	return selected[0], nil
+	return Pick{}, entity.ErrNoCandidates
 }
 
-func (rs *ReviewerSelector) getCandidates(teamMembers []entity.User, authorID string, exclude []string) []string {
+func (rs *ReviewerSelector) getCandidates(teamMembers []entity.User, authorID string, exclude []string) []entity.User {
 	excludeMap := make(map[string]bool)
 	excludeMap[authorID] = true
 	for _, userID := range exclude {
 		excludeMap[userID] = true
 	}
 
-	var candidates []string
+	var candidates []entity.User
 	for _, member := range teamMembers {
-		if member.IsActive && !excludeMap[member.UserID] {
-			candidates = append(candidates, member.UserID)
+		if member.IsActive && member.ReviewWeight > 0 && !excludeMap[member.UserID] {
+			candidates = append(candidates, member)
 		}
 	}
 
 	return candidates
 }
 
-func (rs *ReviewerSelector) randomPick(candidates []string, count int) []string {
+// randomPick draws up to count distinct candidates, each with a probability
+// proportional to its review weight.
+func (rs *ReviewerSelector) randomPick(candidates []entity.User, count int) []string {
 	if len(candidates) == 0 {
 		return []string{}
 	}
@@ -57,13 +90,98 @@ func (rs *ReviewerSelector) randomPick(candidates []string, count int) []string
 		count = len(candidates)
 	}
 
-	shuffled := make([]string, len(candidates))
-	copy(shuffled, candidates)
+	remaining := make([]entity.User, len(candidates))
+	copy(remaining, candidates)
+
+	total := 0
+	for _, c := range remaining {
+		total += c.ReviewWeight
+	}
+
+	picked := make([]string, 0, count)
+	for len(picked) < count {
+		n := rand.Intn(total)
+		i := 0
+		for ; n >= remaining[i].ReviewWeight; i++ {
+			n -= remaining[i].ReviewWeight
+		}
+
+		picked = append(picked, remaining[i].UserID)
+		total -= remaining[i].ReviewWeight
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+
+	return picked
+}
+
+// candidatePools returns the team itself, its fallback teams in priority order and
+// then the team hierarchy escalation: sibling teams, the parent team, the parent's
+// siblings and so on up to the root. The team's leads come last as escalation
+// reviewers. Teams that no longer exist or are archived are skipped and each team
+// appears at most once.
+func candidatePools(ctx context.Context, tr repo.TeamRepo, team entity.Team) ([]TeamPool, error) {
+	pools := []TeamPool{{TeamName: team.TeamName, Members: team.Members}}
+	seen := map[string]bool{team.TeamName: true}
+
+	add := func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+
+		t, err := tr.GetByName(ctx, name)
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tr.GetByName: %w", err)
+		}
+		if t.IsArchived() {
+			return nil
+		}
+
+		pools = append(pools, TeamPool{TeamName: t.TeamName, Members: t.Members})
+		return nil
+	}
+
+	for _, name := range team.Settings.FallbackTeams {
+		if err := add(name); err != nil {
+			return nil, fmt.Errorf("candidatePools - %w", err)
+		}
+	}
 
-	for i := len(shuffled) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	ancestors, err := tr.GetAncestors(ctx, team.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("candidatePools - tr.GetAncestors: %w", err)
 	}
 
-	return shuffled[:count]
+	for _, parent := range ancestors {
+		siblings, err := tr.GetChildren(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("candidatePools - tr.GetChildren: %w", err)
+		}
+
+		for _, name := range siblings {
+			if err := add(name); err != nil {
+				return nil, fmt.Errorf("candidatePools - %w", err)
+			}
+		}
+		if err := add(parent); err != nil {
+			return nil, fmt.Errorf("candidatePools - %w", err)
+		}
+	}
+
+	return append(pools, escalationPool(team)), nil
+}
+
+// applyPicks stores picked reviewers on the PR together with their source teams.
+func applyPicks(pr *entity.PullRequest, picks []Pick) {
+	if pr.ReviewerTeams == nil {
+		pr.ReviewerTeams = make(map[string]string, len(picks))
+	}
+
+	for _, p := range picks {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, p.UserID)
+		pr.ReviewerTeams[p.UserID] = p.TeamName
+	}
 }
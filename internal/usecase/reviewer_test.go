@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+func member(userID string, active bool) entity.User {
+	return entity.User{UserID: userID, IsActive: active, ReviewWeight: entity.DefaultReviewWeight}
+}
+
+// teamsOf counts the picks drawn from each team.
+func teamsOf(picks []usecase.Pick) map[string]int {
+	teams := map[string]int{}
+	for _, p := range picks {
+		teams[p.TeamName]++
+	}
+	return teams
+}
+
+func TestSelectReviewersSpillsOver(t *testing.T) {
+	partner := usecase.TeamPool{TeamName: "platform", Members: []entity.User{member("p1", true), member("p2", true)}}
+
+	tests := []struct {
+		name  string
+		local []entity.User
+		want  map[string]int
+	}{
+		{
+			name:  "enough local candidates",
+			local: []entity.User{member("u1", true), member("u2", true), member("u3", true)},
+			want:  map[string]int{"backend": 2},
+		},
+		{
+			name:  "one local candidate",
+			local: []entity.User{member("u1", true), member("u2", true), member("u3", false)},
+			want:  map[string]int{"backend": 1, "platform": 1},
+		},
+		{
+			name:  "no local candidates",
+			local: []entity.User{member("u1", true), member("u2", false), {UserID: "u3", IsActive: true}},
+			want:  map[string]int{"platform": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pools := []usecase.TeamPool{{TeamName: "backend", Members: tt.local}, partner}
+
+			picks := usecase.NewReviewerSelector().SelectReviewers(pools, "u1", entity.AutoReviewers)
+
+			got := teamsOf(picks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("picks = %+v, want per team %v", picks, tt.want)
+			}
+			for team, n := range tt.want {
+				if got[team] != n {
+					t.Errorf("picks = %+v, want per team %v", picks, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindReplacementSpillsOver(t *testing.T) {
+	selector := usecase.NewReviewerSelector()
+	local := usecase.TeamPool{TeamName: "backend", Members: []entity.User{member("u1", true), member("u2", true)}}
+	partner := usecase.TeamPool{TeamName: "platform", Members: []entity.User{member("p1", true)}}
+
+	pick, err := selector.FindReplacement([]usecase.TeamPool{local, partner}, "u1", []string{"u2"})
+	if err != nil {
+		t.Fatalf("FindReplacement: %v", err)
+	}
+	if pick != (usecase.Pick{UserID: "p1", TeamName: "platform"}) {
+		t.Errorf("pick = %+v, want p1 from platform", pick)
+	}
+
+	_, err = selector.FindReplacement([]usecase.TeamPool{local, partner}, "u1", []string{"u2", "p1"})
+	if !errors.Is(err, entity.ErrNoCandidates) {
+		t.Errorf("FindReplacement error = %v, want %v", err, entity.ErrNoCandidates)
+	}
+}
+
+func TestCreatePRRecordsFallbackTeam(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2")
+	s.addTeam("platform", "p1")
+	backend := s.Teams["backend"]
+	backend.Settings.FallbackTeams = []string{"platform"}
+	s.Teams["backend"] = backend
+
+	pr, err := newPRUseCase(s, &notifier{}).CreatePR(context.Background(), "pr-1", "Fix", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+
+	want := map[string]string{"u2": "backend", "p1": "platform"}
+	if len(pr.ReviewerTeams) != len(want) {
+		t.Fatalf("reviewer teams = %v, want %v", pr.ReviewerTeams, want)
+	}
+	for reviewer, team := range want {
+		if pr.ReviewerTeams[reviewer] != team {
+			t.Errorf("reviewer teams = %v, want %v", pr.ReviewerTeams, want)
+		}
+	}
+}
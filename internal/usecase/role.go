@@ -0,0 +1,16 @@
+package usecase
+
+import "pr-reviewer-service/internal/entity"
+
+// escalationPool holds the team's active leads. They are drawn only once the
+// regular pools are exhausted, regardless of their review weight.
+func escalationPool(team entity.Team) TeamPool {
+	pool := TeamPool{TeamName: team.TeamName}
+	for _, member := range team.Members {
+		if member.Role == entity.RoleLead && member.IsActive {
+			member.ReviewWeight = entity.DefaultReviewWeight
+			pool.Members = append(pool.Members, member)
+		}
+	}
+	return pool
+}
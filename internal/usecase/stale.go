@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"time"
+)
+
+const _defaultStaleThreshold = 30 * 24 * time.Hour
+
+type StaleUseCase struct {
+	tx        repo.Transactor
+	prRepo    repo.PullRequestRepo
+	threshold time.Duration
+	action    entity.StaleAction
+	audit     *AuditUseCase
+}
+
+func NewStaleUseCase(tx repo.Transactor, prr repo.PullRequestRepo, threshold time.Duration, action entity.StaleAction, audit *AuditUseCase) *StaleUseCase {
+	if threshold <= 0 {
+		threshold = _defaultStaleThreshold
+	}
+
+	return &StaleUseCase{
+		tx:        tx,
+		prRepo:    prr,
+		threshold: threshold,
+		action:    action,
+		audit:     audit,
+	}
+}
+
+// GetStalePRs reports OPEN PRs idle for longer than threshold.
+// A zero threshold falls back to the configured one.
+func (uc *StaleUseCase) GetStalePRs(ctx context.Context, threshold time.Duration) (entity.StaleReport, error) {
+	ctx, span := tracer.Start(ctx, "StaleUseCase.GetStalePRs")
+	defer span.End()
+
+	if threshold <= 0 {
+		threshold = uc.threshold
+	}
+
+	cutoff := time.Now().Add(-threshold)
+
+	prs, err := uc.prRepo.GetStale(ctx, cutoff)
+	if err != nil {
+		return entity.StaleReport{}, fmt.Errorf("StaleUseCase - GetStalePRs - uc.prRepo.GetStale: %w", err)
+	}
+
+	return entity.StaleReport{
+		Threshold:    threshold.String(),
+		Cutoff:       cutoff,
+		DryRun:       true,
+		PullRequests: prs,
+	}, nil
+}
+
+// ProcessStalePRs applies the configured stale action to every stale PR.
+// In dry-run mode it only reports the PRs that would be affected.
+func (uc *StaleUseCase) ProcessStalePRs(ctx context.Context, dryRun bool) (entity.StaleReport, error) {
+	ctx, span := tracer.Start(ctx, "StaleUseCase.ProcessStalePRs")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.StaleReport, error) {
+		report, err := uc.GetStalePRs(ctx, uc.threshold)
+		if err != nil {
+			return entity.StaleReport{}, fmt.Errorf("StaleUseCase - ProcessStalePRs: %w", err)
+		}
+
+		report.Action = uc.action
+		report.DryRun = dryRun
+
+		affected := make([]entity.PullRequest, 0, len(report.PullRequests))
+		for _, pr := range report.PullRequests {
+			// Already marked PRs have no reviewers left, nothing to do.
+			if uc.action == entity.StaleActionMark && pr.StaleAt != nil {
+				continue
+			}
+
+			if dryRun {
+				affected = append(affected, pr)
+				continue
+			}
+
+			fullPR, err := uc.prRepo.GetByID(ctx, pr.PullRequestID)
+			if err != nil {
+				return entity.StaleReport{}, fmt.Errorf("StaleUseCase - ProcessStalePRs - uc.prRepo.GetByID: %w", err)
+			}
+
+			before := snapshot(fullPR)
+			if uc.action == entity.StaleActionClose {
+				fullPR.Close()
+			} else {
+				fullPR.MarkStale()
+			}
+
+			if err := uc.prRepo.Update(ctx, fullPR, entity.AssignmentMeta{Reason: entity.ReasonStale}); err != nil {
+				return entity.StaleReport{}, fmt.Errorf("StaleUseCase - ProcessStalePRs - uc.prRepo.Update: %w", err)
+			}
+
+			if err := uc.audit.Record(ctx, entity.AuditPRStale, entity.AuditEntityPullRequest, fullPR.PullRequestID, before, fullPR); err != nil {
+				return entity.StaleReport{}, fmt.Errorf("StaleUseCase - ProcessStalePRs - uc.audit.Record: %w", err)
+			}
+
+			affected = append(affected, fullPR)
+		}
+
+		report.PullRequests = affected
+		return report, nil
+	})
+}
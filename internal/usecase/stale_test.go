@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+const staleThreshold = 30 * 24 * time.Hour
+
+// stalePRs opens pr-old, idle past the threshold, pr-new, recently updated,
+// pr-marked, idle and already marked, and pr-merged, idle but merged.
+func stalePRs(s *store) {
+	idle := time.Now().Add(-40 * 24 * time.Hour)
+	openReview(s, "pr-old", "u1", "u2", "backend")
+	openReview(s, "pr-new", "u1", "u2", "backend")
+	openReview(s, "pr-marked", "u1", "u2", "backend")
+	openReview(s, "pr-merged", "u1", "u2", "backend")
+
+	for id, pr := range s.PRs {
+		pr.UpdatedAt = idle
+		switch id {
+		case "pr-new":
+			pr.UpdatedAt = time.Now().Add(-24 * time.Hour)
+		case "pr-marked":
+			pr.MarkStale()
+			pr.UpdatedAt = idle.Add(time.Hour)
+		case "pr-merged":
+			pr.Status = entity.StatusMerged
+		}
+		s.PRs[id] = pr
+	}
+}
+
+func prIDs(prs []entity.PullRequest) []string {
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
+
+func TestGetStalePRs(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2")
+	stalePRs(s)
+	uc := usecase.NewStaleUseCase(s, s.prs(), staleThreshold, entity.StaleActionMark, usecase.NewAuditUseCase(s.auditLog()))
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		want      []string
+	}{
+		{name: "configured threshold", want: []string{"pr-old", "pr-marked"}},
+		{name: "shorter threshold", threshold: 12 * time.Hour, want: []string{"pr-old", "pr-marked", "pr-new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := uc.GetStalePRs(context.Background(), tt.threshold)
+			if err != nil {
+				t.Fatalf("GetStalePRs: %v", err)
+			}
+			if got := prIDs(report.PullRequests); !slices.Equal(got, tt.want) {
+				t.Errorf("stale PRs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessStalePRs(t *testing.T) {
+	tests := []struct {
+		name   string
+		action entity.StaleAction
+		dryRun bool
+		want   []string
+		// wantStatus is the status of pr-old afterwards
+		wantStatus entity.PRStatus
+	}{
+		{name: "dry run changes nothing", action: entity.StaleActionClose, dryRun: true, want: []string{"pr-old", "pr-marked"}, wantStatus: entity.StatusOpen},
+		{name: "mark skips marked PRs", action: entity.StaleActionMark, want: []string{"pr-old"}, wantStatus: entity.StatusOpen},
+		{name: "close closes marked PRs too", action: entity.StaleActionClose, want: []string{"pr-old", "pr-marked"}, wantStatus: entity.StatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			s.addTeam("backend", "u1", "u2")
+			stalePRs(s)
+			uc := usecase.NewStaleUseCase(s, s.prs(), staleThreshold, tt.action, usecase.NewAuditUseCase(s.auditLog()))
+
+			report, err := uc.ProcessStalePRs(context.Background(), tt.dryRun)
+			if err != nil {
+				t.Fatalf("ProcessStalePRs: %v", err)
+			}
+
+			if got := prIDs(report.PullRequests); !slices.Equal(got, tt.want) {
+				t.Errorf("affected = %v, want %v", got, tt.want)
+			}
+			old := s.PRs["pr-old"]
+			if old.Status != tt.wantStatus {
+				t.Errorf("pr-old status = %s, want %s", old.Status, tt.wantStatus)
+			}
+			if released := len(old.AssignedReviewers) == 0; released == tt.dryRun {
+				t.Errorf("pr-old reviewers = %v, want released %v", old.AssignedReviewers, !tt.dryRun)
+			}
+			if got := s.PRs["pr-new"]; len(got.AssignedReviewers) != 1 || got.StaleAt != nil {
+				t.Errorf("pr-new = %+v, want it untouched", got)
+			}
+
+			wantAudit := len(tt.want)
+			if tt.dryRun {
+				wantAudit = 0
+			}
+			if len(s.Audit) != wantAudit {
+				t.Errorf("audit entries = %d, want %d", len(s.Audit), wantAudit)
+			}
+		})
+	}
+}
@@ -20,6 +20,9 @@ func NewStatsUseCase(prRepo repo.PullRequestRepo, userRepo repo.UserRepo) *Stats
 }
 
 func (uc *StatsUseCase) GetUserStats(ctx context.Context) ([]entity.UserStats, error) {
+	ctx, span := tracer.Start(ctx, "StatsUseCase.GetUserStats")
+	defer span.End()
+
 	stats, err := uc.prRepo.GetUserStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("StatsUseCase - GetUserStats: %w", err)
@@ -28,9 +31,48 @@ func (uc *StatsUseCase) GetUserStats(ctx context.Context) ([]entity.UserStats, e
 }
 
 func (uc *StatsUseCase) GetPRStats(ctx context.Context) (*entity.PRStats, error) {
+	ctx, span := tracer.Start(ctx, "StatsUseCase.GetPRStats")
+	defer span.End()
+
 	stats, err := uc.prRepo.GetPRStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("StatsUseCase - GetPRStats: %w", err)
 	}
 	return stats, nil
 }
+
+// GetTeamStats returns rolled-up team statistics. When root is set only root and
+// its sub-teams are returned.
+func (uc *StatsUseCase) GetTeamStats(ctx context.Context, root string) ([]entity.TeamStats, error) {
+	ctx, span := tracer.Start(ctx, "StatsUseCase.GetTeamStats")
+	defer span.End()
+
+	stats, err := uc.prRepo.GetTeamStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("StatsUseCase - GetTeamStats: %w", err)
+	}
+
+	if root == "" {
+		return stats, nil
+	}
+
+	parents := make(map[string]string, len(stats))
+	for _, s := range stats {
+		parents[s.TeamName] = s.ParentTeam
+	}
+	if _, ok := parents[root]; !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	subtree := []entity.TeamStats{}
+	for _, s := range stats {
+		for name := s.TeamName; name != ""; name = parents[name] {
+			if name == root {
+				subtree = append(subtree, s)
+				break
+			}
+		}
+	}
+
+	return subtree, nil
+}
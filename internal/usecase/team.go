@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/internal/usecase/repo"
@@ -9,86 +10,766 @@ import (
 )
 
 type TeamUseCase struct {
+	tx       repo.Transactor
 	teamRepo repo.TeamRepo
 	userRepo repo.UserRepo
+	prUC     *PullRequestUseCase
+	audit    *AuditUseCase
 }
 
-func NewTeamUseCase(tr repo.TeamRepo, ur repo.UserRepo) *TeamUseCase {
+func NewTeamUseCase(tx repo.Transactor, tr repo.TeamRepo, ur repo.UserRepo, pr *PullRequestUseCase, audit *AuditUseCase) *TeamUseCase {
 	return &TeamUseCase{
+		tx:       tx,
 		teamRepo: tr,
 		userRepo: ur,
+		prUC:     pr,
+		audit:    audit,
 	}
 }
 
 func (uc *TeamUseCase) CreateTeam(ctx context.Context, teamName string, members []entity.User) (entity.Team, error) {
-	exists, err := uc.teamRepo.Exists(ctx, teamName)
+	ctx, span := tracer.Start(ctx, "TeamUseCase.CreateTeam")
+	defer span.End()
+
+	return uc.createTeam(ctx, teamName, "", members)
+}
+
+// createTeam creates a team owned by managedBy, see entity.Team.ManagedBy.
+func (uc *TeamUseCase) createTeam(ctx context.Context, teamName, managedBy string, members []entity.User) (entity.Team, error) {
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		exists, err := uc.teamRepo.Exists(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.teamRepo.Exists: %w", err)
+		}
+		if exists {
+			return entity.Team{}, entity.ErrTeamAlreadyExists
+		}
+
+		team := entity.Team{
+			TeamName:  teamName,
+			Members:   members,
+			Settings:  entity.DefaultTeamSettings(),
+			CreatedAt: time.Now(),
+			ManagedBy: managedBy,
+		}
+
+		if err := uc.teamRepo.Create(ctx, team); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.teamRepo.Create: %w", err)
+		}
+
+		for _, member := range members {
+			member.TeamName = teamName
+			member.CreatedAt = time.Now()
+			if err := uc.userRepo.Create(ctx, member); err != nil {
+				return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.userRepo.Create: %w", err)
+			}
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamCreate, entity.AuditEntityTeam, teamName, nil, team); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.audit.Record: %w", err)
+		}
+
+		return team, nil
+	})
+}
+
+func (uc *TeamUseCase) GetTeam(ctx context.Context, teamName string) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.GetTeam")
+	defer span.End()
+
+	team, err := uc.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
-		return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.teamRepo.Exists: %w", err)
+		return entity.Team{}, fmt.Errorf("TeamUseCase - GetTeam - uc.teamRepo.GetByName: %w", err)
+	}
+	return team, nil
+}
+
+func (uc *TeamUseCase) UpdateSettings(ctx context.Context, teamName string, patch entity.TeamSettingsPatch) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.UpdateSettings")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		team, err := uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - UpdateSettings - uc.teamRepo.GetByName: %w", err)
+		}
+		before := snapshot(team)
+
+		if patch.MaxReviewers != nil {
+			if *patch.MaxReviewers < 0 {
+				return entity.Team{}, entity.ErrInvalidSettings
+			}
+			team.Settings.MaxReviewers = *patch.MaxReviewers
+		}
+		if patch.AllowCrossTeam != nil {
+			team.Settings.AllowCrossTeam = *patch.AllowCrossTeam
+		}
+		if patch.FallbackTeams != nil {
+			if err := uc.validateFallbacks(ctx, teamName, *patch.FallbackTeams); err != nil {
+				return entity.Team{}, fmt.Errorf("TeamUseCase - UpdateSettings - %w", err)
+			}
+			team.Settings.FallbackTeams = *patch.FallbackTeams
+		}
+
+		if err := uc.teamRepo.UpdateSettings(ctx, teamName, team.Settings); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - UpdateSettings - uc.teamRepo.UpdateSettings: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamSettings, entity.AuditEntityTeam, teamName, before, team); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - UpdateSettings - uc.audit.Record: %w", err)
+		}
+
+		return team, nil
+	})
+}
+
+func (uc *TeamUseCase) validateFallbacks(ctx context.Context, teamName string, fallbacks []string) error {
+	seen := make(map[string]bool, len(fallbacks))
+	for _, name := range fallbacks {
+		if name == teamName || seen[name] {
+			return entity.ErrInvalidSettings
+		}
+		seen[name] = true
+
+		exists, err := uc.teamRepo.Exists(ctx, name)
+		if err != nil {
+			return fmt.Errorf("uc.teamRepo.Exists: %w", err)
+		}
+		if !exists {
+			return entity.ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+// SetParent nests the team under parentTeam, or makes it a root team when
+// parentTeam is empty. A team cannot be nested under itself or its own sub-team.
+func (uc *TeamUseCase) SetParent(ctx context.Context, teamName, parentTeam string) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.SetParent")
+	defer span.End()
+
+	team, err := inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		return uc.setParent(ctx, teamName, parentTeam)
+	})
+	if err != nil {
+		return entity.Team{}, fmt.Errorf("TeamUseCase - SetParent - %w", err)
+	}
+
+	return team, nil
+}
+
+func (uc *TeamUseCase) setParent(ctx context.Context, teamName, parentTeam string) (entity.Team, error) {
+	// Two teams nested under each other concurrently would each pass the check
+	if err := uc.teamRepo.LockHierarchy(ctx); err != nil {
+		return entity.Team{}, fmt.Errorf("uc.teamRepo.LockHierarchy: %w", err)
+	}
+
+	before, err := uc.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return entity.Team{}, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
+	}
+
+	if parentTeam != "" {
+		if parentTeam == teamName {
+			return entity.Team{}, entity.ErrTeamCycle
+		}
+
+		exists, err := uc.teamRepo.Exists(ctx, parentTeam)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("uc.teamRepo.Exists: %w", err)
+		}
+		if !exists {
+			return entity.Team{}, entity.ErrNotFound
+		}
+
+		ancestors, err := uc.teamRepo.GetAncestors(ctx, parentTeam)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("uc.teamRepo.GetAncestors: %w", err)
+		}
+		for _, name := range ancestors {
+			if name == teamName {
+				return entity.Team{}, entity.ErrTeamCycle
+			}
+		}
 	}
-	if exists {
-		return entity.Team{}, entity.ErrTeamAlreadyExists
+
+	if err := uc.teamRepo.SetParent(ctx, teamName, parentTeam); err != nil {
+		return entity.Team{}, fmt.Errorf("uc.teamRepo.SetParent: %w", err)
 	}
 
-	team := entity.Team{
-		TeamName:  teamName,
-		Members:   members,
-		CreatedAt: time.Now(),
+	team, err := uc.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return entity.Team{}, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
 	}
 
-	if err := uc.teamRepo.Create(ctx, team); err != nil {
-		return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.teamRepo.Create: %w", err)
+	if err := uc.audit.Record(ctx, entity.AuditTeamSetParent, entity.AuditEntityTeam, teamName, before, team); err != nil {
+		return entity.Team{}, fmt.Errorf("uc.audit.Record: %w", err)
 	}
 
-	for _, member := range members {
+	return team, nil
+}
+
+// GetTree returns the team hierarchy. When root is set only that team's subtree is returned.
+func (uc *TeamUseCase) GetTree(ctx context.Context, root string) ([]entity.TeamNode, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.GetTree")
+	defer span.End()
+
+	teams, err := uc.teamRepo.GetTree(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("TeamUseCase - GetTree - uc.teamRepo.GetTree: %w", err)
+	}
+	if root != "" && len(teams) == 0 {
+		return nil, entity.ErrNotFound
+	}
+
+	return entity.BuildTeamTree(teams), nil
+}
+
+// AddMembers adds users to an existing team. New users are created; existing
+// users only gain the membership and keep their name, activity flag, other
+// memberships and primary team. Archived users cannot be added.
+func (uc *TeamUseCase) AddMembers(ctx context.Context, teamName string, members []entity.User) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.AddMembers")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		before, err := uc.requireActiveTeam(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - AddMembers - %w", err)
+		}
+
+		for _, member := range members {
+			if err := uc.addMember(ctx, teamName, member); err != nil {
+				return entity.Team{}, fmt.Errorf("TeamUseCase - AddMembers - %w", err)
+			}
+		}
+
+		team, err := uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - AddMembers - uc.teamRepo.GetByName: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamAddMembers, entity.AuditEntityTeam, teamName, before, team); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - AddMembers - uc.audit.Record: %w", err)
+		}
+
+		return team, nil
+	})
+}
+
+// AddUsers adds existing users to the team. Unlike AddMembers it does not
+// create users: an unknown user is an error.
+func (uc *TeamUseCase) AddUsers(ctx context.Context, teamName string, userIDs []string) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.AddUsers")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		members := make([]entity.User, 0, len(userIDs))
+		for _, userID := range userIDs {
+			user, err := uc.userRepo.GetByID(ctx, userID)
+			if err != nil {
+				return entity.Team{}, fmt.Errorf("TeamUseCase - AddUsers - uc.userRepo.GetByID: %w", err)
+			}
+			if user.IsArchived() {
+				return entity.Team{}, entity.ErrUserArchived
+			}
+			members = append(members, user)
+		}
+
+		team, err := uc.AddMembers(ctx, teamName, members)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - AddUsers - %w", err)
+		}
+
+		return team, nil
+	})
+}
+
+// addMember creates the user as a member of the team, or adds a membership to
+// an existing user without touching the account.
+func (uc *TeamUseCase) addMember(ctx context.Context, teamName string, member entity.User) error {
+	user, err := uc.userRepo.GetByID(ctx, member.UserID)
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
 		member.TeamName = teamName
 		member.CreatedAt = time.Now()
 		if err := uc.userRepo.Create(ctx, member); err != nil {
-			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeam - uc.userRepo.Create: %w", err)
+			return fmt.Errorf("uc.userRepo.Create: %w", err)
 		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("uc.userRepo.GetByID: %w", err)
+	case user.IsArchived():
+		return entity.ErrUserArchived
 	}
 
-	return team, nil
+	memberships, err := uc.userRepo.GetMemberships(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("uc.userRepo.GetMemberships: %w", err)
+	}
+	if _, ok := findMembership(memberships, teamName); ok {
+		return nil
+	}
+
+	if err := uc.userRepo.SaveMembership(ctx, user.UserID, entity.Membership{
+		TeamName:     teamName,
+		IsActive:     true,
+		ReviewWeight: entity.DefaultReviewWeight,
+		Role:         entity.RoleMember,
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		return fmt.Errorf("uc.userRepo.SaveMembership: %w", err)
+	}
+
+	if user.TeamName == "" {
+		user.TeamName = teamName
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("uc.userRepo.Update: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func (uc *TeamUseCase) GetTeam(ctx context.Context, teamName string) (entity.Team, error) {
+// CreateTeamWithUsers creates a team of existing users in one transaction.
+func (uc *TeamUseCase) CreateTeamWithUsers(ctx context.Context, teamName string, userIDs []string) (entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.CreateTeamWithUsers")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Team, error) {
+		team, err := uc.CreateTeam(ctx, teamName, nil)
+		if err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeamWithUsers - %w", err)
+		}
+		if len(userIDs) == 0 {
+			return team, nil
+		}
+
+		if team, err = uc.AddUsers(ctx, teamName, userIDs); err != nil {
+			return entity.Team{}, fmt.Errorf("TeamUseCase - CreateTeamWithUsers - %w", err)
+		}
+
+		return team, nil
+	})
+}
+
+// SetMembers makes the existing users userIDs the members of the team. Users
+// that are no longer listed leave the team and their OPEN reviews drawn from
+// it are reassigned.
+func (uc *TeamUseCase) SetMembers(ctx context.Context, teamName string, userIDs []string) (entity.Team, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.SetMembers")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.Team, []entity.PullRequest, error) {
+		team, err := uc.requireActiveTeam(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - SetMembers - %w", err)
+		}
+
+		wanted := make(map[string]bool, len(userIDs))
+		var added []string
+		for _, userID := range userIDs {
+			if !wanted[userID] && !team.HasMember(userID) {
+				added = append(added, userID)
+			}
+			wanted[userID] = true
+		}
+		if len(added) > 0 {
+			if _, err := uc.AddUsers(ctx, teamName, added); err != nil {
+				return entity.Team{}, nil, fmt.Errorf("TeamUseCase - SetMembers - %w", err)
+			}
+		}
+
+		reassigned := []entity.PullRequest{}
+		for _, member := range team.Members {
+			if wanted[member.UserID] {
+				continue
+			}
+			prs, err := uc.RemoveMember(ctx, teamName, member.UserID, true)
+			if err != nil {
+				return entity.Team{}, nil, fmt.Errorf("TeamUseCase - SetMembers - %w", err)
+			}
+			reassigned = append(reassigned, prs...)
+		}
+
+		team, err = uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - SetMembers - uc.teamRepo.GetByName: %w", err)
+		}
+
+		return team, reassigned, nil
+	})
+}
+
+// ChangeMembers applies the changes to the team's member list in order, in
+// one transaction: either every change is made or none. Members that leave the
+// team have their OPEN reviews drawn from it reassigned. Removing a user that
+// is not a member is not an error, so that retried requests succeed.
+func (uc *TeamUseCase) ChangeMembers(ctx context.Context, teamName string, changes []entity.MemberChange) (entity.Team, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.ChangeMembers")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.Team, []entity.PullRequest, error) {
+		if _, err := uc.requireActiveTeam(ctx, teamName); err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - %w", err)
+		}
+
+		reassigned := []entity.PullRequest{}
+		for _, change := range changes {
+			switch change.Op {
+			case entity.MemberAdd:
+				if len(change.UserIDs) == 0 {
+					continue
+				}
+				if _, err := uc.AddUsers(ctx, teamName, change.UserIDs); err != nil {
+					return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - %w", err)
+				}
+			case entity.MemberReplace:
+				_, prs, err := uc.SetMembers(ctx, teamName, change.UserIDs)
+				if err != nil {
+					return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - %w", err)
+				}
+				reassigned = append(reassigned, prs...)
+			case entity.MemberRemove:
+				for _, userID := range change.UserIDs {
+					prs, err := uc.RemoveMember(ctx, teamName, userID, true)
+					if errors.Is(err, entity.ErrNotTeamMember) {
+						continue
+					}
+					if err != nil {
+						return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - %w", err)
+					}
+					reassigned = append(reassigned, prs...)
+				}
+			default:
+				return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - unknown op %q", change.Op)
+			}
+		}
+
+		team, err := uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ChangeMembers - uc.teamRepo.GetByName: %w", err)
+		}
+
+		return team, reassigned, nil
+	})
+}
+
+// UpdateMember changes the activity flag, review weight or role of a team membership.
+func (uc *TeamUseCase) UpdateMember(ctx context.Context, teamName, userID string, patch entity.MembershipPatch) (entity.Membership, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.UpdateMember")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.Membership, error) {
+		memberships, err := uc.userRepo.GetMemberships(ctx, userID)
+		if err != nil {
+			return entity.Membership{}, fmt.Errorf("TeamUseCase - UpdateMember - uc.userRepo.GetMemberships: %w", err)
+		}
+
+		membership, ok := findMembership(memberships, teamName)
+		if !ok {
+			return entity.Membership{}, entity.ErrNotTeamMember
+		}
+		before := membership
+
+		if patch.IsActive != nil {
+			membership.IsActive = *patch.IsActive
+		}
+		if patch.ReviewWeight != nil {
+			if *patch.ReviewWeight < 0 {
+				return entity.Membership{}, entity.ErrInvalidSettings
+			}
+			membership.ReviewWeight = *patch.ReviewWeight
+		}
+		if patch.Role != nil {
+			if !patch.Role.IsValid() {
+				return entity.Membership{}, entity.ErrInvalidSettings
+			}
+			membership.Role = *patch.Role
+		}
+
+		if err := uc.userRepo.SaveMembership(ctx, userID, membership); err != nil {
+			return entity.Membership{}, fmt.Errorf("TeamUseCase - UpdateMember - uc.userRepo.SaveMembership: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamUpdateMember, entity.AuditEntityUser, userID, before, membership); err != nil {
+			return entity.Membership{}, fmt.Errorf("TeamUseCase - UpdateMember - uc.audit.Record: %w", err)
+		}
+
+		return membership, nil
+	})
+}
+
+// RemoveMember detaches a user from the team. The user is kept, so their
+// historic PRs stay readable, and remains a member of their other teams.
+func (uc *TeamUseCase) RemoveMember(ctx context.Context, teamName, userID string, reassignReviews bool) ([]entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.RemoveMember")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) ([]entity.PullRequest, error) {
+		memberships, err := uc.userRepo.GetMemberships(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("TeamUseCase - RemoveMember - uc.userRepo.GetMemberships: %w", err)
+		}
+
+		membership, ok := findMembership(memberships, teamName)
+		if !ok {
+			return nil, entity.ErrNotTeamMember
+		}
+
+		if err := uc.userRepo.RemoveMembership(ctx, userID, teamName); err != nil {
+			return nil, fmt.Errorf("TeamUseCase - RemoveMember - uc.userRepo.RemoveMembership: %w", err)
+		}
+
+		if err := uc.refreshPrimaryTeam(ctx, userID, teamName); err != nil {
+			return nil, fmt.Errorf("TeamUseCase - RemoveMember - %w", err)
+		}
+
+		reassigned, err := uc.reassignMemberReviews(ctx, userID, teamName, reassignReviews)
+		if err != nil {
+			return nil, fmt.Errorf("TeamUseCase - RemoveMember - %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamRemoveMember, entity.AuditEntityUser, userID, membership, nil); err != nil {
+			return nil, fmt.Errorf("TeamUseCase - RemoveMember - uc.audit.Record: %w", err)
+		}
+
+		return reassigned, nil
+	})
+}
+
+// MoveMember moves a user from one team to another, carrying over the membership
+// activity and review weight. The role is not carried over: the user joins
+// toTeam as a member. An empty fromTeam means the user's primary team. A user
+// that is already a member of toTeam is not moved, so that membership keeps its
+// settings.
+func (uc *TeamUseCase) MoveMember(ctx context.Context, userID, fromTeam, toTeam string, reassignReviews bool) (entity.User, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.MoveMember")
+	defer span.End()
+
+	type moved struct {
+		user       entity.User
+		reassigned []entity.PullRequest
+	}
+	result, err := inTx(ctx, uc.tx, func(ctx context.Context) (moved, error) {
+		user, reassigned, err := uc.moveMember(ctx, userID, fromTeam, toTeam, reassignReviews)
+		return moved{user, reassigned}, err
+	})
+	if err != nil {
+		return entity.User{}, nil, fmt.Errorf("TeamUseCase - MoveMember - %w", err)
+	}
+
+	return result.user, result.reassigned, nil
+}
+
+func (uc *TeamUseCase) moveMember(ctx context.Context, userID, fromTeam, toTeam string, reassignReviews bool) (entity.User, []entity.PullRequest, error) {
+	if _, err := uc.requireActiveTeam(ctx, toTeam); err != nil {
+		return entity.User{}, nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.User{}, nil, fmt.Errorf("uc.userRepo.GetByID: %w", err)
+	}
+
+	if fromTeam == "" {
+		fromTeam = user.TeamName
+	}
+	if fromTeam == toTeam {
+		return user, []entity.PullRequest{}, nil
+	}
+
+	memberships, err := uc.userRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return entity.User{}, nil, fmt.Errorf("uc.userRepo.GetMemberships: %w", err)
+	}
+
+	membership, ok := findMembership(memberships, fromTeam)
+	if !ok {
+		return entity.User{}, nil, entity.ErrNotTeamMember
+	}
+	if _, ok := findMembership(memberships, toTeam); ok {
+		return entity.User{}, nil, entity.ErrAlreadyTeamMember
+	}
+	before := membership
+
+	membership.TeamName = toTeam
+	membership.Role = entity.RoleMember
+	membership.CreatedAt = time.Now()
+	if err := uc.userRepo.SaveMembership(ctx, userID, membership); err != nil {
+		return entity.User{}, nil, fmt.Errorf("uc.userRepo.SaveMembership: %w", err)
+	}
+
+	if err := uc.userRepo.RemoveMembership(ctx, userID, fromTeam); err != nil {
+		return entity.User{}, nil, fmt.Errorf("uc.userRepo.RemoveMembership: %w", err)
+	}
+
+	if user.TeamName == fromTeam || user.TeamName == "" {
+		user.TeamName = toTeam
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return entity.User{}, nil, fmt.Errorf("uc.userRepo.Update: %w", err)
+		}
+	}
+
+	reassigned, err := uc.reassignMemberReviews(ctx, userID, fromTeam, reassignReviews)
+	if err != nil {
+		return entity.User{}, nil, err
+	}
+
+	if err := uc.audit.Record(ctx, entity.AuditTeamMoveMember, entity.AuditEntityUser, userID, before, membership); err != nil {
+		return entity.User{}, nil, fmt.Errorf("uc.audit.Record: %w", err)
+	}
+
+	return user, reassigned, nil
+}
+
+// requireActiveTeam returns the team, checking that it exists and is not archived.
+func (uc *TeamUseCase) requireActiveTeam(ctx context.Context, teamName string) (entity.Team, error) {
 	team, err := uc.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
-		return entity.Team{}, fmt.Errorf("TeamUseCase - GetTeam - uc.teamRepo.GetByName: %w", err)
+		return entity.Team{}, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
 	}
+	if team.IsArchived() {
+		return entity.Team{}, entity.ErrTeamArchived
+	}
+
 	return team, nil
 }
 
-func (uc *TeamUseCase) DeactivateTeamAndReassign(ctx context.Context, teamName string, prRepo repo.PullRequestRepo, selector *ReviewerSelector) error {
-	openPRs, err := prRepo.GetOpenPRsByTeam(ctx, teamName)
+// refreshPrimaryTeam points the user's primary team at one of the remaining
+// memberships after they left removedTeam.
+func (uc *TeamUseCase) refreshPrimaryTeam(ctx context.Context, userID, removedTeam string) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("uc.userRepo.GetByID: %w", err)
+	}
+	if user.TeamName != removedTeam {
+		return nil
+	}
+
+	memberships, err := uc.userRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("uc.userRepo.GetMemberships: %w", err)
+	}
+
+	user.TeamName = ""
+	if len(memberships) > 0 {
+		user.TeamName = memberships[0].TeamName
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("uc.userRepo.Update: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *TeamUseCase) reassignMemberReviews(ctx context.Context, userID, fromTeam string, reassign bool) ([]entity.PullRequest, error) {
+	if !reassign {
+		return []entity.PullRequest{}, nil
+	}
+
+	prs, err := uc.prUC.ReassignUserReviews(ctx, userID, fromTeam, entity.AssignmentMeta{Reason: entity.ReasonMembership})
 	if err != nil {
-		return fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - GetOpenPRs: %w", err)
+		return nil, fmt.Errorf("uc.prUC.ReassignUserReviews: %w", err)
 	}
 
-	if err := uc.userRepo.DeactivateTeam(ctx, teamName); err != nil {
-		return fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - DeactivateTeam: %w", err)
+	return prs, nil
+}
+
+func findMembership(memberships []entity.Membership, teamName string) (entity.Membership, bool) {
+	for _, m := range memberships {
+		if m.TeamName == teamName {
+			return m, true
+		}
 	}
+	return entity.Membership{}, false
+}
+
+// DeactivateTeamAndReassign deactivates every membership of the team and moves
+// the OPEN reviews its members were drawn into from it to available reviewers.
+func (uc *TeamUseCase) DeactivateTeamAndReassign(ctx context.Context, teamName string) ([]entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.DeactivateTeamAndReassign")
+	defer span.End()
 
-	for _, pr := range openPRs {
-		fullPR, err := prRepo.GetByID(ctx, pr.PullRequestID)
+	return inTx(ctx, uc.tx, func(ctx context.Context) ([]entity.PullRequest, error) {
+		team, err := uc.teamRepo.GetByName(ctx, teamName)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - uc.teamRepo.GetByName: %w", err)
 		}
 
-		author, err := uc.userRepo.GetByID(ctx, fullPR.AuthorID)
+		reassigned, err := uc.deactivateTeam(ctx, team, entity.ReasonDeactivation)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - %w", err)
 		}
 
-		teamMembers, err := uc.userRepo.GetByTeam(ctx, author.TeamName)
+		after, err := uc.teamRepo.GetByName(ctx, teamName)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - uc.teamRepo.GetByName: %w", err)
 		}
 
-		newReviewers := selector.SelectReviewers(teamMembers, fullPR.AuthorID)
-		fullPR.AssignedReviewers = newReviewers
+		if err := uc.audit.Record(ctx, entity.AuditTeamDeactivate, entity.AuditEntityTeam, teamName, team, after); err != nil {
+			return nil, fmt.Errorf("TeamUseCase - DeactivateTeamAndReassign - uc.audit.Record: %w", err)
+		}
 
-		prRepo.Update(ctx, fullPR)
+		return reassigned, nil
+	})
+}
+
+// ArchiveTeam archives the team: it no longer takes part in reviewer selection,
+// cannot get new members and its members' OPEN reviews drawn from it are reassigned.
+// The team, its memberships and historic PRs are kept. Archiving is idempotent.
+func (uc *TeamUseCase) ArchiveTeam(ctx context.Context, teamName string) (entity.Team, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "TeamUseCase.ArchiveTeam")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.Team, []entity.PullRequest, error) {
+		team, err := uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ArchiveTeam - uc.teamRepo.GetByName: %w", err)
+		}
+		if team.IsArchived() {
+			return team, []entity.PullRequest{}, nil
+		}
+
+		before := snapshot(team)
+
+		if err := uc.teamRepo.Archive(ctx, teamName); err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ArchiveTeam - uc.teamRepo.Archive: %w", err)
+		}
+
+		reassigned, err := uc.deactivateTeam(ctx, team, entity.ReasonArchival)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ArchiveTeam - %w", err)
+		}
+
+		team, err = uc.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ArchiveTeam - uc.teamRepo.GetByName: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTeamArchive, entity.AuditEntityTeam, teamName, before, team); err != nil {
+			return entity.Team{}, nil, fmt.Errorf("TeamUseCase - ArchiveTeam - uc.audit.Record: %w", err)
+		}
+
+		return team, reassigned, nil
+	})
+}
+
+func (uc *TeamUseCase) deactivateTeam(ctx context.Context, team entity.Team, reason entity.AssignmentReason) ([]entity.PullRequest, error) {
+	if err := uc.userRepo.DeactivateTeam(ctx, team.TeamName); err != nil {
+		return nil, fmt.Errorf("uc.userRepo.DeactivateTeam: %w", err)
 	}
 
-	return nil
+	reassigned := []entity.PullRequest{}
+	for _, member := range team.Members {
+		prs, err := uc.prUC.ReassignUserReviews(ctx, member.UserID, team.TeamName, entity.AssignmentMeta{Reason: reason})
+		if err != nil {
+			return nil, fmt.Errorf("uc.prUC.ReassignUserReviews: %w", err)
+		}
+		reassigned = append(reassigned, prs...)
+	}
+
+	return reassigned, nil
 }
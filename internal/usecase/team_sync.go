@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// TeamSyncUseCase reconciles the teams in the database with a declarative TeamConfig.
+type TeamSyncUseCase struct {
+	tx       repo.Transactor
+	teamRepo repo.TeamRepo
+	userRepo repo.UserRepo
+	teamUC   *TeamUseCase
+	userUC   *UserUseCase
+}
+
+func NewTeamSyncUseCase(tx repo.Transactor, tr repo.TeamRepo, ur repo.UserRepo, team *TeamUseCase, user *UserUseCase) *TeamSyncUseCase {
+	return &TeamSyncUseCase{
+		tx:       tx,
+		teamRepo: tr,
+		userRepo: ur,
+		teamUC:   team,
+		userUC:   user,
+	}
+}
+
+// Sync plans the changes that make the database match cfg and, unless dryRun is
+// set, applies them in one transaction: either every change is made or none.
+// Open reviews of removed or deactivated members are reassigned. Teams that are
+// not in cfg are archived only when prune is set; a cfg without teams is then
+// rejected rather than taken as archiving every team.
+func (uc *TeamSyncUseCase) Sync(ctx context.Context, cfg entity.TeamConfig, dryRun, prune bool) (entity.TeamSyncResult, error) {
+	ctx, span := tracer.Start(ctx, "TeamSyncUseCase.Sync")
+	defer span.End()
+
+	desired, err := cfg.Validate()
+	if err != nil {
+		return entity.TeamSyncResult{}, err
+	}
+	if prune && len(desired) == 0 {
+		return entity.TeamSyncResult{}, fmt.Errorf("%w: no teams declared, prune would archive every team", entity.ErrInvalidTeamConfig)
+	}
+
+	result := entity.TeamSyncResult{DryRun: dryRun, ReassignedPRs: []entity.PullRequest{}}
+	plan := func(ctx context.Context) error {
+		current, err := uc.currentTeams(ctx, desired, prune)
+		if err != nil {
+			return err
+		}
+		result.Changes = entity.PlanTeamSync(current, desired, prune)
+		return nil
+	}
+
+	if dryRun {
+		if err := plan(ctx); err != nil {
+			return entity.TeamSyncResult{}, fmt.Errorf("TeamSyncUseCase - Sync - %w", err)
+		}
+		return result, nil
+	}
+
+	ctx, release := holdNotifications(ctx)
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := plan(ctx); err != nil {
+			return err
+		}
+
+		teams := make(map[string]entity.TeamState, len(desired))
+		for _, team := range desired {
+			teams[team.TeamName] = team
+		}
+
+		for _, change := range result.Changes {
+			reassigned, err := uc.apply(ctx, change, teams[change.TeamName])
+			if err != nil {
+				return fmt.Errorf("%s: %w", change, err)
+			}
+			result.ReassignedPRs = append(result.ReassignedPRs, reassigned...)
+		}
+		return nil
+	})
+	release(err == nil)
+	if err != nil {
+		return entity.TeamSyncResult{}, fmt.Errorf("TeamSyncUseCase - Sync - %w", err)
+	}
+
+	return result, nil
+}
+
+// currentTeams loads the declared teams that exist and, when prune is set, all
+// other teams as well.
+func (uc *TeamSyncUseCase) currentTeams(ctx context.Context, desired []entity.TeamState, prune bool) (map[string]entity.TeamState, error) {
+	names := make([]string, 0, len(desired))
+	for _, team := range desired {
+		names = append(names, team.TeamName)
+	}
+	if prune {
+		all, err := uc.teamRepo.GetTree(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("uc.teamRepo.GetTree: %w", err)
+		}
+		for _, team := range all {
+			names = append(names, team.TeamName)
+		}
+	}
+
+	current := make(map[string]entity.TeamState, len(names))
+	for _, name := range names {
+		if _, ok := current[name]; ok {
+			continue
+		}
+
+		team, err := uc.teamRepo.GetByName(ctx, name)
+		if errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("uc.teamRepo.GetByName: %w", err)
+		}
+
+		state := entity.TeamState{
+			TeamName:   team.TeamName,
+			ParentTeam: team.ParentTeam,
+			Settings:   team.Settings,
+			Archived:   team.IsArchived(),
+		}
+		for _, member := range team.Members {
+			memberships, err := uc.userRepo.GetMemberships(ctx, member.UserID)
+			if err != nil {
+				return nil, fmt.Errorf("uc.userRepo.GetMemberships: %w", err)
+			}
+			membership, _ := findMembership(memberships, name)
+			state.Members = append(state.Members, entity.MemberState{
+				UserID:       member.UserID,
+				Username:     member.Username,
+				IsActive:     membership.IsActive,
+				ReviewWeight: membership.ReviewWeight,
+				Role:         membership.Role,
+			})
+		}
+		current[name] = state
+	}
+
+	for _, team := range desired {
+		if current[team.TeamName].Archived {
+			return nil, fmt.Errorf("team %s: %w", team.TeamName, entity.ErrTeamArchived)
+		}
+	}
+
+	return current, nil
+}
+
+func (uc *TeamSyncUseCase) apply(ctx context.Context, change entity.TeamSyncChange, team entity.TeamState) ([]entity.PullRequest, error) {
+	switch change.Action {
+	case entity.SyncCreateTeam:
+		_, err := uc.teamUC.CreateTeam(ctx, change.TeamName, nil)
+		return nil, err
+
+	case entity.SyncUpdateSettings:
+		_, err := uc.teamUC.UpdateSettings(ctx, change.TeamName, entity.TeamSettingsPatch{
+			MaxReviewers:   &team.Settings.MaxReviewers,
+			AllowCrossTeam: &team.Settings.AllowCrossTeam,
+			FallbackTeams:  &team.Settings.FallbackTeams,
+		})
+		return nil, err
+
+	case entity.SyncSetParent:
+		_, err := uc.teamUC.SetParent(ctx, change.TeamName, team.ParentTeam)
+		return nil, err
+
+	case entity.SyncAddMember:
+		return nil, uc.addMember(ctx, change.TeamName, findMember(team, change.UserID))
+
+	case entity.SyncRenameUser:
+		member := findMember(team, change.UserID)
+		_, err := uc.userUC.RenameUser(ctx, member.UserID, member.Username)
+		return nil, err
+
+	case entity.SyncUpdateMember:
+		member := findMember(team, change.UserID)
+		_, err := uc.teamUC.UpdateMember(ctx, change.TeamName, member.UserID, entity.MembershipPatch{
+			IsActive:     &member.IsActive,
+			ReviewWeight: &member.ReviewWeight,
+			Role:         &member.Role,
+		})
+		if err != nil || member.IsActive {
+			return nil, err
+		}
+		return uc.teamUC.reassignMemberReviews(ctx, member.UserID, change.TeamName, true)
+
+	case entity.SyncRemoveMember:
+		return uc.teamUC.RemoveMember(ctx, change.TeamName, change.UserID, true)
+
+	case entity.SyncArchiveTeam:
+		_, reassigned, err := uc.teamUC.ArchiveTeam(ctx, change.TeamName)
+		return reassigned, err
+	}
+
+	return nil, fmt.Errorf("unknown action %q", change.Action)
+}
+
+// addMember adds the user to the team, creating the user when needed. An
+// existing user keeps their name and account-wide activity flag: renames are
+// planned as changes of their own.
+func (uc *TeamSyncUseCase) addMember(ctx context.Context, teamName string, member entity.MemberState) error {
+	user := entity.User{UserID: member.UserID, Username: member.Username, IsActive: true}
+	if _, err := uc.teamUC.AddMembers(ctx, teamName, []entity.User{user}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func findMember(team entity.TeamState, userID string) entity.MemberState {
+	for _, m := range team.Members {
+		if m.UserID == userID {
+			return m
+		}
+	}
+	return entity.MemberState{UserID: userID}
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+func newTeamSyncUseCase(s *store, n *notifier) *usecase.TeamSyncUseCase {
+	audit := usecase.NewAuditUseCase(s.auditLog())
+	prUC := newPRUseCase(s, n)
+	teamUC := usecase.NewTeamUseCase(s, s.teams(), s.users(), prUC, audit)
+	userUC := usecase.NewUserUseCase(s, s.users(), s.prs(), prUC, audit)
+	return usecase.NewTeamSyncUseCase(s, s.teams(), s.users(), teamUC, userUC)
+}
+
+func TestTeamSyncRejectsEmptyConfigWithPrune(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1")
+
+	_, err := newTeamSyncUseCase(s, &notifier{}).Sync(context.Background(), entity.TeamConfig{}, false, true)
+	if !errors.Is(err, entity.ErrInvalidTeamConfig) {
+		t.Fatalf("Sync error = %v, want ErrInvalidTeamConfig", err)
+	}
+	if team := s.Teams["backend"]; team.IsArchived() {
+		t.Error("team archived by an empty config")
+	}
+}
+
+func TestTeamSyncFailedStepRollsBack(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3", "u4")
+	s.PRs["pr-1"] = entity.PullRequest{
+		PullRequestID:     "pr-1",
+		AuthorID:          "u1",
+		Status:            entity.StatusOpen,
+		AssignedReviewers: []string{"u2"},
+		ReviewerTeams:     map[string]string{"u2": "backend"},
+	}
+	archived := s.Users["u1"]
+	archived.UserID, archived.Username = "u5", "u5"
+	archived.Archive()
+	s.Users["u5"] = archived
+	n := &notifier{}
+
+	inactive := false
+	cfg := entity.TeamConfig{Teams: []entity.TeamSpec{{
+		TeamName: "backend",
+		Members: []entity.MemberSpec{
+			{UserID: "u1", Username: "u1"},
+			// Deactivating u2 moves pr-1 to another reviewer and notifies
+			{UserID: "u2", Username: "u2", IsActive: &inactive},
+			{UserID: "u3", Username: "u3"},
+			{UserID: "u4", Username: "u4"},
+			// Adding an archived user fails after that
+			{UserID: "u5", Username: "u5"},
+		},
+	}}}
+
+	_, err := newTeamSyncUseCase(s, n).Sync(context.Background(), cfg, false, false)
+	if !errors.Is(err, entity.ErrUserArchived) {
+		t.Fatalf("Sync error = %v, want ErrUserArchived", err)
+	}
+
+	if m, _ := s.membership("u2", "backend"); !m.IsActive {
+		t.Error("membership of u2 deactivated by a failed sync")
+	}
+	if got := s.PRs["pr-1"].AssignedReviewers; len(got) != 1 || got[0] != "u2" {
+		t.Errorf("pr-1 reviewers = %v, want [u2]", got)
+	}
+	if len(s.Audit) != 0 {
+		t.Errorf("audit entries of a failed sync: %+v", s.Audit)
+	}
+	if len(n.sent) != 0 {
+		t.Errorf("notifications of a failed sync: %v", n.events())
+	}
+}
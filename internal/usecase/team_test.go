@@ -0,0 +1,384 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+func newTeamUseCase(s *store, n *notifier) *usecase.TeamUseCase {
+	return usecase.NewTeamUseCase(s, s.teams(), s.users(), newPRUseCase(s, n), usecase.NewAuditUseCase(s.auditLog()))
+}
+
+func memberIDs(team entity.Team) []string {
+	ids := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func TestSetMembers(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	s.addTeam("frontend", "u4")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	n := &notifier{}
+
+	team, reassigned, err := newTeamUseCase(s, n).SetMembers(context.Background(), "backend", []string{"u1", "u3", "u4"})
+	if err != nil {
+		t.Fatalf("SetMembers: %v", err)
+	}
+
+	if got, want := memberIDs(team), []string{"u1", "u3", "u4"}; !slices.Equal(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+	if _, ok := s.membership("u2", "backend"); ok {
+		t.Error("u2 is still a member")
+	}
+	if len(reassigned) != 1 || s.PRs["pr-1"].AssignedReviewers[0] == "u2" {
+		t.Errorf("pr-1 reviewers = %v, want u2 replaced", s.PRs["pr-1"].AssignedReviewers)
+	}
+	if got := n.events(); len(got) != 1 || got[0] != entity.NotifyReassigned {
+		t.Errorf("notifications = %v, want one %s", got, entity.NotifyReassigned)
+	}
+}
+
+func TestChangeMembers(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	s.addTeam("frontend", "u4")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	n := &notifier{}
+
+	team, _, err := newTeamUseCase(s, n).ChangeMembers(context.Background(), "backend", []entity.MemberChange{
+		{Op: entity.MemberAdd, UserIDs: []string{"u4"}},
+		{Op: entity.MemberRemove, UserIDs: []string{"u2", "u9"}},
+	})
+	if err != nil {
+		t.Fatalf("ChangeMembers: %v", err)
+	}
+
+	if got, want := memberIDs(team), []string{"u1", "u3", "u4"}; !slices.Equal(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+	if got := n.events(); len(got) != 1 || got[0] != entity.NotifyReassigned {
+		t.Errorf("notifications = %v, want one %s", got, entity.NotifyReassigned)
+	}
+}
+
+func TestChangeMembersIsAtomic(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	s.addTeam("frontend", "u4")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	n := &notifier{}
+
+	_, _, err := newTeamUseCase(s, n).ChangeMembers(context.Background(), "backend", []entity.MemberChange{
+		{Op: entity.MemberRemove, UserIDs: []string{"u2"}},
+		{Op: entity.MemberAdd, UserIDs: []string{"u4"}},
+		{Op: entity.MemberAdd, UserIDs: []string{"missing"}},
+	})
+	if !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("ChangeMembers error = %v, want ErrNotFound", err)
+	}
+
+	if _, ok := s.membership("u2", "backend"); !ok {
+		t.Error("u2 removed by a failed request")
+	}
+	if _, ok := s.membership("u4", "backend"); ok {
+		t.Error("u4 added by a failed request")
+	}
+	if got := s.PRs["pr-1"].AssignedReviewers; len(got) != 1 || got[0] != "u2" {
+		t.Errorf("pr-1 reviewers = %v, want [u2]", got)
+	}
+	if len(n.sent) != 0 {
+		t.Errorf("notifications of a failed request: %v", n.events())
+	}
+}
+
+func TestMoveMemberResetsRole(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2")
+	s.addTeam("frontend", "u3")
+	lead, _ := s.membership("u2", "backend")
+	lead.Role = entity.RoleLead
+	lead.ReviewWeight = 3
+	s.Memberships["u2"] = []entity.Membership{lead}
+
+	if _, _, err := newTeamUseCase(s, &notifier{}).MoveMember(context.Background(), "u2", "", "frontend", false); err != nil {
+		t.Fatalf("MoveMember: %v", err)
+	}
+
+	moved, ok := s.membership("u2", "frontend")
+	if !ok {
+		t.Fatal("u2 is not a member of frontend")
+	}
+	if moved.Role != entity.RoleMember || moved.ReviewWeight != 3 {
+		t.Errorf("membership = %+v, want role %s and review weight 3", moved, entity.RoleMember)
+	}
+	if _, ok := s.membership("u2", "backend"); ok {
+		t.Error("u2 is still a member of backend")
+	}
+	if got := s.Users["u2"].TeamName; got != "frontend" {
+		t.Errorf("primary team = %q, want frontend", got)
+	}
+}
+
+func TestAddMembersKeepsExistingUsers(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1")
+	s.addTeam("frontend", "u2")
+	before := s.Users["u1"]
+
+	team, err := newTeamUseCase(s, &notifier{}).AddMembers(context.Background(), "frontend", []entity.User{
+		{UserID: "u1", Username: "renamed", IsActive: false},
+		{UserID: "u3", Username: "Carol", IsActive: true},
+	})
+	if err != nil {
+		t.Fatalf("AddMembers: %v", err)
+	}
+
+	if got, want := memberIDs(team), []string{"u1", "u2", "u3"}; !slices.Equal(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+	if got := s.Users["u1"]; got.Username != before.Username || !got.IsActive || got.TeamName != "backend" {
+		t.Errorf("u1 = %+v, want the account unchanged", got)
+	}
+	if got := s.Users["u3"]; got.Username != "Carol" || got.TeamName != "frontend" {
+		t.Errorf("u3 = %+v, want created in frontend", got)
+	}
+}
+
+func TestAddMembersRejectsArchivedUser(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1")
+	s.addTeam("frontend", "u2")
+	archived := s.Users["u1"]
+	archived.Archive()
+	s.Users["u1"] = archived
+
+	_, err := newTeamUseCase(s, &notifier{}).AddMembers(context.Background(), "frontend", []entity.User{{UserID: "u1", IsActive: true}})
+	if !errors.Is(err, entity.ErrUserArchived) {
+		t.Fatalf("AddMembers error = %v, want %v", err, entity.ErrUserArchived)
+	}
+	if _, ok := s.membership("u1", "frontend"); ok {
+		t.Error("archived u1 became a member of frontend")
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		reassign       bool
+		wantReassigned int
+	}{
+		{name: "keeps reviews", reassign: false, wantReassigned: 0},
+		{name: "reassigns reviews", reassign: true, wantReassigned: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			s.addTeam("backend", "u1", "u2", "u3")
+			s.addTeam("platform", "u2")
+			openReview(s, "pr-1", "u1", "u2", "backend")
+			n := &notifier{}
+
+			reassigned, err := newTeamUseCase(s, n).RemoveMember(context.Background(), "backend", "u2", tt.reassign)
+			if err != nil {
+				t.Fatalf("RemoveMember: %v", err)
+			}
+
+			if _, ok := s.membership("u2", "backend"); ok {
+				t.Error("u2 is still a member of backend")
+			}
+			if got := s.Users["u2"].TeamName; got != "platform" {
+				t.Errorf("primary team = %q, want the remaining platform", got)
+			}
+			if len(reassigned) != tt.wantReassigned || len(n.sent) != tt.wantReassigned {
+				t.Errorf("reassigned %d PRs with %d notifications, want %d", len(reassigned), len(n.sent), tt.wantReassigned)
+			}
+			pr := s.PRs["pr-1"]
+			if got := pr.HasReviewer("u2"); got == tt.reassign {
+				t.Errorf("u2 reviews pr-1: %v, want %v", got, !tt.reassign)
+			}
+		})
+	}
+}
+
+func TestRemoveMemberNotMember(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1")
+	s.addTeam("platform", "u2")
+
+	_, err := newTeamUseCase(s, &notifier{}).RemoveMember(context.Background(), "backend", "u2", true)
+	if !errors.Is(err, entity.ErrNotTeamMember) {
+		t.Fatalf("RemoveMember error = %v, want %v", err, entity.ErrNotTeamMember)
+	}
+}
+
+func TestMoveMemberRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		fromTeam string
+		toTeam   string
+		wantErr  error
+	}{
+		{name: "already a member of the target", fromTeam: "backend", toTeam: "platform", wantErr: entity.ErrAlreadyTeamMember},
+		{name: "not a member of the source", fromTeam: "frontend", toTeam: "design", wantErr: entity.ErrNotTeamMember},
+		{name: "archived target", toTeam: "legacy", wantErr: entity.ErrTeamArchived},
+		{name: "unknown target", toTeam: "nowhere", wantErr: entity.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			s.addTeam("backend", "u1", "u2")
+			s.addTeam("platform", "u2")
+			s.addTeam("frontend", "u3")
+			s.addTeam("design")
+			s.addTeam("legacy")
+			legacy := s.Teams["legacy"]
+			archivedAt := time.Now()
+			legacy.ArchivedAt = &archivedAt
+			s.Teams["legacy"] = legacy
+
+			_, _, err := newTeamUseCase(s, &notifier{}).MoveMember(context.Background(), "u2", tt.fromTeam, tt.toTeam, true)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveMember error = %v, want %v", err, tt.wantErr)
+			}
+			if _, ok := s.membership("u2", "backend"); !ok {
+				t.Error("u2 left backend")
+			}
+		})
+	}
+}
+
+func TestMembershipFlagsArePerTeam(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	s.addTeam("platform", "p1", "u2")
+	ctx := context.Background()
+
+	inactive := false
+	if _, err := newTeamUseCase(s, &notifier{}).UpdateMember(ctx, "backend", "u2", entity.MembershipPatch{IsActive: &inactive}); err != nil {
+		t.Fatalf("UpdateMember: %v", err)
+	}
+
+	prUC := newPRUseCase(s, &notifier{})
+	backendPR, err := prUC.CreatePR(ctx, "pr-1", "Backend fix", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if got := backendPR.AssignedReviewers; !slices.Equal(got, []string{"u3"}) {
+		t.Errorf("backend reviewers = %v, want [u3]: u2 is inactive in backend", got)
+	}
+
+	platformPR, err := prUC.CreatePR(ctx, "pr-2", "Platform fix", "p1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if got := platformPR.AssignedReviewers; !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("platform reviewers = %v, want [u2]: u2 is active in platform", got)
+	}
+}
+
+func TestUpdateMemberRejected(t *testing.T) {
+	negative := -1
+	invalid := entity.TeamRole("owner")
+
+	tests := []struct {
+		name    string
+		userID  string
+		patch   entity.MembershipPatch
+		wantErr error
+	}{
+		{name: "negative weight", userID: "u1", patch: entity.MembershipPatch{ReviewWeight: &negative}, wantErr: entity.ErrInvalidSettings},
+		{name: "unknown role", userID: "u1", patch: entity.MembershipPatch{Role: &invalid}, wantErr: entity.ErrInvalidSettings},
+		{name: "not a member", userID: "p1", patch: entity.MembershipPatch{}, wantErr: entity.ErrNotTeamMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			s.addTeam("backend", "u1")
+			s.addTeam("platform", "p1")
+			before, _ := s.membership("u1", "backend")
+
+			_, err := newTeamUseCase(s, &notifier{}).UpdateMember(context.Background(), "backend", tt.userID, tt.patch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateMember error = %v, want %v", err, tt.wantErr)
+			}
+			if after, _ := s.membership("u1", "backend"); after != before {
+				t.Errorf("membership = %+v, want unchanged %+v", after, before)
+			}
+		})
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	tests := []struct {
+		name       string
+		teamName   string
+		parentTeam string
+		wantErr    error
+		wantParent string
+	}{
+		{name: "nests a root team", teamName: "design", parentTeam: "platform", wantParent: "platform"},
+		{name: "moves to the root", teamName: "backend", wantParent: ""},
+		{name: "under itself", teamName: "platform", parentTeam: "platform", wantErr: entity.ErrTeamCycle, wantParent: "eng"},
+		{name: "under its child", teamName: "platform", parentTeam: "backend", wantErr: entity.ErrTeamCycle, wantParent: "eng"},
+		{name: "under its grandchild", teamName: "eng", parentTeam: "backend", wantErr: entity.ErrTeamCycle, wantParent: ""},
+		{name: "under an unknown team", teamName: "design", parentTeam: "nowhere", wantErr: entity.ErrNotFound, wantParent: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			// eng > platform > backend; design is a root team
+			for _, name := range []string{"eng", "platform", "backend", "design"} {
+				s.addTeam(name)
+			}
+			platform, backend := s.Teams["platform"], s.Teams["backend"]
+			platform.ParentTeam, backend.ParentTeam = "eng", "platform"
+			s.Teams["platform"], s.Teams["backend"] = platform, backend
+
+			_, err := newTeamUseCase(s, &notifier{}).SetParent(context.Background(), tt.teamName, tt.parentTeam)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetParent error = %v, want %v", err, tt.wantErr)
+			}
+			if got := s.Teams[tt.teamName].ParentTeam; got != tt.wantParent {
+				t.Errorf("parent of %s = %q, want %q", tt.teamName, got, tt.wantParent)
+			}
+		})
+	}
+}
+
+func TestCreatePREscalatesUpTheHierarchy(t *testing.T) {
+	s := newStore()
+	// platform > backend, frontend; backend has no reviewer but the author
+	s.addTeam("platform", "p1")
+	s.addTeam("backend", "u1")
+	s.addTeam("frontend", "f1")
+	for _, name := range []string{"backend", "frontend"} {
+		team := s.Teams[name]
+		team.ParentTeam = "platform"
+		s.Teams[name] = team
+	}
+
+	pr, err := newPRUseCase(s, &notifier{}).CreatePR(context.Background(), "pr-1", "Fix", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+
+	want := map[string]string{"f1": "frontend", "p1": "platform"}
+	if len(pr.ReviewerTeams) != len(want) || pr.ReviewerTeams["f1"] != want["f1"] || pr.ReviewerTeams["p1"] != want["p1"] {
+		t.Errorf("reviewer teams = %v, want %v", pr.ReviewerTeams, want)
+	}
+}
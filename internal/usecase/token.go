@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase/repo"
+	"strconv"
+	"time"
+)
+
+// lastUsedResolution limits how often a token's last_used_at is written.
+const lastUsedResolution = time.Minute
+
+type TokenUseCase struct {
+	tx        repo.Transactor
+	tokenRepo repo.TokenRepo
+	userRepo  repo.UserRepo
+	// jwt is nil when JWT authentication is disabled
+	jwt   *JWTAuthenticator
+	audit *AuditUseCase
+}
+
+func NewTokenUseCase(tx repo.Transactor, tr repo.TokenRepo, ur repo.UserRepo, jwt *JWTAuthenticator, audit *AuditUseCase) *TokenUseCase {
+	return &TokenUseCase{
+		tx:        tx,
+		tokenRepo: tr,
+		userRepo:  ur,
+		jwt:       jwt,
+		audit:     audit,
+	}
+}
+
+// CreateToken issues a new API token. The raw token is returned only here; the
+// service keeps just its hash. A zero ttl creates a token that never expires.
+func (uc *TokenUseCase) CreateToken(ctx context.Context, name string, scopes []entity.Scope, userID string, ttl time.Duration) (entity.APIToken, string, error) {
+	ctx, span := tracer.Start(ctx, "TokenUseCase.CreateToken")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.APIToken, string, error) {
+		if name == "" || len(scopes) == 0 || ttl < 0 {
+			return entity.APIToken{}, "", entity.ErrInvalidToken
+		}
+		for _, s := range scopes {
+			if !s.IsValid() {
+				return entity.APIToken{}, "", entity.ErrInvalidToken
+			}
+		}
+
+		if userID != "" {
+			if _, err := uc.userRepo.GetByID(ctx, userID); err != nil {
+				return entity.APIToken{}, "", fmt.Errorf("TokenUseCase - CreateToken - uc.userRepo.GetByID: %w", err)
+			}
+		}
+
+		token := entity.APIToken{
+			Name:      name,
+			Scopes:    scopes,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		}
+		if ttl > 0 {
+			expiresAt := token.CreatedAt.Add(ttl)
+			token.ExpiresAt = &expiresAt
+		}
+
+		raw := entity.NewRawToken()
+		token, err := uc.tokenRepo.Create(ctx, token, entity.HashToken(raw))
+		if err != nil {
+			return entity.APIToken{}, "", fmt.Errorf("TokenUseCase - CreateToken - uc.tokenRepo.Create: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTokenCreate, entity.AuditEntityToken, strconv.FormatInt(token.TokenID, 10), nil, token); err != nil {
+			return entity.APIToken{}, "", fmt.Errorf("TokenUseCase - CreateToken - uc.audit.Record: %w", err)
+		}
+
+		return token, raw, nil
+	})
+}
+
+func (uc *TokenUseCase) ListTokens(ctx context.Context) ([]entity.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "TokenUseCase.ListTokens")
+	defer span.End()
+
+	tokens, err := uc.tokenRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TokenUseCase - ListTokens - uc.tokenRepo.List: %w", err)
+	}
+	return tokens, nil
+}
+
+func (uc *TokenUseCase) RevokeToken(ctx context.Context, tokenID int64) error {
+	ctx, span := tracer.Start(ctx, "TokenUseCase.RevokeToken")
+	defer span.End()
+
+	return uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := uc.tokenRepo.Revoke(ctx, tokenID, time.Now()); err != nil {
+			return fmt.Errorf("TokenUseCase - RevokeToken - uc.tokenRepo.Revoke: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTokenRevoke, entity.AuditEntityToken, strconv.FormatInt(tokenID, 10), nil, nil); err != nil {
+			return fmt.Errorf("TokenUseCase - RevokeToken - uc.audit.Record: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Authenticate resolves a raw bearer token to request credentials. JWTs are
+// handed to the JWT authenticator when it is enabled. Unknown, revoked and
+// expired tokens yield ErrUnauthorized.
+func (uc *TokenUseCase) Authenticate(ctx context.Context, raw string) (entity.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "TokenUseCase.Authenticate")
+	defer span.End()
+
+	if looksLikeJWT(raw) {
+		if uc.jwt == nil {
+			return entity.APIToken{}, entity.ErrUnauthorized
+		}
+		return uc.jwt.Authenticate(ctx, raw)
+	}
+
+	token, err := uc.tokenRepo.GetByHash(ctx, entity.HashToken(raw))
+	if errors.Is(err, entity.ErrNotFound) {
+		return entity.APIToken{}, entity.ErrUnauthorized
+	}
+	if err != nil {
+		return entity.APIToken{}, fmt.Errorf("TokenUseCase - Authenticate - uc.tokenRepo.GetByHash: %w", err)
+	}
+
+	now := time.Now()
+	if !token.IsUsable(now) {
+		return entity.APIToken{}, entity.ErrUnauthorized
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := uc.tokenRepo.TouchLastUsed(ctx, token.TokenID, now); err != nil {
+			return entity.APIToken{}, fmt.Errorf("TokenUseCase - Authenticate - uc.tokenRepo.TouchLastUsed: %w", err)
+		}
+		token.LastUsedAt = &now
+	}
+
+	return token, nil
+}
+
+// EnsureBootstrapToken registers raw as an admin token unless it is already
+// known, so a fresh deployment can create its first tokens.
+func (uc *TokenUseCase) EnsureBootstrapToken(ctx context.Context, raw string) error {
+	ctx, span := tracer.Start(ctx, "TokenUseCase.EnsureBootstrapToken")
+	defer span.End()
+
+	return uc.tx.InTx(ctx, func(ctx context.Context) error {
+		_, err := uc.tokenRepo.GetByHash(ctx, entity.HashToken(raw))
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, entity.ErrNotFound) {
+			return fmt.Errorf("TokenUseCase - EnsureBootstrapToken - uc.tokenRepo.GetByHash: %w", err)
+		}
+
+		token := entity.APIToken{
+			Name:      "bootstrap",
+			Scopes:    []entity.Scope{entity.ScopeAdmin},
+			CreatedAt: time.Now(),
+		}
+		token, err = uc.tokenRepo.Create(ctx, token, entity.HashToken(raw))
+		if err != nil {
+			return fmt.Errorf("TokenUseCase - EnsureBootstrapToken - uc.tokenRepo.Create: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditTokenCreate, entity.AuditEntityToken, strconv.FormatInt(token.TokenID, 10), nil, token); err != nil {
+			return fmt.Errorf("TokenUseCase - EnsureBootstrapToken - uc.audit.Record: %w", err)
+		}
+
+		return nil
+	})
+}
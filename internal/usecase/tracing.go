@@ -0,0 +1,7 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts a span for every public usecase method. Spans are dropped
+// until a tracer provider is installed.
+var tracer = otel.Tracer("pr-reviewer-service/internal/usecase")
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"pr-reviewer-service/internal/usecase/repo"
+)
+
+// inTx runs fn in a transaction of tx and returns its result. Calls nested in
+// fn join the transaction, and notifications made in it are sent only once it
+// commits.
+func inTx[T any](ctx context.Context, tx repo.Transactor, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, release := holdNotifications(ctx)
+
+	var result T
+	err := tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	release(err == nil)
+
+	return result, err
+}
+
+// inTx2 is inTx for functions with two results.
+func inTx2[T, U any](ctx context.Context, tx repo.Transactor, fn func(ctx context.Context) (T, U, error)) (T, U, error) {
+	type results struct {
+		t T
+		u U
+	}
+	r, err := inTx(ctx, tx, func(ctx context.Context) (results, error) {
+		t, u, err := fn(ctx)
+		return results{t, u}, err
+	})
+	return r.t, r.u, err
+}
@@ -2,46 +2,330 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"pr-reviewer-service/internal/entity"
 	"pr-reviewer-service/internal/usecase/repo"
+	"time"
+)
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
 )
 
 type UserUseCase struct {
+	tx       repo.Transactor
 	userRepo repo.UserRepo
 	prRepo   repo.PullRequestRepo
+	prUC     *PullRequestUseCase
+	audit    *AuditUseCase
 }
 
-func NewUserUseCase(ur repo.UserRepo, prr repo.PullRequestRepo) *UserUseCase {
+func NewUserUseCase(tx repo.Transactor, ur repo.UserRepo, prr repo.PullRequestRepo, pr *PullRequestUseCase, audit *AuditUseCase) *UserUseCase {
 	return &UserUseCase{
+		tx:       tx,
 		userRepo: ur,
 		prRepo:   prr,
+		prUC:     pr,
+		audit:    audit,
 	}
 }
 
+// CreateUser creates a user outside of any team.
+func (uc *UserUseCase) CreateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.CreateUser")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.User, error) {
+		_, err := uc.userRepo.GetByID(ctx, user.UserID)
+		if err == nil {
+			return entity.User{}, entity.ErrUserAlreadyExists
+		}
+		if !errors.Is(err, entity.ErrNotFound) {
+			return entity.User{}, fmt.Errorf("UserUseCase - CreateUser - uc.userRepo.GetByID: %w", err)
+		}
+
+		user.TeamName = ""
+		user.CreatedAt = time.Now()
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - CreateUser - uc.userRepo.Create: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditUserCreate, entity.AuditEntityUser, user.UserID, nil, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - CreateUser - uc.audit.Record: %w", err)
+		}
+
+		return user, nil
+	})
+}
+
+// RenameUser changes the display name of the user.
+func (uc *UserUseCase) RenameUser(ctx context.Context, userID, username string) (entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.RenameUser")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.User, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - RenameUser - uc.userRepo.GetByID: %w", err)
+		}
+		if user.Username == username {
+			return user, nil
+		}
+		before := user
+
+		user.Username = username
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - RenameUser - uc.userRepo.Update: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditUserRename, entity.AuditEntityUser, userID, before, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - RenameUser - uc.audit.Record: %w", err)
+		}
+
+		return user, nil
+	})
+}
+
 func (uc *UserUseCase) SetIsActive(ctx context.Context, userID string, isActive bool) (entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.SetIsActive")
+	defer span.End()
+
+	return inTx(ctx, uc.tx, func(ctx context.Context) (entity.User, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - SetIsActive - uc.userRepo.GetByID: %w", err)
+		}
+		before := user
+
+		if isActive {
+			if user.IsArchived() {
+				return entity.User{}, entity.ErrUserArchived
+			}
+			user.Activate()
+		} else {
+			user.Deactivate()
+		}
+
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - SetIsActive - uc.userRepo.Update: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditUserSetIsActive, entity.AuditEntityUser, userID, before, user); err != nil {
+			return entity.User{}, fmt.Errorf("UserUseCase - SetIsActive - uc.audit.Record: %w", err)
+		}
+
+		return user, nil
+	})
+}
+
+// DeactivateAndReassign deactivates the user and moves their OPEN reviews to
+// available reviewers. The reviews are moved even when the user is already
+// inactive, so that a repeated request picks up reviews a failed one left.
+func (uc *UserUseCase) DeactivateAndReassign(ctx context.Context, userID string) (entity.User, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.DeactivateAndReassign")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.User, []entity.PullRequest, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - DeactivateAndReassign - uc.userRepo.GetByID: %w", err)
+		}
+
+		if user.IsActive {
+			if user, err = uc.SetIsActive(ctx, userID, false); err != nil {
+				return entity.User{}, nil, fmt.Errorf("UserUseCase - DeactivateAndReassign - %w", err)
+			}
+		}
+
+		reassigned, err := uc.prUC.ReassignUserReviews(ctx, userID, "", entity.AssignmentMeta{Reason: entity.ReasonDeactivation})
+		if err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - DeactivateAndReassign - uc.prUC.ReassignUserReviews: %w", err)
+		}
+
+		return user, reassigned, nil
+	})
+}
+
+// UpdateUser applies a new username and activity flag, each when set, in one
+// transaction. Deactivation reassigns the user's OPEN reviews, see
+// DeactivateAndReassign.
+func (uc *UserUseCase) UpdateUser(ctx context.Context, userID string, username *string, isActive *bool) (entity.User, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.UpdateUser")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.User, []entity.PullRequest, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - UpdateUser - uc.userRepo.GetByID: %w", err)
+		}
+
+		if username != nil {
+			if user, err = uc.RenameUser(ctx, userID, *username); err != nil {
+				return entity.User{}, nil, fmt.Errorf("UserUseCase - UpdateUser - %w", err)
+			}
+		}
+
+		reassigned := []entity.PullRequest{}
+		switch {
+		case isActive == nil:
+		case *isActive && !user.IsActive:
+			if user, err = uc.SetIsActive(ctx, userID, true); err != nil {
+				return entity.User{}, nil, fmt.Errorf("UserUseCase - UpdateUser - %w", err)
+			}
+		case !*isActive:
+			if user, reassigned, err = uc.DeactivateAndReassign(ctx, userID); err != nil {
+				return entity.User{}, nil, fmt.Errorf("UserUseCase - UpdateUser - %w", err)
+			}
+		}
+
+		return user, reassigned, nil
+	})
+}
+
+func (uc *UserUseCase) GetReviews(ctx context.Context, userID string) ([]entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.GetReviews")
+	defer span.End()
+
+	prs, err := uc.prRepo.GetByReviewer(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("UserUseCase - GetReviews - uc.prRepo.GetByReviewer: %w", err)
+	}
+	return prs, nil
+}
+
+// ArchiveUser deactivates the user for good and reassigns their OPEN reviews.
+// The user and their historic PRs are kept. Archiving is idempotent.
+func (uc *UserUseCase) ArchiveUser(ctx context.Context, userID string) (entity.User, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.ArchiveUser")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (entity.User, []entity.PullRequest, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - ArchiveUser - uc.userRepo.GetByID: %w", err)
+		}
+		if user.IsArchived() {
+			return user, []entity.PullRequest{}, nil
+		}
+
+		before := user
+		user.Archive()
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - ArchiveUser - uc.userRepo.Update: %w", err)
+		}
+
+		reassigned, err := uc.prUC.ReassignUserReviews(ctx, userID, "", entity.AssignmentMeta{Reason: entity.ReasonArchival})
+		if err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - ArchiveUser - uc.prUC.ReassignUserReviews: %w", err)
+		}
+
+		if err := uc.audit.Record(ctx, entity.AuditUserArchive, entity.AuditEntityUser, userID, before, user); err != nil {
+			return entity.User{}, nil, fmt.Errorf("UserUseCase - ArchiveUser - uc.audit.Record: %w", err)
+		}
+
+		return user, reassigned, nil
+	})
+}
+
+// EraseUser removes the user's identity for good. OPEN reviews are reassigned
+// first, then the user is anonymised under a random id that historic PRs keep
+// pointing at. The anonymous id is returned.
+func (uc *UserUseCase) EraseUser(ctx context.Context, userID string) (string, []entity.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.EraseUser")
+	defer span.End()
+
+	return inTx2(ctx, uc.tx, func(ctx context.Context) (string, []entity.PullRequest, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return "", nil, fmt.Errorf("UserUseCase - EraseUser - uc.userRepo.GetByID: %w", err)
+		}
+
+		if !user.IsArchived() {
+			user.Archive()
+			if err := uc.userRepo.Update(ctx, user); err != nil {
+				return "", nil, fmt.Errorf("UserUseCase - EraseUser - uc.userRepo.Update: %w", err)
+			}
+		}
+
+		reassigned, err := uc.prUC.ReassignUserReviews(ctx, userID, "", entity.AssignmentMeta{Reason: entity.ReasonErasure})
+		if err != nil {
+			return "", nil, fmt.Errorf("UserUseCase - EraseUser - uc.prUC.ReassignUserReviews: %w", err)
+		}
+
+		anonymousID := entity.NewErasedUserID()
+		if err := uc.userRepo.Erase(ctx, userID, anonymousID); err != nil {
+			return "", nil, fmt.Errorf("UserUseCase - EraseUser - uc.userRepo.Erase: %w", err)
+		}
+
+		// The entry names only the anonymous id: it must not identify the erased user
+		if err := uc.audit.Record(ctx, entity.AuditUserErase, entity.AuditEntityUser, anonymousID, nil, nil); err != nil {
+			return "", nil, fmt.Errorf("UserUseCase - EraseUser - uc.audit.Record: %w", err)
+		}
+
+		return anonymousID, reassigned, nil
+	})
+}
+
+// GetUser returns the user with their memberships and current review load.
+func (uc *UserUseCase) GetUser(ctx context.Context, userID string) (entity.UserProfile, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.GetUser")
+	defer span.End()
+
 	user, err := uc.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return entity.User{}, fmt.Errorf("UserUseCase - SetIsActive - uc.userRepo.GetByID: %w", err)
+		return entity.UserProfile{}, fmt.Errorf("UserUseCase - GetUser - uc.userRepo.GetByID: %w", err)
 	}
 
-	if isActive {
-		user.Activate()
-	} else {
-		user.Deactivate()
+	memberships, err := uc.userRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return entity.UserProfile{}, fmt.Errorf("UserUseCase - GetUser - uc.userRepo.GetMemberships: %w", err)
 	}
 
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		return entity.User{}, fmt.Errorf("UserUseCase - SetIsActive - uc.userRepo.Update: %w", err)
+	reviews, err := uc.prRepo.GetByReviewer(ctx, userID)
+	if err != nil {
+		return entity.UserProfile{}, fmt.Errorf("UserUseCase - GetUser - uc.prRepo.GetByReviewer: %w", err)
 	}
 
-	return user, nil
+	profile := entity.UserProfile{
+		UserSummary: entity.UserSummary{User: user},
+		Memberships: memberships,
+	}
+	for _, pr := range reviews {
+		if pr.IsOpen() {
+			profile.OpenReviews++
+		}
+	}
+
+	return profile, nil
 }
 
-func (uc *UserUseCase) GetReviews(ctx context.Context, userID string) ([]entity.PullRequest, error) {
-	prs, err := uc.prRepo.GetByReviewer(ctx, userID)
+// ListUsers returns a page of the user directory. The page size defaults to
+// defaultUserPageSize and is capped at maxUserPageSize.
+func (uc *UserUseCase) ListUsers(ctx context.Context, filter entity.UserFilter) (entity.UserPage, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.ListUsers")
+	defer span.End()
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserPageSize
+	}
+	if filter.Limit > maxUserPageSize {
+		filter.Limit = maxUserPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	users, total, err := uc.userRepo.List(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("UserUseCase - GetReviews - uc.prRepo.GetByReviewer: %w", err)
+		return entity.UserPage{}, fmt.Errorf("UserUseCase - ListUsers - uc.userRepo.List: %w", err)
 	}
-	return prs, nil
+
+	return entity.UserPage{
+		Users:  users,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
 }
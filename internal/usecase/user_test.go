@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-reviewer-service/internal/entity"
+	"pr-reviewer-service/internal/usecase"
+)
+
+func newUserUseCase(s *store, n *notifier) *usecase.UserUseCase {
+	return usecase.NewUserUseCase(s, s.users(), s.prs(), newPRUseCase(s, n), usecase.NewAuditUseCase(s.auditLog()))
+}
+
+// openReview stores an OPEN PR of authorID reviewed by reviewerID from teamName.
+func openReview(s *store, prID, authorID, reviewerID, teamName string) {
+	s.PRs[prID] = entity.PullRequest{
+		PullRequestID:     prID,
+		AuthorID:          authorID,
+		Status:            entity.StatusOpen,
+		AssignedReviewers: []string{reviewerID},
+		ReviewerTeams:     map[string]string{reviewerID: teamName},
+	}
+}
+
+func TestDeactivateAndReassignInactiveUser(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	// A previous deactivation left the review behind
+	inactive := s.Users["u2"]
+	inactive.Deactivate()
+	s.Users["u2"] = inactive
+	n := &notifier{}
+
+	user, reassigned, err := newUserUseCase(s, n).DeactivateAndReassign(context.Background(), "u2")
+	if err != nil {
+		t.Fatalf("DeactivateAndReassign: %v", err)
+	}
+
+	if user.IsActive {
+		t.Error("user is active")
+	}
+	if len(reassigned) != 1 {
+		t.Fatalf("reassigned = %d PRs, want 1", len(reassigned))
+	}
+	if got := s.PRs["pr-1"].AssignedReviewers; len(got) != 1 || got[0] != "u3" {
+		t.Errorf("pr-1 reviewers = %v, want [u3]", got)
+	}
+	if got := n.events(); len(got) != 1 || got[0] != entity.NotifyReassigned {
+		t.Errorf("notifications = %v, want one %s", got, entity.NotifyReassigned)
+	}
+}
+
+func TestUpdateUserIsAtomic(t *testing.T) {
+	s := newStore()
+	s.addTeam("backend", "u1", "u2", "u3")
+	openReview(s, "pr-1", "u1", "u2", "backend")
+	n := &notifier{}
+	uc := newUserUseCase(s, n)
+	ctx := context.Background()
+
+	name := "Bob"
+	inactive := false
+	user, _, err := uc.UpdateUser(ctx, "u2", &name, &inactive)
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if user.Username != name || user.IsActive {
+		t.Errorf("user = %+v, want renamed and inactive", user)
+	}
+	if got := s.PRs["pr-1"].AssignedReviewers; len(got) != 1 || got[0] != "u3" {
+		t.Errorf("pr-1 reviewers = %v, want [u3]", got)
+	}
+
+	// Renaming commits only together with the rest of the update
+	s.failAudit = errAuditDown
+	name = "Robert"
+	if _, _, err := uc.UpdateUser(ctx, "u3", &name, &inactive); !errors.Is(err, errAuditDown) {
+		t.Fatalf("UpdateUser error = %v, want %v", err, errAuditDown)
+	}
+	if got := s.Users["u3"]; got.Username != "u3" || !got.IsActive {
+		t.Errorf("u3 = %+v, want it unchanged", got)
+	}
+	if got := n.events(); len(got) != 1 {
+		t.Errorf("notifications = %v, want only the one of the first update", got)
+	}
+}
@@ -0,0 +1,10 @@
+ALTER TABLE pull_requests ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE pull_requests ADD COLUMN stale_at TIMESTAMP;
+ALTER TABLE pull_requests ADD COLUMN closed_at TIMESTAMP;
+
+UPDATE pull_requests SET updated_at = COALESCE(merged_at, created_at);
+
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED'));
+
+CREATE INDEX idx_pr_open_updated ON pull_requests(updated_at) WHERE status = 'OPEN';
@@ -0,0 +1,3 @@
+-- Rollback
+DROP INDEX IF EXISTS idx_pr_assignment_events_pr;
+DROP TABLE IF EXISTS pr_assignment_events;
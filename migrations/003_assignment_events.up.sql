@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS pr_assignment_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('assigned', 'unassigned', 'reassigned')),
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    previous_reviewer_id VARCHAR(255) REFERENCES users(user_id),
+    actor_id VARCHAR(255),
+    reason VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pr_assignment_events_pr ON pr_assignment_events(pull_request_id, event_id);
+
+-- Carry over current assignments so every PR has a starting point in its timeline
+INSERT INTO pr_assignment_events (pull_request_id, event_type, reviewer_id, reason, created_at)
+SELECT pull_request_id, 'assigned', reviewer_id, 'auto', assigned_at
+FROM pr_reviewers
+ORDER BY assigned_at;
@@ -0,0 +1,3 @@
+-- Rollback
+ALTER TABLE teams DROP COLUMN IF EXISTS allow_cross_team;
+ALTER TABLE teams DROP COLUMN IF EXISTS max_reviewers;
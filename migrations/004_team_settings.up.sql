@@ -0,0 +1,2 @@
+ALTER TABLE teams ADD COLUMN max_reviewers INT NOT NULL DEFAULT 2 CHECK (max_reviewers >= 0);
+ALTER TABLE teams ADD COLUMN allow_cross_team BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- Rollback
+ALTER TABLE pr_assignment_events DROP COLUMN IF EXISTS source_team;
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS source_team;
+
+DROP TABLE IF EXISTS team_fallbacks;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    priority INT NOT NULL,
+    PRIMARY KEY (team_name, fallback_team),
+    CHECK (team_name <> fallback_team)
+);
+
+ALTER TABLE pr_reviewers ADD COLUMN source_team VARCHAR(255);
+ALTER TABLE pr_assignment_events ADD COLUMN source_team VARCHAR(255);
+
+-- Until now reviewers were always drawn from their own team
+UPDATE pr_reviewers r SET source_team = u.team_name FROM users u WHERE u.user_id = r.reviewer_id;
@@ -0,0 +1,3 @@
+-- Rollback
+DROP INDEX IF EXISTS idx_team_memberships_team_active;
+DROP TABLE IF EXISTS team_memberships;
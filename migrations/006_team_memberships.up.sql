@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS team_memberships (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    review_weight INT NOT NULL DEFAULT 1 CHECK (review_weight >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, team_name)
+);
+
+CREATE INDEX idx_team_memberships_team_active ON team_memberships(team_name, is_active);
+
+-- users.team_name stays as the user's primary team
+INSERT INTO team_memberships (user_id, team_name, created_at)
+SELECT user_id, team_name, created_at
+FROM users
+WHERE team_name IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE teams ADD COLUMN parent_team VARCHAR(255) REFERENCES teams(team_name) ON DELETE SET NULL;
+ALTER TABLE teams ADD CONSTRAINT teams_parent_not_self CHECK (parent_team <> team_name);
+
+CREATE INDEX idx_teams_parent ON teams(parent_team);
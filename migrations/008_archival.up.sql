@@ -0,0 +1,29 @@
+ALTER TABLE teams ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP;
+
+-- Deleting a team must not take its users (and their PRs) with it
+ALTER TABLE users DROP CONSTRAINT users_team_name_fkey;
+ALTER TABLE users ADD CONSTRAINT users_team_name_fkey
+    FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE SET NULL;
+
+-- Erasure renames the user; history follows the new anonymous id
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_author_id_fkey;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(user_id) ON UPDATE CASCADE;
+
+ALTER TABLE pr_reviewers DROP CONSTRAINT pr_reviewers_reviewer_id_fkey;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON UPDATE CASCADE;
+
+ALTER TABLE pr_assignment_events DROP CONSTRAINT pr_assignment_events_reviewer_id_fkey;
+ALTER TABLE pr_assignment_events ADD CONSTRAINT pr_assignment_events_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON UPDATE CASCADE;
+
+ALTER TABLE pr_assignment_events DROP CONSTRAINT pr_assignment_events_previous_reviewer_id_fkey;
+ALTER TABLE pr_assignment_events ADD CONSTRAINT pr_assignment_events_previous_reviewer_id_fkey
+    FOREIGN KEY (previous_reviewer_id) REFERENCES users(user_id) ON UPDATE CASCADE;
+
+ALTER TABLE team_memberships DROP CONSTRAINT team_memberships_user_id_fkey;
+ALTER TABLE team_memberships ADD CONSTRAINT team_memberships_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE ON UPDATE CASCADE;
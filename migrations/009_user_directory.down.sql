@@ -0,0 +1,4 @@
+-- Rollback
+DROP INDEX IF EXISTS idx_team_memberships_user;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+DROP INDEX IF EXISTS idx_users_username_prefix;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Prefix search (LIKE 'abc%') and substring search (LIKE '%abc%') on usernames
+CREATE INDEX idx_users_username_prefix ON users (lower(username) text_pattern_ops);
+CREATE INDEX idx_users_username_trgm ON users USING gin (lower(username) gin_trgm_ops);
+
+CREATE INDEX idx_team_memberships_user ON team_memberships(user_id);
@@ -0,0 +1,2 @@
+-- Rollback
+ALTER TABLE team_memberships DROP COLUMN IF EXISTS role;
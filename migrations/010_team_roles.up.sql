@@ -0,0 +1,2 @@
+ALTER TABLE team_memberships ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('member', 'lead', 'maintainer'));
@@ -0,0 +1,2 @@
+-- Rollback
+DROP TABLE IF EXISTS api_tokens;
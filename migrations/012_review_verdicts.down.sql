@@ -0,0 +1,2 @@
+-- Rollback
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS approved_at;
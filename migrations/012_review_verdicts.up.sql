@@ -0,0 +1 @@
+ALTER TABLE pr_reviewers ADD COLUMN approved_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Rollback
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,2 @@
+-- Rollback
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,2 @@
+-- Rollback
+ALTER TABLE teams ALTER COLUMN max_reviewers SET DEFAULT 2;
@@ -0,0 +1,3 @@
+-- The old default equalled the automatic reviewer count, so no reviewer could
+-- be added manually to a new team. Existing teams keep their setting.
+ALTER TABLE teams ALTER COLUMN max_reviewers SET DEFAULT 3;
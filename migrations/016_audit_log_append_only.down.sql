@@ -0,0 +1,5 @@
+-- Rollback
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+ALTER TABLE audit_log DROP COLUMN IF EXISTS actor_token_id;
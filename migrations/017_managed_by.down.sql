@@ -0,0 +1,5 @@
+-- Rollback
+DROP INDEX IF EXISTS idx_users_managed_by;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS managed_by;
+ALTER TABLE users DROP COLUMN IF EXISTS managed_by;
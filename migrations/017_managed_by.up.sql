@@ -0,0 +1,6 @@
+-- Users and teams created by a directory sync are managed by it; the sync
+-- leaves the ones created in the service alone
+ALTER TABLE users ADD COLUMN IF NOT EXISTS managed_by VARCHAR(32);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS managed_by VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_users_managed_by ON users(managed_by) WHERE managed_by IS NOT NULL;
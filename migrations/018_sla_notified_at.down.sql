@@ -0,0 +1,4 @@
+-- Rollback
+DROP INDEX IF EXISTS idx_pr_reviewers_sla_pending;
+
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS sla_notified_at;
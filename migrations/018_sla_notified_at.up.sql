@@ -0,0 +1,6 @@
+-- A review's SLA breach is reported once: the check claims the breach by
+-- setting sla_notified_at in the statement that selects it
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS sla_notified_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_sla_pending ON pr_reviewers(assigned_at)
+    WHERE approved_at IS NULL AND sla_notified_at IS NULL;
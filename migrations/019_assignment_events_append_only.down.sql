@@ -0,0 +1,4 @@
+-- Rollback
+DROP TRIGGER IF EXISTS pr_assignment_events_no_truncate ON pr_assignment_events;
+DROP TRIGGER IF EXISTS pr_assignment_events_append_only ON pr_assignment_events;
+DROP FUNCTION IF EXISTS pr_assignment_events_append_only();
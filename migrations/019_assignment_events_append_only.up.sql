@@ -0,0 +1,21 @@
+-- The assignment timeline is append-only. The only exception is GDPR erasure,
+-- which rewrites the user's id in a transaction that sets
+-- pr_reviewer.audit_erasure, as for audit_log.
+CREATE OR REPLACE FUNCTION pr_assignment_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('pr_reviewer.audit_erasure', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'pr_assignment_events is append-only: % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_assignment_events_append_only ON pr_assignment_events;
+CREATE TRIGGER pr_assignment_events_append_only
+    BEFORE UPDATE OR DELETE ON pr_assignment_events
+    FOR EACH ROW EXECUTE FUNCTION pr_assignment_events_append_only();
+
+DROP TRIGGER IF EXISTS pr_assignment_events_no_truncate ON pr_assignment_events;
+CREATE TRIGGER pr_assignment_events_no_truncate
+    BEFORE TRUNCATE ON pr_assignment_events
+    FOR EACH STATEMENT EXECUTE FUNCTION pr_assignment_events_append_only();
@@ -0,0 +1,38 @@
+// Package migrations embeds the SQL migrations, named NNN_name.up.sql and
+// NNN_name.down.sql as golang-migrate expects them.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion is the version of the newest migration, the schema version
+// this binary expects.
+func LatestVersion() (uint, error) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, fmt.Errorf("migrations - LatestVersion - fs.Glob: %w", err)
+	}
+
+	var latest uint
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("migrations - LatestVersion - malformed name %q", name)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migrations - LatestVersion - malformed name %q", name)
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
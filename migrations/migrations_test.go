@@ -0,0 +1,31 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/migrations"
+)
+
+func TestEveryMigrationCanBeRolledBack(t *testing.T) {
+	ups, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("fs.Glob: %v", err)
+	}
+
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		if _, err := fs.Stat(migrations.FS, down); err != nil {
+			t.Errorf("%s has no %s", up, down)
+		}
+	}
+
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		t.Fatalf("LatestVersion: %v", err)
+	}
+	if int(latest) != len(ups) {
+		t.Errorf("latest version %d, but %d migrations: versions are not contiguous", latest, len(ups))
+	}
+}
@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/pkg/health"
+)
+
+func TestRun(t *testing.T) {
+	c := health.New(50 * time.Millisecond)
+	c.Add("ok", func(context.Context) error { return nil })
+	c.Add("broken", func(context.Context) error { return errors.New("connection refused") })
+	c.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := c.Run(context.Background())
+
+	if report.Status != health.StatusFail {
+		t.Errorf("status = %s, want fail", report.Status)
+	}
+	if got := report.Checks["ok"].Status; got != health.StatusOK {
+		t.Errorf("ok check = %s", got)
+	}
+	if got := report.Checks["broken"]; got.Status != health.StatusFail || got.Error != "connection refused" {
+		t.Errorf("broken check = %+v", got)
+	}
+	if got := report.Checks["slow"]; got.Status != health.StatusFail || got.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("slow check = %+v, want a timeout", got)
+	}
+}
+
+func TestShuttingDown(t *testing.T) {
+	c := health.New(time.Second)
+	ran := false
+	c.Add("db", func(context.Context) error { ran = true; return nil })
+
+	if report := c.Run(context.Background()); report.Status != health.StatusOK {
+		t.Fatalf("before shutdown: status = %s", report.Status)
+	}
+
+	ran = false
+	c.SetShuttingDown()
+	report := c.Run(context.Background())
+
+	if report.Status != health.StatusFail || report.Checks["shutdown"].Status != health.StatusFail {
+		t.Errorf("during shutdown: report = %+v", report)
+	}
+	if ran {
+		t.Error("checks ran during shutdown")
+	}
+}
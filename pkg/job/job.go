@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs fn once per interval until ctx is cancelled.
+// Errors are logged and do not stop the job.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	if interval <= 0 {
+		slog.Warn("job not started, invalid interval", "job", name, "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slog.Info("job started", "job", name, "interval", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("job stopped", "job", name)
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				slog.ErrorContext(ctx, "job failed", "job", name, "error", err)
+			}
+		}
+	}
+}
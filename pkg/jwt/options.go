@@ -0,0 +1,24 @@
+package jwt
+
+import "time"
+
+type Option func(*Verifier)
+
+func Issuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+func Audience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// Leeway tolerates clock skew when checking exp and nbf.
+func Leeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySource provides the key set tokens are verified against. Refresh asks the
+// source to reload keys, e.g. after a token names an unknown key id.
+type KeySource interface {
+	KeySet(ctx context.Context) (*KeySet, error)
+	Refresh(ctx context.Context) (*KeySet, error)
+}
+
+// StaticSource serves a fixed key set.
+type StaticSource struct {
+	set *KeySet
+}
+
+func NewStaticSource(set *KeySet) *StaticSource {
+	return &StaticSource{set: set}
+}
+
+func (s *StaticSource) KeySet(context.Context) (*KeySet, error) {
+	return s.set, nil
+}
+
+func (s *StaticSource) Refresh(context.Context) (*KeySet, error) {
+	return s.set, nil
+}
+
+// NewFileSource reads a JWKS document from a file once.
+func NewFileSource(path string) (*StaticSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - NewFileSource - os.ReadFile: %w", err)
+	}
+
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - NewFileSource - %w", err)
+	}
+
+	return NewStaticSource(set), nil
+}
+
+// URLSource fetches a JWKS document over HTTP and caches it for a refresh interval.
+// Forced refreshes are limited to one per minRefresh so unknown key ids cannot be
+// used to hammer the identity provider.
+type URLSource struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+
+	mu        sync.Mutex
+	set       *KeySet
+	fetchedAt time.Time
+}
+
+func NewURLSource(url string, ttl time.Duration, client *http.Client) *URLSource {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &URLSource{
+		url:        url,
+		client:     client,
+		ttl:        ttl,
+		minRefresh: time.Minute,
+	}
+}
+
+func (s *URLSource) KeySet(ctx context.Context) (*KeySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.set != nil && time.Since(s.fetchedAt) < s.ttl {
+		return s.set, nil
+	}
+	return s.fetch(ctx)
+}
+
+func (s *URLSource) Refresh(ctx context.Context) (*KeySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.set != nil && time.Since(s.fetchedAt) < s.minRefresh {
+		return s.set, nil
+	}
+	return s.fetch(ctx)
+}
+
+func (s *URLSource) fetch(ctx context.Context) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - URLSource - http.NewRequest: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - URLSource - client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwt - URLSource - unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("jwt - URLSource - io.ReadAll: %w", err)
+	}
+
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - URLSource - %w", err)
+	}
+
+	s.set = set
+	s.fetchedAt = time.Now()
+	return set, nil
+}
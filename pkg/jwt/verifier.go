@@ -0,0 +1,226 @@
+// Package jwt verifies signed JSON Web Tokens against a JWKS key set.
+// Only asymmetric algorithms are accepted: RS256/384/512 and ES256/384/512.
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed      = errors.New("malformed token")
+	ErrUnsupportedAlg = errors.New("unsupported signing algorithm")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrSignature      = errors.New("invalid signature")
+	ErrExpired        = errors.New("token is expired")
+	ErrNotYetValid    = errors.New("token is not valid yet")
+	ErrIssuer         = errors.New("unexpected issuer")
+	ErrAudience       = errors.New("unexpected audience")
+)
+
+type algorithm struct {
+	hash crypto.Hash
+	// curveBits is set for ECDSA algorithms
+	curveBits int
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {hash: crypto.SHA256},
+	"RS384": {hash: crypto.SHA384},
+	"RS512": {hash: crypto.SHA512},
+	"ES256": {hash: crypto.SHA256, curveBits: 256},
+	"ES384": {hash: crypto.SHA384, curveBits: 384},
+	"ES512": {hash: crypto.SHA512, curveBits: 521},
+}
+
+type Verifier struct {
+	source   KeySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+func NewVerifier(source KeySource, opts ...Option) *Verifier {
+	v := &Verifier{
+		source: source,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Verify checks the token's signature, exp, nbf and, when configured, iss and aud,
+// and returns its claims. Tokens without exp are rejected.
+func (v *Verifier) Verify(ctx context.Context, raw string) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformed
+	}
+
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlg, header.Alg)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	h := alg.hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(alg, key, h.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformed
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	set, err := v.source.KeySet(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - Verify - source.KeySet: %w", err)
+	}
+	if key, ok := set.Key(kid); ok {
+		return key, nil
+	}
+
+	// The provider may have rotated its keys since the set was loaded
+	set, err = v.source.Refresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("jwt - Verify - source.Refresh: %w", err)
+	}
+	if key, ok := set.Key(kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func verifySignature(alg algorithm, key crypto.PublicKey, digest, signature []byte) error {
+	if alg.curveBits == 0 {
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		if err := rsa.VerifyPKCS1v15(pub, alg.hash, digest, signature); err != nil {
+			return ErrSignature
+		}
+		return nil
+	}
+
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok || pub.Curve.Params().BitSize != alg.curveBits {
+		return ErrUnsupportedAlg
+	}
+
+	size := (alg.curveBits + 7) / 8
+	if len(signature) != 2*size {
+		return ErrSignature
+	}
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	if !ecdsa.Verify(pub, digest, r, s) {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+func (v *Verifier) validateClaims(claims map[string]any) error {
+	now := v.now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return ErrExpired
+	}
+	if !now.Before(exp.Add(v.leeway)) {
+		return ErrExpired
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(v.leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return ErrIssuer
+		}
+	}
+
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return ErrAudience
+	}
+
+	return nil
+}
+
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec := int64(value)
+	return time.Unix(sec, int64((value-float64(sec))*1e9)), true
+}
+
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
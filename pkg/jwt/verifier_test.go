@@ -0,0 +1,199 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/pkg/jwt"
+)
+
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks []byte
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa-1", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()),
+				"e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec-1", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	return testKeys{rsa: rsaKey, ec: ecKey, jwks: jwks}
+}
+
+func sign(t *testing.T, keys testKeys, alg, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, keys.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("rsa.SignPKCS1v15: %v", err)
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, keys.ec, digest[:])
+		if err != nil {
+			t.Fatalf("ecdsa.Sign: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		t.Fatalf("unsupported test alg %s", alg)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "u1",
+		"iss": "https://idp.example.com",
+		"aud": []string{"pr-reviewer", "other"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func with(claims map[string]any, name string, value any) map[string]any {
+	claims[name] = value
+	return claims
+}
+
+func TestVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+
+	set, err := jwt.ParseKeySet(keys.jwks)
+	if err != nil {
+		t.Fatalf("ParseKeySet: %v", err)
+	}
+	verifier := jwt.NewVerifier(jwt.NewStaticSource(set),
+		jwt.Issuer("https://idp.example.com"),
+		jwt.Audience("pr-reviewer"),
+		jwt.Leeway(30*time.Second),
+	)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"RS256", sign(t, keys, "RS256", "rsa-1", validClaims()), nil},
+		{"ES256", sign(t, keys, "ES256", "ec-1", validClaims()), nil},
+		{"within leeway", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "exp", time.Now().Add(-10*time.Second).Unix())), nil},
+		{"expired", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "exp", time.Now().Add(-time.Hour).Unix())), jwt.ErrExpired},
+		{"missing exp", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "exp", nil)), jwt.ErrExpired},
+		{"not yet valid", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "nbf", time.Now().Add(time.Hour).Unix())), jwt.ErrNotYetValid},
+		{"wrong issuer", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "iss", "https://evil.example.com")), jwt.ErrIssuer},
+		{"wrong audience", sign(t, keys, "RS256", "rsa-1", with(validClaims(), "aud", "other")), jwt.ErrAudience},
+		{"unknown kid", sign(t, keys, "RS256", "rsa-2", validClaims()), jwt.ErrUnknownKey},
+		{"key type mismatch", sign(t, keys, "RS256", "ec-1", validClaims()), jwt.ErrUnsupportedAlg},
+		{"malformed", "not-a-token", jwt.ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims["sub"] != "u1" {
+				t.Errorf("sub = %v, want u1", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsTampering(t *testing.T) {
+	keys := newTestKeys(t)
+	set, _ := jwt.ParseKeySet(keys.jwks)
+	verifier := jwt.NewVerifier(jwt.NewStaticSource(set))
+
+	token := sign(t, keys, "RS256", "rsa-1", validClaims())
+	parts := strings.Split(token, ".")
+
+	forged, _ := json.Marshal(with(validClaims(), "sub", "admin"))
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+	if _, err := verifier.Verify(context.Background(), tampered); !errors.Is(err, jwt.ErrSignature) {
+		t.Errorf("tampered payload: error = %v, want %v", err, jwt.ErrSignature)
+	}
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	if _, err := verifier.Verify(context.Background(), none); !errors.Is(err, jwt.ErrUnsupportedAlg) {
+		t.Errorf("alg none: error = %v, want %v", err, jwt.ErrUnsupportedAlg)
+	}
+}
+
+func TestURLSource(t *testing.T) {
+	keys := newTestKeys(t)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(keys.jwks)
+	}))
+	defer srv.Close()
+
+	verifier := jwt.NewVerifier(jwt.NewURLSource(srv.URL, time.Hour, srv.Client()))
+
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(context.Background(), sign(t, keys, "ES256", "ec-1", validClaims())); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", requests)
+	}
+
+	// An unknown kid triggers at most one rate-limited refresh
+	if _, err := verifier.Verify(context.Background(), sign(t, keys, "ES256", "ec-2", validClaims())); !errors.Is(err, jwt.ErrUnknownKey) {
+		t.Errorf("unknown kid: error = %v, want %v", err, jwt.ErrUnknownKey)
+	}
+	if requests != 1 {
+		t.Errorf("JWKS fetched %d times after unknown kid, want 1", requests)
+	}
+}
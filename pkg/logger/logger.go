@@ -0,0 +1,58 @@
+// Package logger builds slog loggers that add request-scoped attributes taken
+// from the context to every record.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ContextAttrs returns the attributes a context carries, e.g. a request id.
+type ContextAttrs func(ctx context.Context) []slog.Attr
+
+// New returns a logger writing records at level and above to w. The level is
+// debug, info, warn or error and the format json or text. The attributes of
+// every fromContext func are added to records logged with a context.
+func New(w io.Writer, level, format string, fromContext ...ContextAttrs) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("logger - New - unknown level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("logger - New - unknown format %q", format)
+	}
+
+	return slog.New(&contextHandler{Handler: handler, fromContext: fromContext}), nil
+}
+
+type contextHandler struct {
+	slog.Handler
+	fromContext []ContextAttrs
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, attrs := range h.fromContext {
+		r.AddAttrs(attrs(ctx)...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), fromContext: h.fromContext}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), fromContext: h.fromContext}
+}
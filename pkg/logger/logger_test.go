@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/pkg/logger"
+)
+
+type requestIDKey struct{}
+
+func requestID(ctx context.Context) []slog.Attr {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []slog.Attr{slog.String("request_id", id)}
+	}
+	return nil
+}
+
+func TestJSONWithContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "info", "json", requestID)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	l.With("component", "test").InfoContext(ctx, "served", "status", 200)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record is not JSON: %v\n%s", err, buf.String())
+	}
+	for key, want := range map[string]any{
+		"msg":        "served",
+		"level":      "INFO",
+		"request_id": "req-1",
+		"component":  "test",
+		"status":     float64(200),
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+}
+
+func TestLevelIsHonoured(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "warn", "text")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	l.Debug("dropped")
+	l.Info("dropped")
+	l.Warn("kept")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") || !strings.Contains(out, "msg=kept") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	if _, err := logger.New(&bytes.Buffer{}, "verbose", "json"); err == nil {
+		t.Error("unknown level: expected an error")
+	}
+	if _, err := logger.New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("unknown format: expected an error")
+	}
+}
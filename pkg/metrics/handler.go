@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler serves the registry. A failing func metric is logged and left out;
+// the rest of the metrics are still served.
+func Handler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := reg.WriteTo(r.Context(), &buf); err != nil {
+			slog.ErrorContext(r.Context(), "metrics - Handler - reg.WriteTo", "error", err)
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(buf.Bytes())
+	})
+}
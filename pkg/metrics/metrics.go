@@ -0,0 +1,357 @@
+// Package metrics implements counters, gauges and histograms exposed in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the Prometheus metric type.
+type Type string
+
+const (
+	TypeCounter   Type = "counter"
+	TypeGauge     Type = "gauge"
+	TypeHistogram Type = "histogram"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Sample is one value of a metric collected by a func metric. Labels holds
+// the label values in the order of the metric's label names.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// CollectFunc reads the current samples of a func metric at scrape time.
+type CollectFunc func(ctx context.Context) ([]Sample, error)
+
+type family interface {
+	name() string
+	write(ctx context.Context, w *bufio.Writer) error
+}
+
+// Registry holds metric families in registration order.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (reg *Registry) register(f family) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.names[f.name()] {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	reg.names[f.name()] = true
+	reg.families = append(reg.families, f)
+}
+
+// NewCounter registers a counter with the given label names.
+func (reg *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, TypeCounter, labels)}
+	reg.register(c.vec)
+	return c
+}
+
+// NewGauge registers a gauge with the given label names.
+func (reg *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{vec: newVec(name, help, TypeGauge, labels)}
+	reg.register(g.vec)
+	return g
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds,
+// which must be sorted. DefaultBuckets is used when buckets is empty.
+func (reg *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{metricName: name, help: help, labels: labels, buckets: buckets, values: make(map[string]*observations)}
+	reg.register(h)
+	return h
+}
+
+// NewFunc registers a counter or gauge whose samples are read by collect on
+// every scrape.
+func (reg *Registry) NewFunc(name, help string, typ Type, labels []string, collect CollectFunc) {
+	reg.register(&funcFamily{metricName: name, help: help, typ: typ, labels: labels, collect: collect})
+}
+
+// WriteTo writes every family in the text exposition format. Families whose
+// collect func fails are skipped; their errors are returned joined.
+func (reg *Registry) WriteTo(ctx context.Context, w io.Writer) error {
+	reg.mu.Lock()
+	families := append([]family(nil), reg.families...)
+	reg.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	var errs []error
+	for _, f := range families {
+		if err := f.write(ctx, bw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name(), err))
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// vec stores one value per label value combination.
+type vec struct {
+	metricName string
+	help       string
+	typ        Type
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+}
+
+func newVec(name, help string, typ Type, labels []string) *vec {
+	return &vec{metricName: name, help: help, typ: typ, labels: labels, values: make(map[string]*series)}
+}
+
+func (v *vec) name() string { return v.metricName }
+
+// get returns the series for labelValues. The caller holds v.mu.
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.metricName, len(v.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.values[key]
+	if !ok {
+		s = &series{labels: append([]string(nil), labelValues...)}
+		v.values[key] = s
+	}
+	return s
+}
+
+func (v *vec) write(_ context.Context, w *bufio.Writer) error {
+	v.mu.Lock()
+	samples := make([]Sample, 0, len(v.values))
+	for _, s := range v.values {
+		samples = append(samples, Sample{Labels: s.labels, Value: s.value})
+	}
+	v.mu.Unlock()
+
+	writeSamples(w, v.metricName, v.help, v.typ, v.labels, samples)
+	return nil
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	vec *vec
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter by delta, which must not be negative.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counter " + c.vec.metricName + " cannot decrease")
+	}
+	c.vec.mu.Lock()
+	c.vec.get(labelValues).value += delta
+	c.vec.mu.Unlock()
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	vec *vec
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	g.vec.get(labelValues).value = value
+	g.vec.mu.Unlock()
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	g.vec.get(labelValues).value += delta
+	g.vec.mu.Unlock()
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	values map[string]*observations
+}
+
+type observations struct {
+	labels []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *Histogram) name() string { return h.metricName }
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", h.metricName, len(h.labels), len(labelValues)))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := strings.Join(labelValues, "\xff")
+	o, ok := h.values[key]
+	if !ok {
+		o = &observations{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = o
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			o.counts[i]++
+		}
+	}
+	o.sum += value
+	o.count++
+}
+
+func (h *Histogram) write(_ context.Context, w *bufio.Writer) error {
+	h.mu.Lock()
+	all := make([]observations, 0, len(h.values))
+	for _, o := range h.values {
+		all = append(all, observations{labels: o.labels, counts: append([]uint64(nil), o.counts...), sum: o.sum, count: o.count})
+	}
+	h.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return lessLabels(all[i].labels, all[j].labels) })
+
+	writeHeader(w, h.metricName, h.help, TypeHistogram)
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, o := range all {
+		bucketValues := append(append([]string(nil), o.labels...), "")
+		for i, bound := range h.buckets {
+			bucketValues[len(bucketValues)-1] = formatFloat(bound)
+			writeLine(w, h.metricName+"_bucket", bucketLabels, bucketValues, float64(o.counts[i]))
+		}
+		bucketValues[len(bucketValues)-1] = "+Inf"
+		writeLine(w, h.metricName+"_bucket", bucketLabels, bucketValues, float64(o.count))
+		writeLine(w, h.metricName+"_sum", h.labels, o.labels, o.sum)
+		writeLine(w, h.metricName+"_count", h.labels, o.labels, float64(o.count))
+	}
+	return nil
+}
+
+type funcFamily struct {
+	metricName string
+	help       string
+	typ        Type
+	labels     []string
+	collect    CollectFunc
+}
+
+func (f *funcFamily) name() string { return f.metricName }
+
+func (f *funcFamily) write(ctx context.Context, w *bufio.Writer) error {
+	samples, err := f.collect(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range samples {
+		if len(s.Labels) != len(f.labels) {
+			return fmt.Errorf("sample has %d label values, want %d", len(s.Labels), len(f.labels))
+		}
+	}
+
+	writeSamples(w, f.metricName, f.help, f.typ, f.labels, samples)
+	return nil
+}
+
+func writeSamples(w *bufio.Writer, name, help string, typ Type, labels []string, samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool { return lessLabels(samples[i].Labels, samples[j].Labels) })
+
+	writeHeader(w, name, help, typ)
+	for _, s := range samples {
+		writeLine(w, name, labels, s.Labels, s.Value)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help string, typ Type) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+func writeLine(w *bufio.Writer, name string, labels, values []string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label)
+			w.WriteString(`="`)
+			w.WriteString(labelEscaper.Replace(values[i]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+func lessLabels(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
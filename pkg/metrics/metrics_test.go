@@ -0,0 +1,102 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/pkg/metrics"
+)
+
+func TestExposition(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	requests := reg.NewCounter("requests_total", "Requests.", "route", "status")
+	requests.Inc("GET /b", "200")
+	requests.Add(2, "GET /a", "200")
+	requests.Inc("GET /a", "500")
+
+	inFlight := reg.NewGauge("in_flight", "In-flight requests.")
+	inFlight.Set(3)
+	inFlight.Add(-1)
+
+	latency := reg.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "GET /a")
+	latency.Observe(0.5, "GET /a")
+	latency.Observe(2, "GET /a")
+
+	reg.NewFunc("open_items", "Open items by team.", metrics.TypeGauge, []string{"team"},
+		func(context.Context) ([]metrics.Sample, error) {
+			return []metrics.Sample{
+				{Labels: []string{"payments"}, Value: 4},
+				{Labels: []string{`back"end\`}, Value: 1.5},
+			}, nil
+		})
+
+	want := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="GET /a",status="200"} 2
+requests_total{route="GET /a",status="500"} 1
+requests_total{route="GET /b",status="200"} 1
+# HELP in_flight In-flight requests.
+# TYPE in_flight gauge
+in_flight 2
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="GET /a",le="0.1"} 1
+latency_seconds_bucket{route="GET /a",le="1"} 2
+latency_seconds_bucket{route="GET /a",le="+Inf"} 3
+latency_seconds_sum{route="GET /a"} 2.55
+latency_seconds_count{route="GET /a"} 3
+# HELP open_items Open items by team.
+# TYPE open_items gauge
+open_items{team="back\"end\\"} 1.5
+open_items{team="payments"} 4
+`
+
+	var out strings.Builder
+	if err := reg.WriteTo(context.Background(), &out); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("exposition mismatch\ngot:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestHandlerSkipsFailingFunc(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.NewCounter("ok_total", "Always served.").Inc()
+	reg.NewFunc("broken", "Fails to collect.", metrics.TypeGauge, nil,
+		func(context.Context) ([]metrics.Sample, error) {
+			return nil, errors.New("database is down")
+		})
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, metrics.ContentType)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), "ok_total 1\n") {
+		t.Errorf("body lacks ok_total:\n%s", body)
+	}
+	if strings.Contains(string(body), "broken") {
+		t.Errorf("body has the failing metric:\n%s", body)
+	}
+}
+
+func TestDuplicateNamePanics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.NewCounter("dup_total", "First.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	reg.NewGauge("dup_total", "Second.")
+}
@@ -0,0 +1,135 @@
+// Package migrator applies golang-migrate migrations from an fs.FS to Postgres.
+// Every run holds a Postgres advisory lock, so instances started together
+// apply each migration once.
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrSchemaNewer means the database was migrated by a newer binary.
+var ErrSchemaNewer = errors.New("schema is newer than the migrations of this binary")
+
+// Status describes the schema relative to the available migrations.
+type Status struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	Latest  uint `json:"latest"`
+	Pending int  `json:"pending"`
+}
+
+type Migrator struct {
+	m        *migrate.Migrate
+	versions []uint
+}
+
+// New reads the migrations in the root of migrations and connects to the
+// database at databaseURL. lockTimeout bounds the wait for another instance
+// that holds the migration lock.
+func New(migrations fs.FS, databaseURL string, lockTimeout time.Duration) (*Migrator, error) {
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrator - New - iofs.New: %w", err)
+	}
+
+	var versions []uint
+	version, err := source.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("migrator - New - source.Next: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, driverURL(databaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("migrator - New - migrate.NewWithSourceInstance: %w", err)
+	}
+	m.LockTimeout = lockTimeout
+	m.Log = logger{}
+
+	return &Migrator{m: m, versions: versions}, nil
+}
+
+// Status returns the applied version and the migrations still to apply.
+func (mg *Migrator) Status() (Status, error) {
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("migrator - Status - m.Version: %w", err)
+	}
+
+	status := Status{Version: version, Dirty: dirty}
+	for _, v := range mg.versions {
+		status.Latest = max(status.Latest, v)
+		if v > version {
+			status.Pending++
+		}
+	}
+
+	return status, nil
+}
+
+// Up applies every pending migration. It refuses to touch a schema that is
+// newer than the available migrations.
+func (mg *Migrator) Up() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if status.Version > status.Latest {
+		return fmt.Errorf("migrator - Up - version %d, latest %d: %w", status.Version, status.Latest, ErrSchemaNewer)
+	}
+
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrator - Up - m.Up: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back the last steps migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("migrator - Down - steps must be positive, got %d", steps)
+	}
+
+	if err := mg.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrator - Down - m.Steps: %w", err)
+	}
+	return nil
+}
+
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// driverURL points a postgres:// URL at the pgx v5 driver of golang-migrate.
+func driverURL(databaseURL string) string {
+	for _, scheme := range []string{"postgres://", "postgresql://"} {
+		if rest, ok := strings.CutPrefix(databaseURL, scheme); ok {
+			return "pgx5://" + rest
+		}
+	}
+	return databaseURL
+}
+
+// logger passes golang-migrate progress messages to slog.
+type logger struct{}
+
+func (logger) Printf(format string, v ...any) {
+	slog.Info("migrator: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (logger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"pr-reviewer-service/pkg/metrics"
+)
+
+// RegisterMetrics exposes the connection pool statistics as pgxpool_* metrics.
+func (p *Postgres) RegisterMetrics(reg *metrics.Registry) {
+	stat := func(typ metrics.Type, name, help string, value func() float64) {
+		reg.NewFunc(name, help, typ, nil, func(context.Context) ([]metrics.Sample, error) {
+			return []metrics.Sample{{Value: value()}}, nil
+		})
+	}
+
+	stat(metrics.TypeGauge, "pgxpool_max_conns", "Maximum size of the pool.", func() float64 {
+		return float64(p.Pool.Stat().MaxConns())
+	})
+	stat(metrics.TypeGauge, "pgxpool_total_conns", "Connections currently in the pool.", func() float64 {
+		return float64(p.Pool.Stat().TotalConns())
+	})
+	stat(metrics.TypeGauge, "pgxpool_acquired_conns", "Connections currently acquired.", func() float64 {
+		return float64(p.Pool.Stat().AcquiredConns())
+	})
+	stat(metrics.TypeGauge, "pgxpool_idle_conns", "Idle connections in the pool.", func() float64 {
+		return float64(p.Pool.Stat().IdleConns())
+	})
+	stat(metrics.TypeGauge, "pgxpool_constructing_conns", "Connections being established.", func() float64 {
+		return float64(p.Pool.Stat().ConstructingConns())
+	})
+	stat(metrics.TypeCounter, "pgxpool_acquires_total", "Successful connection acquires.", func() float64 {
+		return float64(p.Pool.Stat().AcquireCount())
+	})
+	stat(metrics.TypeCounter, "pgxpool_empty_acquires_total", "Acquires that waited for a connection because the pool was empty.", func() float64 {
+		return float64(p.Pool.Stat().EmptyAcquireCount())
+	})
+	stat(metrics.TypeCounter, "pgxpool_canceled_acquires_total", "Acquires canceled by their context.", func() float64 {
+		return float64(p.Pool.Stat().CanceledAcquireCount())
+	})
+	stat(metrics.TypeCounter, "pgxpool_acquire_duration_seconds_total", "Total time spent acquiring connections.", func() float64 {
+		return p.Pool.Stat().AcquireDuration().Seconds()
+	})
+	stat(metrics.TypeCounter, "pgxpool_new_conns_total", "Connections opened.", func() float64 {
+		return float64(p.Pool.Stat().NewConnsCount())
+	})
+}
@@ -1,6 +1,11 @@
 package postgres
 
-import "time"
+import (
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
 
 type Option func(*Postgres)
 
@@ -21,3 +26,17 @@ func ConnTimeout(timeout time.Duration) Option {
 		c.connTimeout = timeout
 	}
 }
+
+// QueryLogger logs every query at debug level to logger.
+func QueryLogger(logger *slog.Logger) Option {
+	return func(c *Postgres) {
+		c.queryLogger = logger
+	}
+}
+
+// TracerProvider starts a span for every query.
+func TracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Postgres) {
+		c.tracerProvider = tp
+	}
+}
@@ -3,11 +3,12 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -17,9 +18,11 @@ const (
 )
 
 type Postgres struct {
-	maxPoolSize  int
-	connAttempts int
-	connTimeout  time.Duration
+	maxPoolSize    int
+	connAttempts   int
+	connTimeout    time.Duration
+	queryLogger    *slog.Logger
+	tracerProvider trace.TracerProvider
 
 	Builder squirrel.StatementBuilderType
 	Pool    *pgxpool.Pool
@@ -44,6 +47,13 @@ func New(url string, opts ...Option) (*Postgres, error) {
 	}
 
 	poolConfig.MaxConns = int32(pg.maxPoolSize)
+	if pg.queryLogger != nil || pg.tracerProvider != nil {
+		tracer := queryTracer{logger: pg.queryLogger}
+		if pg.tracerProvider != nil {
+			tracer.tracer = pg.tracerProvider.Tracer("pr-reviewer-service/pkg/postgres")
+		}
+		poolConfig.ConnConfig.Tracer = tracer
+	}
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -51,7 +61,7 @@ func New(url string, opts ...Option) (*Postgres, error) {
 			break
 		}
 
-		log.Printf("Postgres is trying to connect, attempts left: %d", pg.connAttempts)
+		slog.Warn("Postgres is trying to connect", "attempts_left", pg.connAttempts, "error", err)
 
 		time.Sleep(pg.connTimeout)
 
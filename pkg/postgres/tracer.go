@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer starts a span for every query and logs it at debug level, both
+// with the context of the call, so queries show up under the request that
+// ran them.
+type queryTracer struct {
+	logger *slog.Logger
+	tracer trace.Tracer
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+	span  trace.Span
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	start := queryStart{sql: data.SQL, start: time.Now()}
+
+	if t.tracer != nil {
+		ctx, start.span = t.tracer.Start(ctx, queryOperation(data.SQL),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.query.text", data.SQL),
+			),
+		)
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, start)
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	if start.span != nil {
+		if data.Err != nil {
+			start.span.RecordError(data.Err)
+			start.span.SetStatus(codes.Error, data.Err.Error())
+		} else {
+			start.span.SetAttributes(attribute.Int64("db.response.rows", data.CommandTag.RowsAffected()))
+		}
+		start.span.End()
+	}
+
+	if t.logger == nil || !t.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", start.sql),
+		slog.Float64("duration_ms", float64(time.Since(start.start).Microseconds())/1000.0),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, slog.String("error", data.Err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int64("rows", data.CommandTag.RowsAffected()))
+	}
+
+	t.logger.LogAttrs(ctx, slog.LevelDebug, "postgres query", attrs...)
+}
+
+// queryOperation names a query span after the SQL command, e.g. SELECT.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "postgres"
+	}
+	return "postgres " + strings.ToUpper(fields[0])
+}
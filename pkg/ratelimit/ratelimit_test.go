@@ -0,0 +1,68 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/pkg/ratelimit"
+)
+
+func TestTake(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name       string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{"full bucket", 0, true, 1, 0},
+		{"last token", 0, true, 0, 0},
+		{"empty", 0, false, 0, time.Second},
+		{"half refilled", 500 * time.Millisecond, false, 0, 500 * time.Millisecond},
+		{"refilled one", 1500 * time.Millisecond, true, 0, 0},
+		{"capped at burst", time.Hour, true, 1, 0},
+	}
+
+	var state ratelimit.State
+	for _, step := range steps {
+		var result ratelimit.Result
+		state, result = ratelimit.Take(state, limit, start.Add(step.at))
+
+		if result.Allowed != step.allowed || result.Remaining != step.remaining || result.RetryAfter != step.retryAfter {
+			t.Errorf("%s: got allowed=%t remaining=%d retry_after=%s, want %t %d %s",
+				step.name, result.Allowed, result.Remaining, result.RetryAfter,
+				step.allowed, step.remaining, step.retryAfter)
+		}
+		if result.Limit != limit.Burst {
+			t.Errorf("%s: limit = %d, want %d", step.name, result.Limit, limit.Burst)
+		}
+	}
+}
+
+func TestMemoryStoreKeys(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	for _, key := range []string{"a", "b"} {
+		result, err := store.Take(context.Background(), key, limit, now)
+		if err != nil || !result.Allowed {
+			t.Fatalf("Take(%s) = %+v, %v; want allowed", key, result, err)
+		}
+	}
+
+	result, _ := store.Take(context.Background(), "a", limit, now)
+	if result.Allowed {
+		t.Errorf("second Take(a) allowed, want limited")
+	}
+
+	// Buckets swept after refilling start full again
+	result, _ = store.Take(context.Background(), "a", limit, now.Add(time.Hour))
+	if !result.Allowed {
+		t.Errorf("Take(a) after refill limited, want allowed")
+	}
+}
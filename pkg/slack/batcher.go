@@ -0,0 +1,105 @@
+package slack
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Batcher merges bursts of messages to the same webhook into one post. A batch
+// is sent window after its first message or as soon as it is full. Failed
+// posts are logged and dropped.
+type Batcher struct {
+	client      *Client
+	window      time.Duration
+	maxMessages int
+
+	mu      sync.Mutex
+	pending map[string]*batch
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+type batch struct {
+	texts []string
+	timer *time.Timer
+}
+
+func NewBatcher(client *Client, window time.Duration, maxMessages int) *Batcher {
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+	return &Batcher{
+		client:      client,
+		window:      window,
+		maxMessages: maxMessages,
+		pending:     make(map[string]*batch),
+	}
+}
+
+// Add queues text for the webhook. It does not block on delivery; messages
+// added after Close are dropped.
+func (b *Batcher) Add(webhookURL, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		slog.Warn("slack - message dropped, batcher closed")
+		return
+	}
+
+	p, ok := b.pending[webhookURL]
+	if !ok {
+		p = &batch{}
+		p.timer = time.AfterFunc(b.window, func() { b.flush(webhookURL, p) })
+		b.pending[webhookURL] = p
+	}
+	p.texts = append(p.texts, text)
+
+	if len(p.texts) >= b.maxMessages {
+		p.timer.Stop()
+		delete(b.pending, webhookURL)
+		b.send(webhookURL, p.texts)
+	}
+}
+
+// Close sends the pending batches and waits for all posts to finish.
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	b.closed = true
+	for webhookURL, p := range b.pending {
+		p.timer.Stop()
+		b.send(webhookURL, p.texts)
+	}
+	clear(b.pending)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// flush sends p when its window ends, unless it was sent already.
+func (b *Batcher) flush(webhookURL string, p *batch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending[webhookURL] != p {
+		return
+	}
+	delete(b.pending, webhookURL)
+	b.send(webhookURL, p.texts)
+}
+
+// send posts texts in the background. b.mu must be held.
+func (b *Batcher) send(webhookURL string, texts []string) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		msg := Message{Text: strings.Join(texts, "\n\n")}
+		if err := b.client.Post(context.Background(), webhookURL, msg); err != nil {
+			slog.Error("slack - batch not delivered", "messages", len(texts), "error", err)
+		}
+	}()
+}
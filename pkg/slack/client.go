@@ -0,0 +1,63 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Message is the payload of a Slack-compatible incoming webhook.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Client posts messages to incoming webhook URLs.
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(hc *http.Client) *Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &Client{http: hc}
+}
+
+// Post sends msg to the webhook. Any status other than 2xx is an error.
+// The URL carries the webhook secret and is left out of errors.
+func (c *Client) Post(ctx context.Context, webhookURL string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("slack - Post - json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("slack - Post - http.NewRequest: invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// *url.Error repeats the url
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("slack - Post - c.http.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack - Post - unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(reply))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/pkg/slack"
+)
+
+// webhook is a local stand-in for incoming webhooks that records the posted
+// texts by path.
+type webhook struct {
+	mu    sync.Mutex
+	posts map[string][]string
+}
+
+func newWebhook(t *testing.T) (*webhook, *httptest.Server) {
+	t.Helper()
+
+	w := &webhook{posts: make(map[string][]string)}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(rw, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/gone" {
+			http.Error(rw, "no_service", http.StatusNotFound)
+			return
+		}
+
+		var msg slack.Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(rw, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+
+		w.mu.Lock()
+		w.posts[r.URL.Path] = append(w.posts[r.URL.Path], msg.Text)
+		w.mu.Unlock()
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return w, srv
+}
+
+func (w *webhook) texts(path string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.posts[path]...)
+}
+
+func TestClientPost(t *testing.T) {
+	hook, srv := newWebhook(t)
+	client := slack.NewClient(srv.Client())
+
+	if err := client.Post(context.Background(), srv.URL+"/team", slack.Message{Text: "hello"}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if got := hook.texts("/team"); len(got) != 1 || got[0] != "hello" {
+		t.Errorf("posted %q, want [hello]", got)
+	}
+
+	err := client.Post(context.Background(), srv.URL+"/gone", slack.Message{Text: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "no_service") {
+		t.Errorf("Post to a removed webhook: got %v, want a 404 error", err)
+	}
+	if err != nil && strings.Contains(err.Error(), srv.URL) {
+		t.Errorf("error %q reveals the webhook URL", err)
+	}
+}
+
+func TestBatcher(t *testing.T) {
+	hook, srv := newWebhook(t)
+	batcher := slack.NewBatcher(slack.NewClient(srv.Client()), 50*time.Millisecond, 3)
+
+	// a burst within the window is one post per webhook
+	batcher.Add(srv.URL+"/team", "one")
+	batcher.Add(srv.URL+"/user", "dm")
+	batcher.Add(srv.URL+"/team", "two")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(hook.texts("/team")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := hook.texts("/team"); len(got) != 1 || got[0] != "one\n\ntwo" {
+		t.Errorf("team posts = %q, want one batch of two messages", got)
+	}
+
+	// a full batch does not wait for the window, Close sends the rest
+	slow := slack.NewBatcher(slack.NewClient(srv.Client()), time.Hour, 2)
+	slow.Add(srv.URL+"/full", "a")
+	slow.Add(srv.URL+"/full", "b")
+	slow.Add(srv.URL+"/full", "c")
+	slow.Close()
+	slow.Add(srv.URL+"/full", "late")
+	batcher.Close()
+
+	got := hook.texts("/full")
+	slices.Sort(got)
+	if len(got) != 2 || got[0] != "a\n\nb" || got[1] != "c" {
+		t.Errorf("full posts = %q, want [a\\n\\nb c]", got)
+	}
+	if got := hook.texts("/user"); len(got) != 1 || got[0] != "dm" {
+		t.Errorf("user posts = %q, want [dm]", got)
+	}
+}
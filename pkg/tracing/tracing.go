@@ -0,0 +1,91 @@
+// Package tracing sets up OpenTelemetry tracing with an OTLP or stdout
+// exporter and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	// Exporter is otlp (OTLP over HTTP) or stdout
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector
+	Endpoint string
+	Insecure bool
+	// SampleRatio is the share of new traces that are recorded. Requests whose
+	// caller sampled the trace are always recorded.
+	SampleRatio float64
+}
+
+// Propagator reads and writes W3C traceparent and baggage headers.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// New creates a tracer provider exporting spans as cfg says and installs it,
+// together with Propagator, as the global one. Shut the provider down on exit
+// to flush buffered spans.
+func New(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(Propagator())
+
+	return tp, nil
+}
+
+// NewProvider creates a tracer provider for the service described by cfg that
+// exports spans through processor options such as sdktrace.WithBatcher or,
+// in tests, sdktrace.WithSyncer with an in-memory exporter.
+func NewProvider(cfg Config, processors ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	)
+
+	opts := append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, processors...)
+
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("tracing - New - otlptracehttp.New: %w", err)
+		}
+		return exporter, nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("tracing - New - stdouttrace.New: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("tracing - New - unknown exporter %q", cfg.Exporter)
+	}
+}